@@ -13,6 +13,7 @@ import (
 	"time"
 
 	"github.com/figment-networks/indexer-manager/structs"
+	"github.com/figment-networks/terra-worker/api/mapper"
 
 	"encoding/json"
 )
@@ -82,13 +83,7 @@ func (c *Client) GetAccountBalance(ctx context.Context, params structs.HeightAcc
 	}
 
 	for _, blnc := range result.Result {
-		resp.Balances = append(resp.Balances,
-			structs.TransactionAmount{
-				Text:     blnc.Amount.String(),
-				Numeric:  blnc.Amount.BigInt(),
-				Currency: blnc.Denom,
-			},
-		)
+		resp.Balances = append(resp.Balances, mapper.CoinAmount(blnc))
 	}
 
 	return resp, err
@@ -0,0 +1,265 @@
+package api
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+	"golang.org/x/time/rate"
+)
+
+// ArchiveMode selects how client uses the archive
+type ArchiveMode string
+
+const (
+	// ArchiveRecord stores responses fetched from the network
+	ArchiveRecord ArchiveMode = "record"
+	// ArchiveReplay serves requests from the archive only, without network access
+	ArchiveReplay ArchiveMode = "replay"
+)
+
+// ErrNotArchived is returned in replay mode for requests without archived response
+var ErrNotArchived = errors.New("response is not archived")
+
+// txHeightRe matches height condition of tx_search query
+var txHeightRe = regexp.MustCompile(`tx\.height\s*>?=\s*(\d+)`)
+
+// ArchiveEntry describes archived response of request. Key is the endpoint with sorted params,
+// so the same request to other node (or with other datahub key) has the same key
+type ArchiveEntry struct {
+	Key         string     `json:"key"`
+	Endpoint    string     `json:"endpoint"`
+	Params      url.Values `json:"params,omitempty"`
+	Height      uint64     `json:"height,omitempty"`
+	StatusCode  int        `json:"status_code"`
+	ContentType string     `json:"content_type,omitempty"`
+	Object      string     `json:"object"`
+	Size        int64      `json:"size"`
+	Time        time.Time  `json:"time"`
+}
+
+// Archive stores raw http responses on local disk. Bodies are content addressed objects (gzipped, named by sha256 of the body),
+// so identical responses are stored once. Entries are json files named by hash of the request key, grouped by endpoint:
+//
+//	objects/<sha256[:2]>/<sha256>.gz
+//	index/<endpoint>/<sha256 of key>.json
+type Archive struct {
+	dir string
+}
+
+// NewArchive is Archive constructor, dir is created when missing
+func NewArchive(dir string) (*Archive, error) {
+	if dir == "" {
+		return nil, errors.New("[TERRA-API] Archive dir is not set")
+	}
+	for _, d := range []string{"objects", "index"} {
+		if err := os.MkdirAll(filepath.Join(dir, d), 0755); err != nil {
+			return nil, err
+		}
+	}
+	return &Archive{dir: dir}, nil
+}
+
+// RequestKey returns archive key of request: endpoint path with sorted query params
+func RequestKey(req *http.Request) string {
+	if q := req.URL.Query().Encode(); q != "" {
+		return req.URL.Path + "?" + q
+	}
+	return req.URL.Path
+}
+
+// requestHeight returns height of request taken from `height`, `minHeight` or `tx.height` of tx_search query
+func requestHeight(params url.Values) uint64 {
+	for _, p := range []string{"height", "minHeight"} {
+		if h, err := strconv.ParseUint(params.Get(p), 10, 64); err == nil {
+			return h
+		}
+	}
+	if m := txHeightRe.FindStringSubmatch(params.Get("query")); m != nil {
+		h, _ := strconv.ParseUint(m[1], 10, 64)
+		return h
+	}
+	return 0
+}
+
+func (a *Archive) entryPath(key, endpoint string) string {
+	sum := sha256.Sum256([]byte(key))
+	dir := strings.Trim(strings.ReplaceAll(endpoint, "/", "_"), "_")
+	if dir == "" {
+		dir = "_"
+	}
+	return filepath.Join(a.dir, "index", dir, hex.EncodeToString(sum[:])+".json")
+}
+
+func (a *Archive) objectPath(object string) string {
+	return filepath.Join(a.dir, "objects", object[:2], object+".gz")
+}
+
+// Put stores response body of request, replacing previously archived response of the same request
+func (a *Archive) Put(req *http.Request, statusCode int, contentType string, body []byte) (entry ArchiveEntry, err error) {
+	sum := sha256.Sum256(body)
+	entry = ArchiveEntry{
+		Key:         RequestKey(req),
+		Endpoint:    req.URL.Path,
+		Params:      req.URL.Query(),
+		Height:      requestHeight(req.URL.Query()),
+		StatusCode:  statusCode,
+		ContentType: contentType,
+		Object:      hex.EncodeToString(sum[:]),
+		Size:        int64(len(body)),
+		Time:        time.Now().UTC(),
+	}
+
+	object := a.objectPath(entry.Object)
+	if _, err := os.Stat(object); errors.Is(err, os.ErrNotExist) {
+		buff := &bytes.Buffer{}
+		gz := gzip.NewWriter(buff)
+		if _, err := gz.Write(body); err != nil {
+			return entry, err
+		}
+		if err := gz.Close(); err != nil {
+			return entry, err
+		}
+		if err := writeFileAtomic(object, buff.Bytes()); err != nil {
+			return entry, err
+		}
+	}
+
+	e, err := json.Marshal(entry)
+	if err != nil {
+		return entry, err
+	}
+	return entry, writeFileAtomic(a.entryPath(entry.Key, entry.Endpoint), e)
+}
+
+// Get returns archived response of request, ErrNotArchived when there is none
+func (a *Archive) Get(req *http.Request) (entry ArchiveEntry, body []byte, err error) {
+	key := RequestKey(req)
+	e, err := ioutil.ReadFile(a.entryPath(key, req.URL.Path))
+	if errors.Is(err, os.ErrNotExist) {
+		return entry, nil, fmt.Errorf("%s: %w", key, ErrNotArchived)
+	} else if err != nil {
+		return entry, nil, err
+	}
+	if err := json.Unmarshal(e, &entry); err != nil {
+		return entry, nil, fmt.Errorf("error decoding archive entry of %s: %w", key, err)
+	}
+
+	f, err := os.Open(a.objectPath(entry.Object))
+	if err != nil {
+		return entry, nil, err
+	}
+	defer f.Close()
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return entry, nil, fmt.Errorf("error reading archived object %s: %w", entry.Object, err)
+	}
+	defer gz.Close()
+	if body, err = ioutil.ReadAll(gz); err != nil {
+		return entry, nil, fmt.Errorf("error reading archived object %s: %w", entry.Object, err)
+	}
+	return entry, body, nil
+}
+
+func writeFileAtomic(path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	tmp, err := ioutil.TempFile(filepath.Dir(path), filepath.Base(path)+".tmp")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// archiveTransport records successful (2xx) responses of next round tripper into archive, or serves them from archive when next is nil.
+// Error responses are passed through, so a transient failure never replaces an archived response of the same request
+type archiveTransport struct {
+	archive *Archive
+	next    http.RoundTripper
+	logger  *zap.Logger
+}
+
+func (t *archiveTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if t.next == nil {
+		entry, body, err := t.archive.Get(req)
+		if err != nil {
+			return nil, err
+		}
+		header := http.Header{}
+		if entry.ContentType != "" {
+			header.Set("Content-Type", entry.ContentType)
+		}
+		return &http.Response{
+			Status:        fmt.Sprintf("%d %s", entry.StatusCode, http.StatusText(entry.StatusCode)),
+			StatusCode:    entry.StatusCode,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        header,
+			Body:          ioutil.NopCloser(bytes.NewReader(body)),
+			ContentLength: int64(len(body)),
+			Request:       req,
+		}, nil
+	}
+
+	resp, err := t.next.RoundTrip(req)
+	if err != nil || resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp, err
+	}
+	body, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+	if _, err := t.archive.Put(req, resp.StatusCode, resp.Header.Get("Content-Type"), body); err != nil {
+		t.logger.Error("[TERRA-API] Error archiving response", zap.String("key", RequestKey(req)), zap.Error(err))
+	}
+	return resp, nil
+}
+
+// SetArchive makes client store raw responses in the archive (ArchiveRecord),
+// or serve requests from it instead of the network (ArchiveReplay, without rate limit)
+func (c *Client) SetArchive(a *Archive, mode ArchiveMode) error {
+	hc := *c.httpClient
+	t := &archiveTransport{archive: a, logger: c.logger}
+	switch mode {
+	case ArchiveRecord:
+		t.next = hc.Transport
+		if t.next == nil {
+			t.next = http.DefaultTransport
+		}
+	case ArchiveReplay:
+		c.rateLimiter = rate.NewLimiter(rate.Inf, 0)
+	default:
+		return fmt.Errorf("[TERRA-API] Unknown archive mode %q", mode)
+	}
+	hc.Transport = t
+	c.httpClient = &hc
+	return nil
+}
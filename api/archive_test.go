@@ -0,0 +1,118 @@
+package api
+
+import (
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func newRequest(t *testing.T, url string) *http.Request {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	require.NoError(t, err)
+	return req
+}
+
+func TestArchive_PutGet(t *testing.T) {
+	dir := t.TempDir()
+	a, err := NewArchive(dir)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name   string
+		url    string
+		key    string
+		height uint64
+	}{
+		{"lcd", "http://lcd:1317/bank/balances/terra1abc?height=10", "/bank/balances/terra1abc?height=10", 10},
+		{"blockchain", "http://rpc:26657/blockchain?minHeight=5&maxHeight=7", "/blockchain?maxHeight=7&minHeight=5", 5},
+		{"tx_search", `http://rpc:26657/tx_search?query="tx.height>=20 AND tx.height<=30"&page=1`, `/tx_search?page=1&query=%22tx.height%3E%3D20+AND+tx.height%3C%3D30%22`, 20},
+		{"without params", "http://rpc:26657/status", "/status", 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := newRequest(t, tt.url)
+			require.Equal(t, tt.key, RequestKey(req))
+
+			entry, err := a.Put(req, http.StatusOK, "application/json", []byte(`{"result":{}}`))
+			require.NoError(t, err)
+			require.Equal(t, tt.height, entry.Height)
+
+			got, body, err := a.Get(req)
+			require.NoError(t, err)
+			require.Equal(t, `{"result":{}}`, string(body))
+			require.Equal(t, entry.Key, got.Key)
+			require.Equal(t, entry.Object, got.Object)
+		})
+	}
+
+	// the same response body is stored once
+	objects, err := filepath.Glob(filepath.Join(dir, "objects", "*", "*.gz"))
+	require.NoError(t, err)
+	require.Len(t, objects, 1)
+
+	// requests differ only by node
+	_, body, err := a.Get(newRequest(t, "http://other:1317/bank/balances/terra1abc?height=10"))
+	require.NoError(t, err)
+	require.NotEmpty(t, body)
+
+	_, _, err = a.Get(newRequest(t, "http://lcd:1317/bank/balances/terra1abc?height=11"))
+	require.True(t, errors.Is(err, ErrNotArchived))
+}
+
+func TestArchiveTransport_RecordsSuccessfulResponses(t *testing.T) {
+	status := http.StatusOK
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		w.Write([]byte(http.StatusText(status)))
+	}))
+	defer srv.Close()
+
+	a, err := NewArchive(t.TempDir())
+	require.NoError(t, err)
+	record := &archiveTransport{archive: a, next: http.DefaultTransport, logger: zap.NewNop()}
+	replay := &archiveTransport{archive: a, logger: zap.NewNop()}
+
+	tests := []struct {
+		name   string
+		url    string
+		status int
+		// wantReplay is the status replayed from archive afterwards, 0 when the request isn't archived
+		wantReplay int
+	}{
+		{"ok", srv.URL + "/bank/balances/terra1abc", http.StatusOK, http.StatusOK},
+		{"not found isn't archived", srv.URL + "/bank/balances/terra1def", http.StatusNotFound, 0},
+		{"server error keeps archived ok", srv.URL + "/bank/balances/terra1abc", http.StatusServiceUnavailable, http.StatusOK},
+		{"bad request keeps archived ok", srv.URL + "/bank/balances/terra1abc", http.StatusBadRequest, http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status = tt.status
+			resp, err := record.RoundTrip(newRequest(t, tt.url))
+			require.NoError(t, err)
+			body, err := ioutil.ReadAll(resp.Body)
+			resp.Body.Close()
+			require.NoError(t, err)
+			require.Equal(t, tt.status, resp.StatusCode)
+			require.Equal(t, http.StatusText(tt.status), string(body))
+
+			resp, err = replay.RoundTrip(newRequest(t, tt.url))
+			if tt.wantReplay == 0 {
+				require.True(t, errors.Is(err, ErrNotArchived))
+				return
+			}
+			require.NoError(t, err)
+			body, err = ioutil.ReadAll(resp.Body)
+			resp.Body.Close()
+			require.NoError(t, err)
+			require.Equal(t, tt.wantReplay, resp.StatusCode)
+			require.Equal(t, http.StatusText(tt.wantReplay), string(body))
+		})
+	}
+}
@@ -0,0 +1,85 @@
+package api
+
+import (
+	"github.com/figment-networks/indexer-manager/structs"
+	"github.com/figment-networks/terra-worker/api/mapper"
+	"github.com/figment-networks/terra-worker/api/types"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/terra-project/core/x/auth"
+	"go.uber.org/zap"
+)
+
+// BalanceChangesKind is kind of transaction event listing balance changes of transaction.
+// Every change is a subevent of `balance_change` type, with changed `account` node,
+// signed `delta` amount and `reason` (fee, tax, transfer, reward, commission, mint, burn)
+const BalanceChangesKind = "balance_changes"
+
+// balanceChangesEvent lists balance changes of transaction: gas fee and stability tax paid
+// by fee payer to fee collector, followed by changes of every message taken from its log
+func balanceChangesEvent(logger *zap.Logger, tx *auth.StdTx, txLog []types.LogFormat, gasFee, tax sdk.Coins) (tev structs.TransactionEvent, ok bool) {
+	var changes []mapper.BalanceChange
+
+	payer := feePayer(tx)
+	feeCollector := mapper.ModuleAddress(auth.FeeCollectorName)
+	for _, f := range []struct {
+		coins  sdk.Coins
+		reason string
+	}{{gasFee, mapper.ReasonFee}, {tax, mapper.ReasonTax}} {
+		for _, coin := range f.coins {
+			am := mapper.CoinAmount(coin)
+			changes = append(changes,
+				mapper.BalanceChange{Account: payer, Amount: mapper.NegAmount(am), Reason: f.reason},
+				mapper.BalanceChange{Account: feeCollector, Amount: am, Reason: f.reason},
+			)
+		}
+	}
+
+	for _, lf := range txLog {
+		var msg sdk.Msg
+		if i := int(lf.MsgIndex); i < len(tx.Msgs) {
+			msg = tx.Msgs[i]
+		}
+		ch, err := mapper.MsgBalanceChanges(msg, lf)
+		if err != nil {
+			logger.Error("[TERRA-API] Problem reading balance changes", zap.Error(err), zap.Float64("msg_index", lf.MsgIndex))
+			continue
+		}
+		changes = append(changes, ch...)
+	}
+
+	if len(changes) == 0 {
+		return tev, false
+	}
+
+	tev.Kind = BalanceChangesKind
+	for _, c := range changes {
+		tev.Sub = append(tev.Sub, structs.SubsetEvent{
+			Type:       []string{"balance_change"},
+			Node:       map[string][]structs.Account{"account": {{ID: c.Account}}},
+			Amount:     map[string]structs.TransactionAmount{"delta": c.Amount},
+			Additional: map[string][]string{"reason": {c.Reason}},
+		})
+	}
+	return tev, true
+}
+
+// BalanceChanges returns balance changes listed in `balance_changes` event of transaction
+func BalanceChanges(tx structs.Transaction) (changes []mapper.BalanceChange) {
+	for _, ev := range tx.Events {
+		if ev.Kind != BalanceChangesKind {
+			continue
+		}
+		for _, sub := range ev.Sub {
+			c := mapper.BalanceChange{Amount: sub.Amount["delta"]}
+			if acc := sub.Node["account"]; len(acc) > 0 {
+				c.Account = acc[0].ID
+			}
+			if reason := sub.Additional["reason"]; len(reason) > 0 {
+				c.Reason = reason[0]
+			}
+			changes = append(changes, c)
+		}
+	}
+	return changes
+}
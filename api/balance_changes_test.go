@@ -0,0 +1,67 @@
+package api
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/figment-networks/indexer-manager/structs"
+	"github.com/figment-networks/terra-worker/api/mapper"
+	"github.com/stretchr/testify/require"
+)
+
+// requireBalanceChangesNetZero checks that balance changes of transaction net to zero in every currency,
+// apart from coins minted and burned
+func requireBalanceChangesNetZero(t *testing.T, tx structs.Transaction) {
+	t.Helper()
+
+	changes := BalanceChanges(tx)
+	if len(tx.Fee) > 0 {
+		require.NotEmpty(t, changes, "transaction %s with fee has no balance changes", tx.Hash)
+	}
+
+	net := map[string]*big.Rat{}
+	for _, c := range changes {
+		require.NotEmpty(t, c.Account, "balance change %+v without account", c)
+		require.NotEmpty(t, c.Reason, "balance change %+v without reason", c)
+		require.NotNil(t, c.Amount.Numeric, "balance change %+v without amount", c)
+		if c.Reason == mapper.ReasonMint || c.Reason == mapper.ReasonBurn {
+			continue
+		}
+
+		if net[c.Amount.Currency] == nil {
+			net[c.Amount.Currency] = new(big.Rat)
+		}
+		delta := new(big.Rat).SetFrac(c.Amount.Numeric, new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(c.Amount.Exp)), nil))
+		net[c.Amount.Currency].Add(net[c.Amount.Currency], delta)
+	}
+
+	for currency, sum := range net {
+		require.Zero(t, sum.Sign(), "balance changes of %s in transaction %s net to %s", currency, tx.Hash, sum.RatString())
+	}
+}
+
+func TestBalanceChanges(t *testing.T) {
+	tx := structs.Transaction{Events: structs.TransactionEvents{
+		{Kind: "send"},
+		{
+			Kind: BalanceChangesKind,
+			Sub: []structs.SubsetEvent{{
+				Type:       []string{"balance_change"},
+				Node:       map[string][]structs.Account{"account": {{ID: "terra1a"}}},
+				Amount:     map[string]structs.TransactionAmount{"delta": {Text: "-10", Numeric: big.NewInt(-10), Currency: "uluna"}},
+				Additional: map[string][]string{"reason": {mapper.ReasonFee}},
+			}, {
+				Type:       []string{"balance_change"},
+				Node:       map[string][]structs.Account{"account": {{ID: "terra1b"}}},
+				Amount:     map[string]structs.TransactionAmount{"delta": {Text: "10", Numeric: big.NewInt(10), Currency: "uluna"}},
+				Additional: map[string][]string{"reason": {mapper.ReasonFee}},
+			}},
+		},
+	}}
+
+	require.Equal(t, []mapper.BalanceChange{
+		{Account: "terra1a", Amount: structs.TransactionAmount{Text: "-10", Numeric: big.NewInt(-10), Currency: "uluna"}, Reason: mapper.ReasonFee},
+		{Account: "terra1b", Amount: structs.TransactionAmount{Text: "10", Numeric: big.NewInt(10), Currency: "uluna"}, Reason: mapper.ReasonFee},
+	}, BalanceChanges(tx))
+	require.Empty(t, BalanceChanges(structs.Transaction{}))
+}
@@ -0,0 +1,64 @@
+package api
+
+import (
+	"container/list"
+	"sync"
+)
+
+// lruCache keeps size least recently used values, it's safe for concurrent use
+type lruCache struct {
+	lock  sync.Mutex
+	size  int
+	items map[interface{}]*list.Element
+	order *list.List
+}
+
+type lruEntry struct {
+	key   interface{}
+	value interface{}
+}
+
+func newLRUCache(size int) *lruCache {
+	if size < 1 {
+		size = 1
+	}
+	return &lruCache{size: size, items: map[interface{}]*list.Element{}, order: list.New()}
+}
+
+// Get returns cached value of key, marking it as recently used
+func (c *lruCache) Get(key interface{}) (interface{}, bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	el, ok := c.items[key]
+	if !ok {
+		return nil, false
+	}
+	c.order.MoveToFront(el)
+	return el.Value.(*lruEntry).value, true
+}
+
+// Add caches value of key, evicting the least recently used value when the cache is full
+func (c *lruCache) Add(key, value interface{}) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if el, ok := c.items[key]; ok {
+		el.Value.(*lruEntry).value = value
+		c.order.MoveToFront(el)
+		return
+	}
+	c.items[key] = c.order.PushFront(&lruEntry{key: key, value: value})
+	if c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.items, oldest.Value.(*lruEntry).key)
+	}
+}
+
+// Len returns number of cached values
+func (c *lruCache) Len() int {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.order.Len()
+}
@@ -4,6 +4,8 @@ import (
 	"net/http"
 	"time"
 
+	"github.com/figment-networks/terra-worker/api/mapper"
+
 	amino "github.com/tendermint/go-amino"
 	"github.com/terra-project/core/app"
 	"github.com/terra-project/core/x/auth"
@@ -31,6 +33,8 @@ type Client struct {
 	logger      *zap.Logger
 	rateLimiter *rate.Limiter
 	cdc         *amino.Codec
+
+	mapperOptions mapper.Options
 }
 
 // NewClient returns a new client for a given endpoint
@@ -60,6 +64,11 @@ func (c *Client) CDC() *amino.Codec {
 	return c.cdc
 }
 
+// SetMapperOptions sets options of transactions converted by the client itself (SearchTx and plugin functions)
+func (c *Client) SetMapperOptions(opts mapper.Options) {
+	c.mapperOptions = opts
+}
+
 // InitMetrics initialise metrics
 func InitMetrics() {
 	transactionConversionDuration = conversionDuration.WithLabels("transaction")
@@ -3,24 +3,18 @@ package api
 import (
 	"context"
 	"encoding/json"
-	"errors"
 	"fmt"
-	"math/big"
 	"net"
 	"net/http"
 	"strconv"
-	"strings"
 	"time"
 
 	"github.com/cosmos/cosmos-sdk/types/rest"
 	"github.com/figment-networks/indexer-manager/structs"
+	"github.com/figment-networks/terra-worker/api/mapper"
 	"github.com/figment-networks/terra-worker/api/types"
 )
 
-var (
-	tenInt = big.NewInt(10)
-)
-
 // GetAccountDelegations fetches account delegations
 func (c *Client) GetAccountDelegations(ctx context.Context, params structs.HeightAccount) (resp structs.GetAccountDelegationsResponse, err error) {
 	resp.Height = params.Height
@@ -87,69 +81,25 @@ func (c *Client) GetAccountDelegations(ctx context.Context, params structs.Heigh
 	}
 
 	for _, del := range result.Delegations {
-		shareInt, shareExp, err := gettIntAndExp(del.Shares)
+		shares, err := mapper.ParseAmount("", del.Shares)
 		if err != nil {
 			return resp, fmt.Errorf("could not convert shares, %w", err)
 		}
 
-		amtInt, amtExp, err := gettIntAndExp(del.Balance.Amount)
+		balance, err := mapper.ParseAmount(del.Balance.Denom, del.Balance.Amount)
 		if err != nil {
-			return resp, fmt.Errorf("could not convert shares, %w", err)
+			return resp, fmt.Errorf("could not convert balance, %w", err)
 		}
 
 		resp.Delegations = append(resp.Delegations,
 			structs.Delegation{
 				Delegator: del.DelegatorAddress,
 				Validator: structs.Validator(del.ValidatorAddress),
-				Shares: structs.TransactionAmount{
-					Numeric: shareInt,
-					Exp:     shareExp,
-				},
-				Balance: structs.TransactionAmount{
-					Numeric:  amtInt,
-					Currency: del.Balance.Denom,
-					Exp:      amtExp,
-				},
+				Shares:    shares,
+				Balance:   balance,
 			},
 		)
 	}
 
 	return resp, err
 }
-
-// gettIntAndExp converts a string of an integer or float  and converts it
-// to a bigInt type, returning the int and number of decimal places
-// so "44.55" becomes 4455, 2
-func gettIntAndExp(s string) (*big.Int, int32, error) {
-	if s == "" {
-		return nil, 0, errors.New("foo")
-	}
-
-	parts := strings.Split(s, ".")
-	intgrPart := parts[0]
-	intgr, ok := new(big.Int).SetString(intgrPart, 10)
-	if !ok {
-		return nil, 0, errors.New("foo")
-	}
-
-	if len(parts) == 1 {
-		return intgr, 0, nil
-	} else if len(parts) > 2 {
-		return nil, 0, errors.New("foo")
-	}
-
-	decPart := parts[1]
-	dec, ok := new(big.Int).SetString(decPart, 10)
-	if !ok {
-		return nil, 0, errors.New("foo")
-	}
-
-	if dec.Cmp(big.NewInt(0)) == 0 {
-		return intgr, 0, nil
-	}
-
-	exp := int32(len(decPart))
-	mltplr := new(big.Int).Exp(tenInt, big.NewInt(int64(exp)), nil)
-	intgr.Mul(intgr, mltplr)
-	return intgr.Add(intgr, dec), exp, nil
-}
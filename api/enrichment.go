@@ -0,0 +1,22 @@
+package api
+
+import (
+	"context"
+
+	cStruct "github.com/figment-networks/indexer-manager/worker/connectivity/structs"
+)
+
+// EnrichCh passes responses from in to out, replacing each with the result of enrich.
+// It's the shared body of enrichment stages following RawToTransactionCh: when ctx is done
+// remaining responses of in are dropped, so upstream stages are not left blocked on sending
+func EnrichCh(ctx context.Context, in <-chan cStruct.OutResp, out chan<- cStruct.OutResp, enrich func(cStruct.OutResp) cStruct.OutResp) {
+	for resp := range in {
+		select {
+		case out <- enrich(resp):
+		case <-ctx.Done():
+			for range in {
+			}
+			return
+		}
+	}
+}
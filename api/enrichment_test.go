@@ -0,0 +1,53 @@
+package api
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	cStruct "github.com/figment-networks/indexer-manager/worker/connectivity/structs"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEnrichCh(t *testing.T) {
+	in := make(chan cStruct.OutResp, 2)
+	out := make(chan cStruct.OutResp, 2)
+	in <- cStruct.OutResp{Type: "Transaction"}
+	in <- cStruct.OutResp{Type: "Block"}
+	close(in)
+
+	EnrichCh(context.Background(), in, out, func(resp cStruct.OutResp) cStruct.OutResp {
+		resp.Type += "!"
+		return resp
+	})
+	close(out)
+
+	var types []string
+	for resp := range out {
+		types = append(types, resp.Type)
+	}
+	require.Equal(t, []string{"Transaction!", "Block!"}, types)
+}
+
+func TestEnrichCh_DrainsOnCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	in := make(chan cStruct.OutResp)
+	sent := make(chan struct{})
+	go func() {
+		defer close(sent)
+		for i := 0; i < 10; i++ {
+			in <- cStruct.OutResp{Type: "Block"}
+		}
+		close(in)
+	}()
+
+	// out is never read, so responses can only be dropped
+	EnrichCh(ctx, in, make(chan cStruct.OutResp), func(resp cStruct.OutResp) cStruct.OutResp { return resp })
+	select {
+	case <-sent:
+	case <-time.After(time.Second):
+		t.Fatal("sender is blocked after cancel")
+	}
+}
@@ -0,0 +1,99 @@
+package api
+
+import (
+	"strconv"
+
+	"github.com/figment-networks/indexer-manager/structs"
+	"github.com/figment-networks/terra-worker/api/mapper"
+	"github.com/figment-networks/terra-worker/api/types"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/terra-project/core/x/auth"
+	"go.uber.org/zap"
+)
+
+// feeBreakdownEvent describes transaction fee split into gas fee and stability tax (see splitFee),
+// gas price is the gas fee divided by gas wanted.
+func feeBreakdownEvent(tx *auth.StdTx, gasFee, tax sdk.Coins, gasWanted uint64) (tev structs.TransactionEvent, ok bool) {
+	if len(tx.Fee.Amount) == 0 {
+		return tev, false
+	}
+
+	payer := feePayer(tx)
+
+	sub := structs.SubsetEvent{
+		Type:       []string{"fee"},
+		Module:     "auth",
+		Amount:     map[string]structs.TransactionAmount{},
+		Transfers:  map[string][]structs.EventTransfer{},
+		Additional: map[string][]string{"gas_wanted": {strconv.FormatUint(gasWanted, 10)}},
+	}
+
+	if payer != "" {
+		sub.Node = map[string][]structs.Account{"payer": {{ID: payer}}}
+	}
+
+	gasFeeTransfer := structs.EventTransfer{Account: structs.Account{ID: payer}}
+	for _, coin := range gasFee {
+		am := mapper.CoinAmount(coin)
+		sub.Amount["gas_fee_"+coin.Denom] = am
+		gasFeeTransfer.Amounts = append(gasFeeTransfer.Amounts, am)
+
+		if gasWanted > 0 {
+			price := sdk.NewDecFromInt(coin.Amount).QuoInt64(int64(gasWanted))
+			sub.Amount["gas_price_"+coin.Denom] = mapper.DecAmount(coin.Denom, price)
+		}
+	}
+	if len(gasFeeTransfer.Amounts) > 0 {
+		sub.Transfers["gas_fee"] = []structs.EventTransfer{gasFeeTransfer}
+	}
+
+	taxTransfer := structs.EventTransfer{Account: structs.Account{ID: payer}}
+	for _, coin := range tax {
+		am := mapper.CoinAmount(coin)
+		sub.Amount["tax_"+coin.Denom] = am
+		taxTransfer.Amounts = append(taxTransfer.Amounts, am)
+	}
+	if len(taxTransfer.Amounts) > 0 {
+		sub.Transfers["tax"] = []structs.EventTransfer{taxTransfer}
+	}
+
+	return structs.TransactionEvent{
+		Kind: "fee",
+		Sub:  []structs.SubsetEvent{sub},
+	}, true
+}
+
+// splitFee splits transaction fee into gas fee and stability tax.
+// Terra includes the tax charged on bank sends and swapsends into the fee paid,
+// the tax part is taken from the `tax` attribute of message logs, the rest of the fee is the gas fee.
+// Logs report the tax up to columbus-3 only, later transactions are split by TaxResolver.
+func splitFee(logger *zap.Logger, tx *auth.StdTx, txLog []types.LogFormat) (gasFee, tax sdk.Coins) {
+	tax = sdk.NewCoins()
+	for _, lf := range txLog {
+		if lf.Log.Tax == "" {
+			continue
+		}
+		coins, err := sdk.ParseCoins(lf.Log.Tax)
+		if err != nil {
+			logger.Error("[TERRA-API] Problem parsing tax", zap.Error(err), zap.String("tax", lf.Log.Tax))
+			continue
+		}
+		tax = tax.Add(coins...)
+	}
+
+	gasFee, negative := tx.Fee.Amount.SafeSub(tax)
+	if negative {
+		logger.Error("[TERRA-API] Tax is higher than fee", zap.Stringer("fee", tx.Fee.Amount), zap.Stringer("tax", tax))
+		return tx.Fee.Amount, sdk.NewCoins()
+	}
+	return gasFee, tax
+}
+
+// feePayer returns address of account paying the fee
+func feePayer(tx *auth.StdTx) (payer string) {
+	if fp := tx.FeePayer(); !fp.Empty() {
+		payer, _ = mapper.AccAddress(fp)
+	}
+	return payer
+}
@@ -0,0 +1,231 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/figment-networks/indexer-manager/structs"
+	"github.com/figment-networks/terra-worker/api/mapper"
+	"github.com/figment-networks/terra-worker/api/types"
+)
+
+// govVotesPerPage is the page size of proposal votes requests (the lcd default)
+const govVotesPerPage = 100
+
+// GovProposal is governance proposal with its current status
+type GovProposal struct {
+	ID          uint64          `json:"id"`
+	Height      uint64          `json:"height"`
+	Type        string          `json:"type"`
+	Title       string          `json:"title"`
+	Description string          `json:"description"`
+	Content     json.RawMessage `json:"content"`
+	Status      string          `json:"status"`
+
+	SubmitTime      time.Time `json:"submit_time"`
+	DepositEndTime  time.Time `json:"deposit_end_time"`
+	VotingStartTime time.Time `json:"voting_start_time"`
+	VotingEndTime   time.Time `json:"voting_end_time"`
+
+	TotalDeposit     []structs.TransactionAmount `json:"total_deposit"`
+	FinalTallyResult GovTally                    `json:"final_tally_result"`
+}
+
+// GovVote is vote of single account on proposal
+type GovVote struct {
+	ProposalID uint64 `json:"proposal_id"`
+	Height     uint64 `json:"height"`
+	Voter      string `json:"voter"`
+	Option     string `json:"option"`
+}
+
+// GovDeposit is deposit of single account on proposal
+type GovDeposit struct {
+	ProposalID uint64                      `json:"proposal_id"`
+	Height     uint64                      `json:"height"`
+	Depositor  string                      `json:"depositor"`
+	Amount     []structs.TransactionAmount `json:"amount"`
+}
+
+// GovTally is voting power of given options
+type GovTally struct {
+	Yes        structs.TransactionAmount `json:"yes"`
+	Abstain    structs.TransactionAmount `json:"abstain"`
+	No         structs.TransactionAmount `json:"no"`
+	NoWithVeto structs.TransactionAmount `json:"no_with_veto"`
+}
+
+// GetGovProposals fetches proposals, optionally filtered by status (eg. `voting_period`, `passed`)
+func (c *Client) GetGovProposals(ctx context.Context, status string, height uint64) (proposals []GovProposal, err error) {
+	endpoint := "/gov/proposals"
+	if status != "" {
+		endpoint += "?status=" + url.QueryEscape(status)
+	}
+
+	var result []types.GovProposal
+	if err = c.getLCD(ctx, endpoint, "/gov/proposals", height, &result); err != nil {
+		return nil, err
+	}
+
+	for _, p := range result {
+		proposal, err := govProposal(p, height)
+		if err != nil {
+			return nil, err
+		}
+		proposals = append(proposals, proposal)
+	}
+	return proposals, nil
+}
+
+// GetGovProposal fetches single proposal
+func (c *Client) GetGovProposal(ctx context.Context, proposalID, height uint64) (proposal GovProposal, err error) {
+	var result types.GovProposal
+	endpoint := fmt.Sprintf("/gov/proposals/%d", proposalID)
+	if err = c.getLCD(ctx, endpoint, "/gov/proposals/_", height, &result); err != nil {
+		return proposal, err
+	}
+	return govProposal(result, height)
+}
+
+// GetGovVotes fetches votes of the proposal, page by page. Votes are removed from the state after voting ends.
+// Paging stops at the last page, at the total reported by lcd, or at page without new voters (lcd ignoring page and limit)
+func (c *Client) GetGovVotes(ctx context.Context, proposalID, height uint64) (votes []GovVote, err error) {
+	voters := map[string]bool{}
+	for page := 1; ; page++ {
+		var result []types.GovVote
+		endpoint := fmt.Sprintf("/gov/proposals/%d/votes?page=%d&limit=%d", proposalID, page, govVotesPerPage)
+		total, err := c.getLCDPage(ctx, endpoint, "/gov/proposals/_/votes", height, &result)
+		if err != nil {
+			return nil, err
+		}
+
+		added := 0
+		for _, v := range result {
+			// every voter has single vote
+			if voters[v.Voter] {
+				continue
+			}
+			voters[v.Voter] = true
+			added++
+			votes = append(votes, GovVote{
+				ProposalID: proposalID,
+				Height:     height,
+				Voter:      v.Voter,
+				Option:     v.Option,
+			})
+		}
+
+		if len(result) < govVotesPerPage || added == 0 || (total > 0 && uint64(len(votes)) >= total) {
+			return votes, nil
+		}
+	}
+}
+
+// GetGovDeposits fetches deposits of the proposal
+func (c *Client) GetGovDeposits(ctx context.Context, proposalID, height uint64) (deposits []GovDeposit, err error) {
+	var result []types.GovDeposit
+	endpoint := fmt.Sprintf("/gov/proposals/%d/deposits", proposalID)
+	if err = c.getLCD(ctx, endpoint, "/gov/proposals/_/deposits", height, &result); err != nil {
+		return nil, err
+	}
+
+	for _, d := range result {
+		amount, err := balancesToAmounts(d.Amount)
+		if err != nil {
+			return nil, fmt.Errorf("could not convert deposit of %s: %w", d.Depositor, err)
+		}
+		deposits = append(deposits, GovDeposit{
+			ProposalID: proposalID,
+			Height:     height,
+			Depositor:  d.Depositor,
+			Amount:     amount,
+		})
+	}
+	return deposits, nil
+}
+
+// GetGovTally fetches tally of the proposal, current one for proposals in voting period and final for finished ones
+func (c *Client) GetGovTally(ctx context.Context, proposalID, height uint64) (tally GovTally, err error) {
+	var result types.GovTally
+	endpoint := fmt.Sprintf("/gov/proposals/%d/tally", proposalID)
+	if err = c.getLCD(ctx, endpoint, "/gov/proposals/_/tally", height, &result); err != nil {
+		return tally, err
+	}
+	return govTally(result)
+}
+
+func govProposal(p types.GovProposal, height uint64) (proposal GovProposal, err error) {
+	proposal = GovProposal{
+		Height:          height,
+		Type:            p.Content.Type,
+		Content:         p.Content.Value,
+		Status:          p.Status,
+		SubmitTime:      p.SubmitTime,
+		DepositEndTime:  p.DepositEndTime,
+		VotingStartTime: p.VotingStartTime,
+		VotingEndTime:   p.VotingEndTime,
+	}
+
+	if proposal.ID, err = strconv.ParseUint(p.ID, 10, 64); err != nil {
+		return proposal, fmt.Errorf("could not parse proposal id %q: %w", p.ID, err)
+	}
+
+	content := struct {
+		Title       string `json:"title"`
+		Description string `json:"description"`
+	}{}
+	if len(p.Content.Value) > 0 {
+		if err = json.Unmarshal(p.Content.Value, &content); err != nil {
+			return proposal, fmt.Errorf("could not decode content of proposal %d: %w", proposal.ID, err)
+		}
+	}
+	proposal.Title = content.Title
+	proposal.Description = content.Description
+
+	if proposal.TotalDeposit, err = balancesToAmounts(p.TotalDeposit); err != nil {
+		return proposal, fmt.Errorf("could not convert deposit of proposal %d: %w", proposal.ID, err)
+	}
+
+	if proposal.FinalTallyResult, err = govTally(p.FinalTallyResult); err != nil {
+		return proposal, fmt.Errorf("could not convert tally of proposal %d: %w", proposal.ID, err)
+	}
+
+	return proposal, nil
+}
+
+func govTally(t types.GovTally) (tally GovTally, err error) {
+	for _, o := range []struct {
+		text string
+		am   *structs.TransactionAmount
+	}{
+		{t.Yes, &tally.Yes},
+		{t.Abstain, &tally.Abstain},
+		{t.No, &tally.No},
+		{t.NoWithVeto, &tally.NoWithVeto},
+	} {
+		if o.text == "" {
+			o.text = "0"
+		}
+		am, err := mapper.ParseAmount("", o.text)
+		if err != nil {
+			return tally, fmt.Errorf("could not parse tally %q: %w", o.text, err)
+		}
+		*o.am = am
+	}
+	return tally, nil
+}
+
+func balancesToAmounts(balances []types.Balance) (amounts []structs.TransactionAmount, err error) {
+	for _, b := range balances {
+		am, err := mapper.ParseAmount(b.Denom, b.Amount)
+		if err != nil {
+			return nil, fmt.Errorf("could not parse amount %q: %w", b.Amount, err)
+		}
+		amounts = append(amounts, am)
+	}
+	return amounts, nil
+}
@@ -0,0 +1,95 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/cosmos/cosmos-sdk/types/rest"
+)
+
+// lcdResponse is generic terra lcd response wrapper. Total is set only by lcds reporting count of paginated results
+type lcdResponse struct {
+	Height string          `json:"height"`
+	Result json.RawMessage `json:"result"`
+	Total  json.Number     `json:"total,omitempty"`
+}
+
+// getLCD makes GET request to the lcd endpoint at given height and decodes `result` into out.
+// The metricName is used as endpoint label, so it should not contain any variable parts
+func (c *Client) getLCD(ctx context.Context, endpoint, metricName string, height uint64, out interface{}) error {
+	_, err := c.getLCDPage(ctx, endpoint, metricName, height, out)
+	return err
+}
+
+// getLCDPage is getLCD of paginated endpoint, returning count of all results when lcd reports it (0 otherwise)
+func (c *Client) getLCDPage(ctx context.Context, endpoint, metricName string, height uint64, out interface{}) (total uint64, err error) {
+	req, err := http.NewRequest(http.MethodGet, c.baseURL+endpoint, nil)
+	if err != nil {
+		return 0, err
+	}
+
+	req.Header.Add("Content-Type", "application/json")
+	if c.key != "" {
+		req.Header.Add("Authorization", c.key)
+	}
+
+	q := req.URL.Query()
+	if height > 0 {
+		q.Add("height", strconv.FormatUint(height, 10))
+	}
+	req.URL.RawQuery = q.Encode()
+
+	if c.rateLimiter != nil {
+		err = c.rateLimiter.Wait(ctx)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	var cliResp *http.Response
+	for i := 1; i <= maxRetries; i++ {
+		n := time.Now()
+		cliResp, err = c.httpClient.Do(req)
+		if err, ok := err.(net.Error); ok && err.Timeout() && i != maxRetries {
+			continue
+		} else if err != nil {
+			return 0, err
+		}
+		rawRequestHTTPDuration.WithLabels(metricName, cliResp.Status).Observe(time.Since(n).Seconds())
+
+		if cliResp.StatusCode < 500 || i == maxRetries {
+			break
+		}
+		// body of failed attempt is closed before retrying, so its connection can be reused
+		cliResp.Body.Close()
+		time.Sleep(time.Duration(i*500) * time.Millisecond)
+	}
+	defer cliResp.Body.Close()
+
+	decoder := json.NewDecoder(cliResp.Body)
+
+	if cliResp.StatusCode > 399 {
+		var result rest.ErrorResponse
+		if err = decoder.Decode(&result); err != nil {
+			return 0, fmt.Errorf("[TERRA-API] Error fetching %s: %d", metricName, cliResp.StatusCode)
+		}
+		return 0, fmt.Errorf("[TERRA-API] Error fetching %s: %s ", metricName, result.Error)
+	}
+
+	var result lcdResponse
+	if err = decoder.Decode(&result); err != nil {
+		return 0, err
+	}
+
+	if result.Total != "" {
+		if total, err = strconv.ParseUint(result.Total.String(), 10, 64); err != nil {
+			return 0, fmt.Errorf("[TERRA-API] Error fetching %s: invalid total %q", metricName, result.Total)
+		}
+	}
+	return total, json.Unmarshal(result.Result, out)
+}
@@ -0,0 +1,74 @@
+package api
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/figment-networks/indexer-manager/structs"
+	"github.com/figment-networks/terra-worker/test/fakeserver"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_GetLCDWithoutRateLimiter(t *testing.T) {
+	InitMetrics()
+	s := fakeserver.New("columbus-4")
+	defer s.Close()
+	validator := "terravaloper1lq40xgtqh3f3zt9prz4m74l6dlk506usv9ag54"
+	require.NoError(t, s.SetLCD("/oracle/voters/"+validator+"/feeder", "terra190vqdjtlpcq27xslcveglfmr4ynfwg7gxlzhjn"))
+
+	c := &Client{baseURL: s.URL, httpClient: http.DefaultClient}
+	feeder, err := c.GetOracleFeeder(context.Background(), structs.HeightAccount{Account: validator})
+	require.NoError(t, err)
+	require.Equal(t, "terra190vqdjtlpcq27xslcveglfmr4ynfwg7gxlzhjn", feeder)
+}
+
+// closeCounter is response body counting its closes
+type closeCounter struct {
+	io.Reader
+	closed *int
+}
+
+func (c closeCounter) Close() error {
+	*c.closed++
+	return nil
+}
+
+// statusTransport responds with statuses in order, recording bodies left open when the next request is sent
+type statusTransport struct {
+	statuses []int
+	sent     int
+	closed   int
+	open     []int
+}
+
+func (t *statusTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if t.closed < t.sent {
+		t.open = append(t.open, t.sent)
+	}
+	t.sent++
+	status := t.statuses[0]
+	t.statuses = t.statuses[1:]
+	return &http.Response{
+		Status:     http.StatusText(status),
+		StatusCode: status,
+		Body:       closeCounter{Reader: strings.NewReader(`{"height":"1","result":"terra1"}`), closed: &t.closed},
+		Request:    req,
+	}, nil
+}
+
+func TestClient_GetLCDPageClosesRetriedBodies(t *testing.T) {
+	InitMetrics()
+	tr := &statusTransport{statuses: []int{http.StatusServiceUnavailable, http.StatusBadGateway, http.StatusOK}}
+	c := &Client{baseURL: "http://lcd", httpClient: &http.Client{Transport: tr}}
+
+	var out string
+	_, err := c.getLCDPage(context.Background(), "/oracle/voters/x/feeder", "test", 0, &out)
+	require.NoError(t, err)
+	require.Equal(t, "terra1", out)
+	require.Empty(t, tr.statuses)
+	require.Empty(t, tr.open, "bodies of failed attempts are closed before retrying")
+	require.Equal(t, 3, tr.closed)
+}
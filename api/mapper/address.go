@@ -0,0 +1,29 @@
+package mapper
+
+import (
+	"github.com/tendermint/tendermint/libs/bech32"
+	"github.com/terra-project/core/types/util"
+)
+
+// AccAddress encodes account address with terra prefix, regardless of the global sdk config.
+// Empty address is returned as empty string.
+func AccAddress(addr []byte) (string, error) {
+	return encodeAddress(util.Bech32PrefixAccAddr, addr)
+}
+
+// ValAddress encodes validator operator address with terra prefix
+func ValAddress(addr []byte) (string, error) {
+	return encodeAddress(util.Bech32PrefixValAddr, addr)
+}
+
+// ConsAddress encodes validator consensus address with terra prefix
+func ConsAddress(addr []byte) (string, error) {
+	return encodeAddress(util.Bech32PrefixConsAddr, addr)
+}
+
+func encodeAddress(prefix string, addr []byte) (string, error) {
+	if len(addr) == 0 {
+		return "", nil
+	}
+	return bech32.ConvertAndEncode(prefix, addr)
+}
@@ -0,0 +1,270 @@
+package mapper
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/figment-networks/indexer-manager/structs"
+	"github.com/figment-networks/terra-worker/api/types"
+	"github.com/stretchr/testify/require"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	distributiontypes "github.com/cosmos/cosmos-sdk/x/distribution/types"
+	"github.com/tendermint/tendermint/crypto/ed25519"
+	"github.com/tendermint/tendermint/libs/bech32"
+	"github.com/terra-project/core/app"
+	"github.com/terra-project/core/x/auth"
+
+	"github.com/terra-project/core/x/bank"
+	"github.com/terra-project/core/x/crisis"
+	"github.com/terra-project/core/x/distribution"
+	"github.com/terra-project/core/x/evidence"
+	"github.com/terra-project/core/x/gov"
+	"github.com/terra-project/core/x/market"
+	"github.com/terra-project/core/x/msgauth"
+	"github.com/terra-project/core/x/oracle"
+	"github.com/terra-project/core/x/slashing"
+	"github.com/terra-project/core/x/staking"
+	"github.com/terra-project/core/x/wasm"
+)
+
+var bech32Candidate = regexp.MustCompile(`[a-z]+1[02-9ac-hj-np-z]{38,}`)
+
+// TestAddressPrefixConformance runs every mapper with sdk configured to default cosmos prefixes
+// and checks that all addresses in the output are encoded with terra prefixes.
+// Mappers are run without log and with every fixture log of the same message type
+func TestAddressPrefixConformance(t *testing.T) {
+	logs := fixtureLogs(t)
+
+	cfg := sdk.GetConfig()
+	accAddr, accPub := cfg.GetBech32AccountAddrPrefix(), cfg.GetBech32AccountPubPrefix()
+	valAddr, valPub := cfg.GetBech32ValidatorAddrPrefix(), cfg.GetBech32ValidatorPubPrefix()
+	consAddr, consPub := cfg.GetBech32ConsensusAddrPrefix(), cfg.GetBech32ConsensusPubPrefix()
+	t.Cleanup(func() {
+		cfg.SetBech32PrefixForAccount(accAddr, accPub)
+		cfg.SetBech32PrefixForValidator(valAddr, valPub)
+		cfg.SetBech32PrefixForConsensusNode(consAddr, consPub)
+	})
+	cfg.SetBech32PrefixForAccount(sdk.Bech32PrefixAccAddr, sdk.Bech32PrefixAccPub)
+	cfg.SetBech32PrefixForValidator(sdk.Bech32PrefixValAddr, sdk.Bech32PrefixValPub)
+	cfg.SetBech32PrefixForConsensusNode(sdk.Bech32PrefixConsAddr, sdk.Bech32PrefixConsPub)
+
+	acc1 := sdk.AccAddress([]byte("account_address_0001"))
+	acc2 := sdk.AccAddress([]byte("account_address_0002"))
+	val1 := sdk.ValAddress([]byte("validator_address_01"))
+	val2 := sdk.ValAddress([]byte("validator_address_02"))
+	coins := sdk.NewCoins(sdk.NewInt64Coin("uluna", 1000))
+	coin := sdk.NewInt64Coin("uluna", 1000)
+
+	rate := sdk.NewDecWithPrec(1, 1)
+	minSelf := sdk.NewInt(1)
+
+	tests := []struct {
+		name   string
+		mapper func(logf types.LogFormat) (structs.SubsetEvent, error)
+	}{
+		{"bank send", func(logf types.LogFormat) (structs.SubsetEvent, error) {
+			return BankSendToSub(bank.NewMsgSend(acc1, acc2, coins), logf)
+		}},
+		{"bank multisend", func(logf types.LogFormat) (structs.SubsetEvent, error) {
+			return BankMultisendToSub(bank.NewMsgMultiSend([]bank.Input{bank.NewInput(acc1, coins)}, []bank.Output{bank.NewOutput(acc2, coins)}), logf)
+		}},
+		{"crisis verify invariant", func(logf types.LogFormat) (structs.SubsetEvent, error) {
+			return CrisisVerifyInvariantToSub(crisis.NewMsgVerifyInvariant(acc1, "bank", "total-supply"))
+		}},
+		{"distribution withdraw validator commission", func(logf types.LogFormat) (structs.SubsetEvent, error) {
+			return DistributionWithdrawValidatorCommissionToSub(distribution.NewMsgWithdrawValidatorCommission(val1), logf)
+		}},
+		{"distribution set withdraw address", func(logf types.LogFormat) (structs.SubsetEvent, error) {
+			return DistributionSetWithdrawAddressToSub(distribution.NewMsgSetWithdrawAddress(acc1, acc2))
+		}},
+		{"distribution withdraw delegator reward", func(logf types.LogFormat) (structs.SubsetEvent, error) {
+			return DistributionWithdrawDelegatorRewardToSub(distribution.NewMsgWithdrawDelegatorReward(acc1, val1), logf)
+		}},
+		{"distribution fund community pool", func(logf types.LogFormat) (structs.SubsetEvent, error) {
+			return DistributionFundCommunityPoolToSub(distributiontypes.MsgFundCommunityPool{Amount: coins, Depositor: acc1})
+		}},
+		{"evidence submit evidence", func(logf types.LogFormat) (structs.SubsetEvent, error) {
+			ev := evidence.Equivocation{Height: 10, Time: time.Unix(0, 0), Power: 10, ConsensusAddress: sdk.ConsAddress(val1)}
+			return EvidenceSubmitEvidenceToSub(evidence.MsgSubmitEvidence{Evidence: ev, Submitter: acc1})
+		}},
+		{"gov deposit", func(logf types.LogFormat) (structs.SubsetEvent, error) {
+			return GovDepositToSub(gov.NewMsgDeposit(acc1, 1, coins), logf)
+		}},
+		{"gov vote", func(logf types.LogFormat) (structs.SubsetEvent, error) {
+			return GovVoteToSub(gov.NewMsgVote(acc1, 1, gov.OptionYes))
+		}},
+		{"gov submit proposal", func(logf types.LogFormat) (structs.SubsetEvent, error) {
+			content := distribution.NewCommunityPoolSpendProposal("title", "description", acc2, coins)
+			return GovSubmitProposalToSub(gov.NewMsgSubmitProposal(content, coins, acc1), logf)
+		}},
+		{"market swap", func(logf types.LogFormat) (structs.SubsetEvent, error) {
+			return MarketSwapToSub(market.NewMsgSwap(acc1, coin, "uusd"), logf)
+		}},
+		{"market swapsend", func(logf types.LogFormat) (structs.SubsetEvent, error) {
+			return MarketSwapSendToSub(market.NewMsgSwapSend(acc1, acc2, coin, "uusd"), logf)
+		}},
+		{"msgauth exec authorized", func(logf types.LogFormat) (structs.SubsetEvent, error) {
+			se, _, err := MsgauthExecAuthorizedToSub(msgauth.NewMsgExecAuthorized(acc1, []sdk.Msg{bank.NewMsgSend(acc2, acc1, coins)}))
+			return se, err
+		}},
+		{"msgauth grant authorization", func(logf types.LogFormat) (structs.SubsetEvent, error) {
+			return MsgauthGrantAuthorizationToSub(msgauth.NewMsgGrantAuthorization(acc1, acc2, msgauth.NewSendAuthorization(coins), time.Hour))
+		}},
+		{"msgauth revoke authorization", func(logf types.LogFormat) (structs.SubsetEvent, error) {
+			return MsgauthRevokeAuthorizationToSub(msgauth.NewMsgRevokeAuthorization(acc1, acc2, "send"))
+		}},
+		{"oracle exchange rate vote", func(logf types.LogFormat) (structs.SubsetEvent, error) {
+			return OracleExchangeRateVoteToSub(oracle.NewMsgExchangeRateVote(rate, "salt", "ukrw", acc1, val1))
+		}},
+		{"oracle exchange rate prevote", func(logf types.LogFormat) (structs.SubsetEvent, error) {
+			return OracleExchangeRatePrevoteToSub(oracle.NewMsgExchangeRatePrevote(oracle.VoteHash{}, "ukrw", acc1, val1))
+		}},
+		{"oracle delegate feed consent", func(logf types.LogFormat) (structs.SubsetEvent, error) {
+			return OracleDelegateFeedConsent(oracle.NewMsgDelegateFeedConsent(val1, acc1))
+		}},
+		{"oracle aggregate exchange rate prevote", func(logf types.LogFormat) (structs.SubsetEvent, error) {
+			return OracleAggregateExchangeRatePrevoteToSub(oracle.NewMsgAggregateExchangeRatePrevote(oracle.AggregateVoteHash{}, acc1, val1))
+		}},
+		{"oracle aggregate exchange rate vote", func(logf types.LogFormat) (structs.SubsetEvent, error) {
+			return OracleAggregateExchangeRateVoteToSub(oracle.NewMsgAggregateExchangeRateVote("salt", "1.0ukrw", acc1, val1))
+		}},
+		{"slashing unjail", func(logf types.LogFormat) (structs.SubsetEvent, error) {
+			return SlashingUnjailToSub(slashing.NewMsgUnjail(val1))
+		}},
+		{"staking delegate", func(logf types.LogFormat) (structs.SubsetEvent, error) {
+			return StakingDelegateToSub(staking.NewMsgDelegate(acc1, val1, coin), logf)
+		}},
+		{"staking undelegate", func(logf types.LogFormat) (structs.SubsetEvent, error) {
+			return StakingUndelegateToSub(staking.NewMsgUndelegate(acc1, val1, coin), logf)
+		}},
+		{"staking begin redelegate", func(logf types.LogFormat) (structs.SubsetEvent, error) {
+			return StakingBeginRedelegateToSub(staking.NewMsgBeginRedelegate(acc1, val1, val2, coin), logf)
+		}},
+		{"staking create validator", func(logf types.LogFormat) (structs.SubsetEvent, error) {
+			commission := staking.NewCommissionRates(rate, rate, rate)
+			return StakingCreateValidatorToSub(staking.NewMsgCreateValidator(val1, ed25519.GenPrivKey().PubKey(), coin, staking.Description{Moniker: "moniker"}, commission, minSelf))
+		}},
+		{"staking edit validator", func(logf types.LogFormat) (structs.SubsetEvent, error) {
+			return StakingEditValidatorToSub(staking.NewMsgEditValidator(val1, staking.Description{Moniker: "moniker"}, &rate, &minSelf))
+		}},
+		{"wasm execute contract", func(logf types.LogFormat) (structs.SubsetEvent, error) {
+			return WasmExecuteContractToSub(wasm.NewMsgExecuteContract(acc1, acc2, []byte(`{"transfer":{"recipient":"`+mustAccAddress(t, acc1)+`","amount":"1"}}`), coins), logf, Options{Contracts: cw20Contracts(t, mustAccAddress(t, acc2))})
+		}},
+		{"wasm store code", func(logf types.LogFormat) (structs.SubsetEvent, error) {
+			return WasmStoreCodeToSub(wasm.NewMsgStoreCode(acc1, []byte("code")), logf, Options{})
+		}},
+		{"wasm update contract owner", func(logf types.LogFormat) (structs.SubsetEvent, error) {
+			return WasmMsgUpdateContractOwnerToSub(wasm.NewMsgUpdateContractOwner(acc1, acc2, acc2))
+		}},
+		{"wasm instantiate contract", func(logf types.LogFormat) (structs.SubsetEvent, error) {
+			return WasmMsgInstantiateContractToSub(wasm.NewMsgInstantiateContract(acc1, 1, []byte(`{}`), coins, true), logf)
+		}},
+		{"wasm migrate contract", func(logf types.LogFormat) (structs.SubsetEvent, error) {
+			return WasmMsgMigrateContractToSub(wasm.NewMsgMigrateContract(acc1, acc2, 2, []byte(`{}`)))
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			se, err := tt.mapper(types.LogFormat{})
+			require.NoError(t, err)
+			require.NotZero(t, requireTerraAddresses(t, se), "no addresses found in mapper output")
+
+			for _, logf := range logs[se.Type[0]] {
+				se, err := tt.mapper(logf)
+				require.NoError(t, err)
+				requireTerraAddresses(t, se)
+			}
+		})
+	}
+}
+
+// requireTerraAddresses checks that all addresses in subevent have terra prefixes, returns the number of addresses
+func requireTerraAddresses(t *testing.T, se structs.SubsetEvent) (found int) {
+	t.Helper()
+	for _, s := range subsetEventStrings(se) {
+		for _, candidate := range bech32Candidate.FindAllString(s, -1) {
+			hrp, _, err := bech32.DecodeAndConvert(candidate)
+			if err != nil {
+				continue
+			}
+			found++
+			require.Truef(t, strings.HasPrefix(hrp, "terra"), "address %s has non-terra prefix in %q", candidate, s)
+		}
+	}
+	return found
+}
+
+// fixtureLogs returns logs of messages in tx_search fixtures by message type
+func fixtureLogs(t *testing.T) map[string][]types.LogFormat {
+	t.Helper()
+	cdc := app.MakeCodec()
+
+	fixtures, err := filepath.Glob(filepath.Join("..", "testdata", "tx_search", "*", "*.json"))
+	require.NoError(t, err)
+	require.NotEmpty(t, fixtures)
+
+	logs := map[string][]types.LogFormat{}
+	for _, fixture := range fixtures {
+		b, err := ioutil.ReadFile(fixture)
+		require.NoError(t, err)
+		result := &types.GetTxSearchResponse{}
+		require.NoError(t, json.Unmarshal(b, result))
+
+		for _, txRaw := range result.Result.Txs {
+			tx := &auth.StdTx{}
+			_, err := cdc.UnmarshalBinaryLengthPrefixedReader(base64.NewDecoder(base64.StdEncoding, strings.NewReader(txRaw.TxData)), tx, 0)
+			require.NoError(t, err)
+
+			var txLog []types.LogFormat
+			if err := json.Unmarshal([]byte(txRaw.TxResult.Log), &txLog); err != nil {
+				continue // failed transaction
+			}
+			for _, lf := range txLog {
+				if i := int(lf.MsgIndex); i < len(tx.Msgs) {
+					logs[tx.Msgs[i].Type()] = append(logs[tx.Msgs[i].Type()], lf)
+				}
+			}
+		}
+	}
+	return logs
+}
+
+func mustAccAddress(t *testing.T, addr sdk.AccAddress) string {
+	s, err := AccAddress(addr)
+	require.NoError(t, err)
+	return s
+}
+
+// subsetEventStrings returns all string values of subevent that might hold addresses
+func subsetEventStrings(se structs.SubsetEvent) (out []string) {
+	for _, accounts := range se.Node {
+		for _, a := range accounts {
+			out = append(out, a.ID)
+		}
+	}
+	for _, transfers := range [][]structs.EventTransfer{se.Sender, se.Recipient} {
+		for _, tr := range transfers {
+			out = append(out, tr.Account.ID)
+		}
+	}
+	for _, transfers := range se.Transfers {
+		for _, tr := range transfers {
+			out = append(out, tr.Account.ID)
+		}
+	}
+	for _, values := range se.Additional {
+		out = append(out, values...)
+	}
+	for _, sub := range se.Sub {
+		out = append(out, subsetEventStrings(sub)...)
+	}
+	return out
+}
@@ -0,0 +1,172 @@
+package mapper
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/figment-networks/indexer-manager/structs"
+	"github.com/figment-networks/terra-worker/api/types"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/terra-project/core/x/bank"
+	"github.com/terra-project/core/x/market"
+)
+
+// Reasons of balance changes
+const (
+	ReasonFee        = "fee"
+	ReasonTax        = "tax"
+	ReasonTransfer   = "transfer"
+	ReasonReward     = "reward"
+	ReasonCommission = "commission"
+	ReasonMint       = "mint"
+	ReasonBurn       = "burn"
+)
+
+// BalanceChange is a signed change of account balance in single currency.
+// Amount is negative for debits
+type BalanceChange struct {
+	Account string
+	Amount  structs.TransactionAmount
+	Reason  string
+}
+
+// MsgBalanceChanges returns balance changes caused by message, taken from its log:
+// `transfer` events (debited from sender, credited to recipient), multisend inputs,
+// coins burned and minted by market swaps and cw20 token movements reported by contracts.
+// Transfers of reward and commission withdrawals have `reward` and `commission` reasons
+func MsgBalanceChanges(msg sdk.Msg, logf types.LogFormat) (changes []BalanceChange, err error) {
+	reason := ReasonTransfer
+	if msg != nil {
+		switch msg.Type() {
+		case "withdraw_delegator_reward":
+			reason = ReasonReward
+		case "withdraw_validator_commission":
+			reason = ReasonCommission
+		}
+	}
+
+	var withoutSender bool
+	for _, ev := range logf.Events {
+		if ev.Attributes == nil {
+			continue
+		}
+
+		switch ev.Type {
+		case "transfer":
+			ch, ws, err := transferBalanceChanges(ev.Attributes, reason)
+			if err != nil {
+				return nil, err
+			}
+			changes = append(changes, ch...)
+			withoutSender = withoutSender || ws
+		case "swap":
+			ch, err := swapBalanceChanges(ev.Attributes)
+			if err != nil {
+				return nil, err
+			}
+			changes = append(changes, ch...)
+		}
+	}
+
+	// transfer events of multisend carry only recipients, inputs are taken from the message
+	if multisend, ok := msg.(bank.MsgMultiSend); ok && withoutSender {
+		for _, in := range multisend.Inputs {
+			addr, err := AccAddress(in.Address)
+			if err != nil {
+				return nil, err
+			}
+			for _, c := range in.Coins {
+				changes = append(changes, BalanceChange{Account: addr, Amount: NegAmount(CoinAmount(c)), Reason: reason})
+			}
+		}
+	}
+
+	return append(changes, cw20BalanceChanges(logf)...), nil
+}
+
+// transferBalanceChanges reads (recipient, sender, amount) groups of `transfer` event,
+// returns true when any amount had no sender. Payouts of distribution module are rewards
+// unless the message withdraws commission
+func transferBalanceChanges(attr *types.TxEventsAttributes, reason string) (changes []BalanceChange, withoutSender bool, err error) {
+	for _, g := range attributeGroups(attr, "recipient") {
+		amounts, err := ParseCoins(g["amount"])
+		if err != nil {
+			return nil, false, fmt.Errorf("[TERRA-API] Error parsing transfer amount '%s': %w ", g["amount"], err)
+		}
+
+		r := reason
+		if g["sender"] == distributionModuleAddr && r == ReasonTransfer {
+			r = ReasonReward
+		}
+		for _, am := range amounts {
+			if g["sender"] == "" {
+				withoutSender = true
+			} else {
+				changes = append(changes, BalanceChange{Account: g["sender"], Amount: NegAmount(am), Reason: r})
+			}
+			changes = append(changes, BalanceChange{Account: g["recipient"], Amount: am, Reason: r})
+		}
+	}
+	return changes, withoutSender, nil
+}
+
+// swapBalanceChanges returns offer coins burned and swap coins minted by market module
+func swapBalanceChanges(attr *types.TxEventsAttributes) (changes []BalanceChange, err error) {
+	module := ModuleAddress(market.ModuleName)
+	for _, kr := range [][2]string{{"offer", ReasonBurn}, {"swap_coin", ReasonMint}} {
+		key, reason := kr[0], kr[1]
+		values := attr.Others[key]
+		if len(values) == 0 {
+			continue
+		}
+		am, err := ParseCoin(values[0])
+		if err != nil {
+			return nil, fmt.Errorf("[TERRA-API] Error parsing %s '%s': %w ", key, values[0], err)
+		}
+		if reason == ReasonBurn {
+			am = NegAmount(am)
+		}
+		changes = append(changes, BalanceChange{Account: module, Amount: am, Reason: reason})
+	}
+	return changes, nil
+}
+
+// cw20BalanceChanges returns token movements reported by contracts, with contract address as currency
+func cw20BalanceChanges(logf types.LogFormat) (changes []BalanceChange) {
+	for _, a := range cw20FromLog(logf) {
+		am, err := ParseAmount(a.Contract, a.Amount)
+		if err != nil {
+			continue
+		}
+
+		switch cw20TransferTypes[a.Action] {
+		case "send":
+			changes = append(changes,
+				BalanceChange{Account: a.From, Amount: NegAmount(am), Reason: ReasonTransfer},
+				BalanceChange{Account: a.To, Amount: am, Reason: ReasonTransfer},
+			)
+		case "mint":
+			changes = append(changes, BalanceChange{Account: a.To, Amount: am, Reason: ReasonMint})
+		case "burn":
+			changes = append(changes, BalanceChange{Account: a.From, Amount: NegAmount(am), Reason: ReasonBurn})
+		}
+	}
+	return changes
+}
+
+// NegAmount returns amount with opposite sign
+func NegAmount(am structs.TransactionAmount) structs.TransactionAmount {
+	if am.Numeric == nil {
+		return am
+	}
+	am.Numeric = new(big.Int).Neg(am.Numeric)
+	switch {
+	case strings.HasPrefix(am.Text, "-"):
+		am.Text = am.Text[1:]
+	case am.Numeric.Sign() != 0:
+		am.Text = "-" + am.Text
+	}
+	return am
+}
@@ -0,0 +1,118 @@
+package mapper
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/figment-networks/indexer-manager/structs"
+	"github.com/figment-networks/terra-worker/api/types"
+	"github.com/stretchr/testify/require"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/tendermint/tendermint/libs/bech32"
+	"github.com/terra-project/core/x/bank"
+	"github.com/terra-project/core/x/distribution"
+)
+
+func TestMsgBalanceChanges(t *testing.T) {
+	const (
+		alice = "terra190vqdjtlpcq27xslcveglfmr4ynfwg7gxlzhjn"
+		bob   = "terra1sxmr0k8u6trd5c6eu6trzyapzux7090yhcwdln"
+		token = "terra183rfa8tvtp6ax7jr7dfaf7ywv870sykx4zvgjs"
+	)
+	// decoded regardless of the global sdk config
+	_, aliceAddr, err := bech32.DecodeAndConvert(alice)
+	require.NoError(t, err)
+	_, bobAddr, err := bech32.DecodeAndConvert(bob)
+	require.NoError(t, err)
+	distributionModule := ModuleAddress(distribution.ModuleName)
+
+	amount := func(currency string, n int64) structs.TransactionAmount {
+		return structs.TransactionAmount{Text: big.NewInt(n).String(), Numeric: big.NewInt(n), Currency: currency}
+	}
+
+	tests := []struct {
+		name    string
+		msg     sdk.Msg
+		log     string
+		want    []BalanceChange
+		wantErr bool
+	}{
+		{
+			name: "send",
+			msg:  bank.NewMsgSend(sdk.AccAddress(aliceAddr), sdk.AccAddress(bobAddr), sdk.NewCoins(sdk.NewInt64Coin("uluna", 10))),
+			log: `{"events": [{"type": "transfer", "attributes": [
+				{"key": "recipient", "value": "` + bob + `"}, {"key": "sender", "value": "` + alice + `"}, {"key": "amount", "value": "10uluna,2uusd"}]}]}`,
+			want: []BalanceChange{
+				{Account: alice, Amount: amount("uluna", -10), Reason: ReasonTransfer},
+				{Account: bob, Amount: amount("uluna", 10), Reason: ReasonTransfer},
+				{Account: alice, Amount: amount("uusd", -2), Reason: ReasonTransfer},
+				{Account: bob, Amount: amount("uusd", 2), Reason: ReasonTransfer},
+			},
+		},
+		{
+			name: "multisend inputs from message",
+			msg: bank.NewMsgMultiSend(
+				[]bank.Input{bank.NewInput(sdk.AccAddress(aliceAddr), sdk.NewCoins(sdk.NewInt64Coin("uluna", 30)))},
+				[]bank.Output{bank.NewOutput(sdk.AccAddress(bobAddr), sdk.NewCoins(sdk.NewInt64Coin("uluna", 10))), bank.NewOutput(sdk.AccAddress(aliceAddr), sdk.NewCoins(sdk.NewInt64Coin("uluna", 20)))},
+			),
+			log: `{"events": [{"type": "transfer", "attributes": [
+				{"key": "recipient", "value": "` + bob + `"}, {"key": "amount", "value": "10uluna"},
+				{"key": "recipient", "value": "` + alice + `"}, {"key": "amount", "value": "20uluna"}]}]}`,
+			want: []BalanceChange{
+				{Account: bob, Amount: amount("uluna", 10), Reason: ReasonTransfer},
+				{Account: alice, Amount: amount("uluna", 20), Reason: ReasonTransfer},
+				{Account: alice, Amount: amount("uluna", -30), Reason: ReasonTransfer},
+			},
+		},
+		{
+			name: "reward withdrawal",
+			msg:  distribution.NewMsgWithdrawDelegatorReward(sdk.AccAddress(aliceAddr), sdk.ValAddress(bobAddr)),
+			log: `{"events": [{"type": "transfer", "attributes": [
+				{"key": "recipient", "value": "` + alice + `"}, {"key": "sender", "value": "` + distributionModule + `"}, {"key": "amount", "value": "5uluna"}]}]}`,
+			want: []BalanceChange{
+				{Account: distributionModule, Amount: amount("uluna", -5), Reason: ReasonReward},
+				{Account: alice, Amount: amount("uluna", 5), Reason: ReasonReward},
+			},
+		},
+		{
+			name: "cw20 mint and burn",
+			log: `{"events": [{"type": "from_contract", "attributes": [
+				{"key": "contract_address", "value": "` + token + `"}, {"key": "action", "value": "mint"}, {"key": "to", "value": "` + bob + `"}, {"key": "amount", "value": "100"},
+				{"key": "contract_address", "value": "` + token + `"}, {"key": "action", "value": "burn"}, {"key": "from", "value": "` + alice + `"}, {"key": "amount", "value": "40"}]}]}`,
+			want: []BalanceChange{
+				{Account: bob, Amount: amount(token, 100), Reason: ReasonMint},
+				{Account: alice, Amount: amount(token, -40), Reason: ReasonBurn},
+			},
+		},
+		{
+			name:    "invalid amount",
+			log:     `{"events": [{"type": "transfer", "attributes": [{"key": "recipient", "value": "` + bob + `"}, {"key": "amount", "value": "uluna10"}]}]}`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logf := types.LogFormat{}
+			require.NoError(t, json.Unmarshal([]byte(tt.log), &logf))
+
+			got, err := MsgBalanceChanges(tt.msg, logf)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestNegAmount(t *testing.T) {
+	require.Equal(t, structs.TransactionAmount{Text: "-1.50", Numeric: big.NewInt(-150), Exp: 2, Currency: "uluna"},
+		NegAmount(structs.TransactionAmount{Text: "1.50", Numeric: big.NewInt(150), Exp: 2, Currency: "uluna"}))
+	require.Equal(t, structs.TransactionAmount{Text: "7", Numeric: big.NewInt(7)}, NegAmount(structs.TransactionAmount{Text: "-7", Numeric: big.NewInt(-7)}))
+	require.Equal(t, structs.TransactionAmount{Text: "0", Numeric: big.NewInt(0)}, NegAmount(structs.TransactionAmount{Text: "0", Numeric: big.NewInt(0)}))
+	require.Equal(t, structs.TransactionAmount{Currency: "uluna"}, NegAmount(structs.TransactionAmount{Currency: "uluna"}))
+}
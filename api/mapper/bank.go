@@ -3,18 +3,13 @@ package mapper
 import (
 	"errors"
 	"fmt"
-	"math/big"
-	"regexp"
 	"strings"
 
 	"github.com/figment-networks/indexer-manager/structs"
 	"github.com/figment-networks/terra-worker/api/types"
 
 	sdk "github.com/cosmos/cosmos-sdk/types"
-	"github.com/terra-project/core/types/util"
 	"github.com/terra-project/core/x/bank"
-
-	"github.com/tendermint/tendermint/libs/bech32"
 )
 
 func BankMultisendToSub(msg sdk.Msg, logf types.LogFormat) (se structs.SubsetEvent, err error) {
@@ -71,21 +66,14 @@ func BankSendToSub(msg sdk.Msg, logf types.LogFormat) (se structs.SubsetEvent, e
 func bankProduceEvTx(account sdk.AccAddress, coins sdk.Coins) (evt structs.EventTransfer, err error) {
 	bech32Addr := ""
 	if !account.Empty() {
-		bech32Addr, err = bech32.ConvertAndEncode(util.Bech32PrefixAccAddr, account.Bytes())
+		bech32Addr, err = AccAddress(account)
 	}
 
 	evt = structs.EventTransfer{
 		Account: structs.Account{ID: bech32Addr},
 	}
 	if len(coins) > 0 {
-		evt.Amounts = []structs.TransactionAmount{}
-		for _, coin := range coins {
-			evt.Amounts = append(evt.Amounts, structs.TransactionAmount{
-				Currency: coin.Denom,
-				Numeric:  coin.Amount.BigInt(),
-				Text:     coin.Amount.String(),
-			})
-		}
+		evt.Amounts = CoinsAmounts(coins)
 	}
 
 	return evt, nil
@@ -101,34 +89,21 @@ func produceTransfers(se *structs.SubsetEvent, transferType, skipAddr string, lo
 		attr := ev.Attributes
 
 		for i, recip := range attr.Recipient {
-			if recip == skipAddr || len(attr.Amount) < i {
+			if recip == skipAddr || len(attr.Amount) <= i {
 				continue
 			}
 			amts := []structs.TransactionAmount{}
 
 			for _, amt := range strings.Split(attr.Amount[i], ",") { // (pacmessica): split amount because it may contain multiple amounts, eg. from logs `"value": "2896ukrw,16uluna,1umnt"`
-				attrAmt := structs.TransactionAmount{Numeric: &big.Int{}}
-
-				sliced := getCurrency(amt)
-				var (
-					c       *big.Int
-					exp     int32
-					coinErr error
-				)
-				if len(sliced) == 3 {
-					attrAmt.Currency = sliced[2]
-					c, exp, coinErr = getCoin(sliced[1])
-				} else {
-					c, exp, coinErr = getCoin(amt)
+				attrAmt, coinErr := ParseCoin(amt)
+				if coinErr != nil {
+					// amounts without denomination are taken as plain numbers
+					attrAmt, coinErr = ParseAmount("", amt)
 				}
 				if coinErr != nil {
 					return fmt.Errorf("[TERRA-API] Error parsing amount '%s': %s ", amt, coinErr)
 				}
 
-				attrAmt.Text = amt
-				attrAmt.Exp = exp
-				attrAmt.Numeric.Set(c)
-
 				amts = append(amts, attrAmt)
 			}
 			evts = append(evts, structs.EventTransfer{
@@ -142,7 +117,7 @@ func produceTransfers(se *structs.SubsetEvent, transferType, skipAddr string, lo
 		return
 	}
 
-	if se.Transfers[transferType] == nil {
+	if se.Transfers == nil {
 		se.Transfers = make(map[string][]structs.EventTransfer)
 	}
 	se.Transfers[transferType] = evts
@@ -150,24 +125,19 @@ func produceTransfers(se *structs.SubsetEvent, transferType, skipAddr string, lo
 	return
 }
 
-func getCoin(s string) (number *big.Int, exp int32, err error) {
-	s = strings.Replace(s, ",", ".", -1)
-	strs := strings.Split(s, `.`)
-	number = &big.Int{}
-	if len(strs) == 1 {
-		number.SetString(strs[0], 10)
-		return number, 0, nil
+// attributeGroups splits attributes of event into groups starting with the given key,
+// eg. (recipient, sender, amount) groups of `transfer` event. Attributes before the first key are skipped
+func attributeGroups(attr *types.TxEventsAttributes, first string) (groups []map[string]string) {
+	if attr == nil {
+		return nil
 	}
-	if len(strs) == 2 {
-		number.SetString(strs[0]+strs[1], 10)
-		return number, int32(len(strs[1])), nil
+	for _, kv := range attr.Ordered {
+		if kv.Key == first {
+			groups = append(groups, map[string]string{})
+		}
+		if len(groups) > 0 {
+			groups[len(groups)-1][kv.Key] = kv.Value
+		}
 	}
-
-	return number, 0, errors.New("Impossible to parse ")
-}
-
-var curencyRegex = regexp.MustCompile("([0-9\\.\\,\\-]+)[\\s]*([^0-9\\s]+)$")
-
-func getCurrency(in string) []string {
-	return curencyRegex.FindStringSubmatch(in)
+	return groups
 }
@@ -0,0 +1,190 @@
+package mapper
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"regexp"
+	"strings"
+
+	"github.com/figment-networks/indexer-manager/structs"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// denomRegex is the denomination format of newer cosmos-sdk versions.
+// It accepts terra natives (`uluna`), ibc (`ibc/27394FB0...`) and contract addresses used as currency
+var denomRegex = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9/:._-]{2,127}$`)
+
+// ParseDecimal parses decimal number as printed by sdk.Int and sdk.Dec (eg. `12`, `-0.5`, `44.550000000000000000`)
+// into integer and number of decimal places, so `44.55` becomes 4455 and 2.
+// Trailing zeros of the fraction are kept, so FormatDecimal restores the exact input
+func ParseDecimal(s string) (number *big.Int, exp int32, err error) {
+	digits := s
+	if strings.HasPrefix(digits, "-") {
+		digits = digits[1:]
+	}
+
+	intPart, fracPart := digits, ""
+	if i := strings.IndexByte(digits, '.'); i >= 0 {
+		intPart, fracPart = digits[:i], digits[i+1:]
+		if fracPart == "" {
+			return nil, 0, fmt.Errorf("invalid decimal %q: empty fraction", s)
+		}
+	}
+
+	if intPart == "" {
+		return nil, 0, fmt.Errorf("invalid decimal %q: empty integer part", s)
+	}
+	if !isDigits(intPart) || !isDigits(fracPart) {
+		return nil, 0, fmt.Errorf("invalid decimal %q", s)
+	}
+	if len(fracPart) > math.MaxInt32 {
+		return nil, 0, fmt.Errorf("invalid decimal %q: too many decimal places", s)
+	}
+
+	number, ok := new(big.Int).SetString(intPart+fracPart, 10)
+	if !ok {
+		return nil, 0, fmt.Errorf("invalid decimal %q", s)
+	}
+	if s[0] == '-' {
+		number.Neg(number)
+	}
+	return number, int32(len(fracPart)), nil
+}
+
+// FormatDecimal formats number with exp decimal places, reverse of ParseDecimal
+func FormatDecimal(number *big.Int, exp int32) string {
+	if number == nil {
+		number = &big.Int{}
+	}
+
+	digits := new(big.Int).Abs(number).String()
+	if exp > 0 {
+		if pad := int(exp) + 1 - len(digits); pad > 0 {
+			digits = strings.Repeat("0", pad) + digits
+		}
+		digits = digits[:len(digits)-int(exp)] + "." + digits[len(digits)-int(exp):]
+	}
+
+	if number.Sign() < 0 {
+		return "-" + digits
+	}
+	return digits
+}
+
+// ParseCoin parses single coin as printed by sdk.Coin and sdk.DecCoin (eg. `2896ukrw`, `1.500000000000000000uluna`)
+func ParseCoin(s string) (am structs.TransactionAmount, err error) {
+	i := strings.IndexFunc(s, func(r rune) bool {
+		return (r < '0' || r > '9') && r != '.' && r != '-'
+	})
+	if i <= 0 {
+		return am, fmt.Errorf("invalid coin %q", s)
+	}
+
+	denom := strings.TrimLeft(s[i:], " ")
+	if !denomRegex.MatchString(denom) {
+		return am, fmt.Errorf("invalid coin %q: wrong denomination %q", s, denom)
+	}
+
+	am, err = ParseAmount(denom, s[:i])
+	if err != nil {
+		return am, fmt.Errorf("invalid coin %q: %w", s, err)
+	}
+	if am.Numeric.Sign() < 0 {
+		return am, fmt.Errorf("invalid coin %q: negative amount", s)
+	}
+	return am, nil
+}
+
+// ParseCoins parses comma separated list of coins as printed by sdk.Coins and sdk.DecCoins (eg. `2896ukrw,16uluna`)
+func ParseCoins(s string) (amounts []structs.TransactionAmount, err error) {
+	if s == "" {
+		return nil, nil
+	}
+
+	for _, c := range strings.Split(s, ",") {
+		am, err := ParseCoin(c)
+		if err != nil {
+			return nil, err
+		}
+		amounts = append(amounts, am)
+	}
+	return amounts, nil
+}
+
+// Amounts of every mapper and lcd call are built by the functions below, so that all of them
+// keep the same form: Text is the decimal number (without currency), Numeric is Text without
+// the decimal point and Exp is the number of decimal places, so Numeric / 10^Exp == Text.
+
+// ParseAmount converts decimal string (log attribute, lcd response) into amount of given currency.
+// Exp is the number of decimal places of the string
+func ParseAmount(currency, s string) (am structs.TransactionAmount, err error) {
+	am.Numeric, am.Exp, err = ParseDecimal(s)
+	if err != nil {
+		return structs.TransactionAmount{}, err
+	}
+	am.Text = s
+	am.Currency = currency
+	return am, nil
+}
+
+// IntAmount converts sdk.Int into amount of given currency, with Exp 0.
+// Nil integer gives amount with currency only
+func IntAmount(currency string, i sdk.Int) structs.TransactionAmount {
+	am := structs.TransactionAmount{Currency: currency}
+	if i.IsNil() {
+		return am
+	}
+	am.Text = i.String()
+	am.Numeric = i.BigInt()
+	return am
+}
+
+// DecAmount converts sdk.Dec into amount of given currency, with Exp sdk.Precision.
+// Nil decimal gives amount with currency only
+func DecAmount(currency string, d sdk.Dec) structs.TransactionAmount {
+	am := structs.TransactionAmount{Currency: currency}
+	if d.IsNil() {
+		return am
+	}
+	am.Text = d.String()
+	am.Numeric = d.BigInt()
+	am.Exp = sdk.Precision
+	return am
+}
+
+// CoinAmount converts sdk.Coin into amount
+func CoinAmount(c sdk.Coin) structs.TransactionAmount {
+	return IntAmount(c.Denom, c.Amount)
+}
+
+// DecCoinAmount converts sdk.DecCoin into amount
+func DecCoinAmount(c sdk.DecCoin) structs.TransactionAmount {
+	return DecAmount(c.Denom, c.Amount)
+}
+
+// CoinsAmounts converts sdk.Coins into amounts, nil for no coins
+func CoinsAmounts(coins sdk.Coins) (amounts []structs.TransactionAmount) {
+	for _, c := range coins {
+		amounts = append(amounts, CoinAmount(c))
+	}
+	return amounts
+}
+
+// DecCoinsAmounts converts sdk.DecCoins into amounts, nil for no coins
+func DecCoinsAmounts(coins sdk.DecCoins) (amounts []structs.TransactionAmount) {
+	for _, c := range coins {
+		amounts = append(amounts, DecCoinAmount(c))
+	}
+	return amounts
+}
+
+func isDigits(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,371 @@
+package mapper
+
+import (
+	"math/big"
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/figment-networks/indexer-manager/structs"
+	"github.com/stretchr/testify/require"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+func TestParseDecimal(t *testing.T) {
+	tests := []struct {
+		in      string
+		number  string
+		exp     int32
+		wantErr bool
+	}{
+		{in: "0", number: "0"},
+		{in: "12", number: "12"},
+		{in: "44.55", number: "4455", exp: 2},
+		{in: "1.05", number: "105", exp: 2},
+		{in: "-1.5", number: "-15", exp: 1},
+		{in: "-0.500000000000000000", number: "-500000000000000000", exp: 18},
+		{in: "1000000.000000000000000000", number: "1000000000000000000000000", exp: 18},
+		{in: "", wantErr: true},
+		{in: "-", wantErr: true},
+		{in: ".5", wantErr: true},
+		{in: "5.", wantErr: true},
+		{in: "1.2.3", wantErr: true},
+		{in: "1,5", wantErr: true},
+		{in: "+1", wantErr: true},
+		{in: "1.-5", wantErr: true},
+		{in: "--1", wantErr: true},
+		{in: " 1", wantErr: true},
+		{in: "1e5", wantErr: true},
+		{in: "0x10", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			number, exp, err := ParseDecimal(tt.in)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.number, number.String())
+			require.Equal(t, tt.exp, exp)
+			require.Equal(t, tt.in, FormatDecimal(number, exp))
+		})
+	}
+}
+
+func TestParseCoins(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    []structs.TransactionAmount
+		wantErr bool
+	}{
+		{in: ""},
+		{
+			in: "2896ukrw,16uluna,1umnt",
+			want: []structs.TransactionAmount{
+				{Text: "2896", Numeric: big.NewInt(2896), Currency: "ukrw"},
+				{Text: "16", Numeric: big.NewInt(16), Currency: "uluna"},
+				{Text: "1", Numeric: big.NewInt(1), Currency: "umnt"},
+			},
+		},
+		{
+			in:   "1.500000000000000000uluna",
+			want: []structs.TransactionAmount{{Text: "1.500000000000000000", Numeric: big.NewInt(1500000000000000000), Exp: 18, Currency: "uluna"}},
+		},
+		{
+			in:   "5 uluna",
+			want: []structs.TransactionAmount{{Text: "5", Numeric: big.NewInt(5), Currency: "uluna"}},
+		},
+		{
+			in:   "100ibc/27394FB092D2ECCD56123C74F36E4C1F926001CEADA9CA97EA622B25F41E5EB2",
+			want: []structs.TransactionAmount{{Text: "100", Numeric: big.NewInt(100), Currency: "ibc/27394FB092D2ECCD56123C74F36E4C1F926001CEADA9CA97EA622B25F41E5EB2"}},
+		},
+		{
+			in:   "7terra1ejpjr43ht3y56pplm5pxpusmcrk9rkkv09x0fz",
+			want: []structs.TransactionAmount{{Text: "7", Numeric: big.NewInt(7), Currency: "terra1ejpjr43ht3y56pplm5pxpusmcrk9rkkv09x0fz"}},
+		},
+		{in: "5", wantErr: true},
+		{in: "uluna", wantErr: true},
+		{in: "5u", wantErr: true},
+		{in: "-5uluna", wantErr: true},
+		{in: "1,5uluna", wantErr: true},
+		{in: "5uluna,", wantErr: true},
+		{in: ",5uluna", wantErr: true},
+		{in: "1.5.5uluna", wantErr: true},
+		{in: "5uluna ", wantErr: true},
+		{in: "5ulu na", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := ParseCoins(tt.in)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+// TestParseDecimalRoundTrip checks that strings of random sdk.Int and sdk.Dec values are parsed exactly
+func TestParseDecimalRoundTrip(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+
+	for i := 0; i < 10000; i++ {
+		n := randomBigInt(r)
+
+		in := sdk.NewIntFromBigInt(n)
+		number, exp, err := ParseDecimal(in.String())
+		require.NoError(t, err)
+		require.Zero(t, exp)
+		require.Zero(t, n.Cmp(number), "int %s parsed as %s", in, number)
+		require.Equal(t, in.String(), FormatDecimal(number, exp))
+
+		dec := sdk.NewDecFromBigIntWithPrec(n, sdk.Precision)
+		number, exp, err = ParseDecimal(dec.String())
+		require.NoError(t, err)
+		require.Equal(t, int32(sdk.Precision), exp)
+		require.Zero(t, dec.BigInt().Cmp(number), "dec %s parsed as %s", dec, number)
+		require.Equal(t, dec.String(), FormatDecimal(number, exp))
+	}
+}
+
+// TestParseCoinsRoundTrip checks that strings of random sdk.Coins and sdk.DecCoins are parsed exactly
+func TestParseCoinsRoundTrip(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+
+	for i := 0; i < 2000; i++ {
+		var coins sdk.Coins
+		var decCoins sdk.DecCoins
+		for _, denom := range randomDenoms(r) {
+			n := new(big.Int).Abs(randomBigInt(r))
+			n.Add(n, big.NewInt(1))
+			coins = append(coins, sdk.NewCoin(denom, sdk.NewIntFromBigInt(n)))
+			decCoins = append(decCoins, sdk.NewDecCoinFromDec(denom, sdk.NewDecFromBigIntWithPrec(n, sdk.Precision)))
+		}
+		coins = coins.Sort()
+		decCoins = decCoins.Sort()
+
+		amounts, err := ParseCoins(coins.String())
+		require.NoError(t, err)
+		require.Len(t, amounts, len(coins))
+		for j, am := range amounts {
+			require.Equal(t, coins[j].Denom, am.Currency)
+			require.Zero(t, coins[j].Amount.BigInt().Cmp(am.Numeric))
+			require.Zero(t, am.Exp)
+		}
+		require.Equal(t, coins.String(), formatCoins(amounts))
+
+		amounts, err = ParseCoins(decCoins.String())
+		require.NoError(t, err)
+		require.Len(t, amounts, len(decCoins))
+		for j, am := range amounts {
+			require.Equal(t, decCoins[j].Denom, am.Currency)
+			require.Zero(t, decCoins[j].Amount.BigInt().Cmp(am.Numeric))
+			require.Equal(t, int32(sdk.Precision), am.Exp)
+		}
+		require.Equal(t, decCoins.String(), formatCoins(amounts))
+	}
+}
+
+// TestParseDecimalReformat checks that formatted parsed decimals parse back to the same value, in sdk.Dec as well,
+// and inputs other than leading zeros and negative zero are formatted unchanged
+func TestParseDecimalReformat(t *testing.T) {
+	tests := []struct {
+		in        string
+		formatted string
+		wantErr   bool
+	}{
+		{in: "0", formatted: "0"},
+		{in: "12", formatted: "12"},
+		{in: "44.55", formatted: "44.55"},
+		{in: "-0.500000000000000000", formatted: "-0.500000000000000000"},
+		{in: "1000000.000000000000000000", formatted: "1000000.000000000000000000"},
+		{in: "0.000000000000000001", formatted: "0.000000000000000001"},
+		{in: "123456789012345678901234567890.123456789012345678", formatted: "123456789012345678901234567890.123456789012345678"},
+		{in: "1.0000000000000000000000001", formatted: "1.0000000000000000000000001"},
+		{in: "007", formatted: "7"},
+		{in: "-007.50", formatted: "-7.50"},
+		{in: "00.5", formatted: "0.5"},
+		{in: "-0", formatted: "0"},
+		{in: "-0.000", formatted: "0.000"},
+		{in: "1.2.3", wantErr: true},
+		{in: ".5", wantErr: true},
+		{in: "1,5", wantErr: true},
+		{in: "-.5", wantErr: true},
+		{in: "٣", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			number, exp, err := ParseDecimal(tt.in)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			formatted := FormatDecimal(number, exp)
+			require.Equal(t, tt.formatted, formatted)
+			again, againExp, err := ParseDecimal(formatted)
+			require.NoError(t, err)
+			require.Zero(t, again.Cmp(number), "%q parsed as %s, its format %q as %s", tt.in, number, formatted, again)
+			require.Equal(t, exp, againExp)
+
+			if exp <= sdk.Precision {
+				dec, err := sdk.NewDecFromStr(tt.in)
+				require.NoError(t, err)
+				scaled := new(big.Int).Mul(number, new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(sdk.Precision-exp)), nil))
+				require.Zero(t, dec.BigInt().Cmp(scaled), "%q parsed as %s,%d, sdk parsed %s", tt.in, number, exp, dec)
+			}
+		})
+	}
+}
+
+// TestParseCoinsReformat checks that parsed coins have non-negative amounts and valid denominations,
+// and their format parses back to the same coins
+func TestParseCoinsReformat(t *testing.T) {
+	tests := []struct {
+		in        string
+		formatted string
+		wantErr   bool
+	}{
+		{in: "2896ukrw,16uluna,1umnt", formatted: "2896ukrw,16uluna,1umnt"},
+		{in: "1.500000000000000000uluna", formatted: "1.500000000000000000uluna"},
+		{in: "5 uluna", formatted: "5uluna"},
+		{in: "007uluna", formatted: "7uluna"},
+		{in: "0uluna", formatted: "0uluna"},
+		{in: "100ibc/27394FB092D2ECCD", formatted: "100ibc/27394FB092D2ECCD"},
+		{in: "1uluna,1uluna", formatted: "1uluna,1uluna"},
+		{in: "5", wantErr: true},
+		{in: "-5uluna", wantErr: true},
+		{in: "5uluna,", wantErr: true},
+		{in: "1,5uluna", wantErr: true},
+		{in: "5uluna,-1ukrw", wantErr: true},
+		{in: "5uluna;1ukrw", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			amounts, err := ParseCoins(tt.in)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			for _, am := range amounts {
+				require.GreaterOrEqual(t, am.Numeric.Sign(), 0)
+				require.Regexp(t, denomRegex, am.Currency)
+			}
+
+			formatted := formatCoins(amounts)
+			require.Equal(t, tt.formatted, formatted)
+			again, err := ParseCoins(formatted)
+			require.NoError(t, err)
+			require.Len(t, again, len(amounts))
+			for i := range amounts {
+				require.Equal(t, amounts[i].Currency, again[i].Currency)
+				require.Zero(t, amounts[i].Numeric.Cmp(again[i].Numeric))
+				require.Equal(t, amounts[i].Exp, again[i].Exp)
+			}
+		})
+	}
+}
+
+// TestAmounts checks that conversions of random sdk.Int, sdk.Dec and decimal strings hold Numeric / 10^Exp == Text
+func TestAmounts(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+
+	for i := 0; i < 2000; i++ {
+		n := randomBigInt(r)
+		abs := new(big.Int).Abs(n)
+		in := sdk.NewIntFromBigInt(n)
+		dec := sdk.NewDecFromBigIntWithPrec(n, sdk.Precision)
+
+		am := IntAmount("uluna", in)
+		requireAmountMatchesText(t, am)
+		require.Equal(t, in.String(), am.Text)
+		require.Zero(t, am.Exp)
+
+		am = DecAmount("uluna", dec)
+		requireAmountMatchesText(t, am)
+		require.Equal(t, dec.String(), am.Text)
+		require.Equal(t, int32(sdk.Precision), am.Exp)
+
+		am = CoinAmount(sdk.NewCoin("uusd", sdk.NewIntFromBigInt(abs)))
+		requireAmountMatchesText(t, am)
+		require.Equal(t, "uusd", am.Currency)
+
+		am = DecCoinAmount(sdk.NewDecCoinFromDec("uusd", sdk.NewDecFromBigIntWithPrec(abs, sdk.Precision)))
+		requireAmountMatchesText(t, am)
+		require.Equal(t, "uusd", am.Currency)
+
+		// log values keep their own number of decimal places
+		text := FormatDecimal(n, int32(r.Intn(25)))
+		am, err := ParseAmount("ukrw", text)
+		require.NoError(t, err)
+		requireAmountMatchesText(t, am)
+		require.Equal(t, text, am.Text)
+	}
+
+	require.Equal(t, structs.TransactionAmount{Currency: "uluna"}, IntAmount("uluna", sdk.Int{}))
+	require.Equal(t, structs.TransactionAmount{Currency: "uluna"}, DecAmount("uluna", sdk.Dec{}))
+	require.Nil(t, CoinsAmounts(sdk.Coins{}))
+	require.Nil(t, DecCoinsAmounts(nil))
+
+	_, err := ParseAmount("uluna", "5uluna")
+	require.Error(t, err)
+}
+
+// requireAmountMatchesText checks that amount holds Numeric / 10^Exp == Text
+func requireAmountMatchesText(t *testing.T, am structs.TransactionAmount) {
+	t.Helper()
+
+	text, ok := new(big.Rat).SetString(am.Text)
+	require.True(t, ok, "amount text %q is not a number", am.Text)
+	numeric := new(big.Rat).SetFrac(am.Numeric, new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(am.Exp)), nil))
+	require.Zero(t, text.Cmp(numeric), "amount %s has numeric %s and exp %d", am.Text, am.Numeric, am.Exp)
+}
+
+func formatCoins(amounts []structs.TransactionAmount) string {
+	coins := make([]string, 0, len(amounts))
+	for _, am := range amounts {
+		coins = append(coins, FormatDecimal(am.Numeric, am.Exp)+am.Currency)
+	}
+	return strings.Join(coins, ",")
+}
+
+// randomBigInt returns integer of up to 60 digits, with small numbers being more likely
+func randomBigInt(r *rand.Rand) *big.Int {
+	n := new(big.Int).Rand(r, new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(r.Intn(60)+1)), nil))
+	if r.Intn(2) == 0 {
+		n.Neg(n)
+	}
+	return n
+}
+
+// randomDenoms returns unique denominations of terra natives and random denoms valid in sdk
+func randomDenoms(r *rand.Rand) (denoms []string) {
+	const alphabet = "abcdefghijklmnopqrstuvwxyz0123456789"
+	known := map[string]bool{}
+	for i := r.Intn(5); i >= 0; i-- {
+		denom := []string{"uluna", "ukrw", "uusd", "usdr", "umnt"}[r.Intn(5)]
+		if r.Intn(2) == 0 {
+			b := []byte{alphabet[r.Intn(26)]}
+			for j := r.Intn(13) + 1; j >= 0; j-- {
+				b = append(b, alphabet[r.Intn(len(alphabet))])
+			}
+			denom = string(b)
+		}
+		if !known[denom] {
+			known[denom] = true
+			denoms = append(denoms, denom)
+		}
+	}
+	return denoms
+}
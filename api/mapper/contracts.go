@@ -0,0 +1,447 @@
+package mapper
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/figment-networks/indexer-manager/structs"
+	"github.com/figment-networks/terra-worker/api/types"
+)
+
+// ContractAction is a set of attributes of single action emitted by contract in `from_contract` event
+type ContractAction struct {
+	Contract   string
+	Attributes []types.TxTags
+}
+
+// Get returns first value of attribute
+func (ca ContractAction) Get(key string) string {
+	for _, kv := range ca.Attributes {
+		if kv.Key == key {
+			return kv.Value
+		}
+	}
+	return ""
+}
+
+// ContractDecoder turns execute message and actions reported by contract into typed subevents
+type ContractDecoder interface {
+	Decode(contract string, execMsg []byte, actions []ContractAction) ([]structs.SubsetEvent, error)
+}
+
+// ContractsConfig is a list of known contracts per chain id
+//
+//	{
+//	  "columbus-4": {
+//	    "contracts": [{"address": "terra1...", "decoder": "terraswap_pair"}],
+//	    "code_ids":  [{"code_id": 4, "decoder": "terraswap_pair"}]
+//	  }
+//	}
+type ContractsConfig map[string]ChainContracts
+
+// ChainContracts known contracts of single chain
+type ChainContracts struct {
+	Contracts []struct {
+		Address string `json:"address"`
+		Decoder string `json:"decoder"`
+	} `json:"contracts"`
+	CodeIDs []struct {
+		CodeID  uint64 `json:"code_id"`
+		Decoder string `json:"decoder"`
+	} `json:"code_ids"`
+}
+
+// CodeIDSource provides current code ids of contracts. Code id of contract changes only with migration,
+// so sources keep code ids by address and learn new ones from instantiate and migrate logs
+type CodeIDSource interface {
+	ContractCodeID(address string) (uint64, error)
+	SetContractCodeID(address string, codeID uint64)
+}
+
+// ContractLookupError is returned with undecoded subevent when contract can't be matched, because its code id lookup failed
+type ContractLookupError struct {
+	Contract string
+	Err      error
+}
+
+func (e *ContractLookupError) Error() string {
+	return fmt.Sprintf("error getting code id of contract %s: %s", e.Contract, e.Err)
+}
+
+func (e *ContractLookupError) Unwrap() error {
+	return e.Err
+}
+
+// ContractRegistry keeps decoders of well known contracts.
+// Contracts are matched by address first, then by their current code id (when CodeIDSource is set)
+type ContractRegistry struct {
+	lock sync.RWMutex
+
+	decoders  map[string]ContractDecoder
+	byAddress map[string]ContractDecoder
+	byCodeID  map[uint64]ContractDecoder
+	codeIDs   CodeIDSource
+}
+
+// NewContractRegistry is ContractRegistry constructor, with built-in decoders registered
+func NewContractRegistry() *ContractRegistry {
+	cr := &ContractRegistry{
+		decoders:  map[string]ContractDecoder{},
+		byAddress: map[string]ContractDecoder{},
+		byCodeID:  map[uint64]ContractDecoder{},
+	}
+
+	for name, d := range builtinDecoders {
+		cr.decoders[name] = d
+	}
+	return cr
+}
+
+// RegisterDecoder adds named decoder that can be referenced in config
+func (cr *ContractRegistry) RegisterDecoder(name string, d ContractDecoder) {
+	cr.lock.Lock()
+	defer cr.lock.Unlock()
+	cr.decoders[name] = d
+}
+
+// AddContract assigns named decoder to contract address
+func (cr *ContractRegistry) AddContract(address, decoder string) error {
+	cr.lock.Lock()
+	defer cr.lock.Unlock()
+
+	d, ok := cr.decoders[decoder]
+	if !ok {
+		return fmt.Errorf("unknown decoder %s", decoder)
+	}
+	cr.byAddress[address] = d
+	return nil
+}
+
+// AddCodeID assigns named decoder to all contracts instantiated from (or migrated to) given code
+func (cr *ContractRegistry) AddCodeID(codeID uint64, decoder string) error {
+	cr.lock.Lock()
+	defer cr.lock.Unlock()
+
+	d, ok := cr.decoders[decoder]
+	if !ok {
+		return fmt.Errorf("unknown decoder %s", decoder)
+	}
+	cr.byCodeID[codeID] = d
+	return nil
+}
+
+// SetCodeIDSource sets source of contract code ids, without it contracts are matched only by address
+func (cr *ContractRegistry) SetCodeIDSource(src CodeIDSource) {
+	cr.lock.Lock()
+	defer cr.lock.Unlock()
+	cr.codeIDs = src
+}
+
+// LoadConfig loads contracts of given chain from json config
+func (cr *ContractRegistry) LoadConfig(r io.Reader, chainID string) error {
+	cfg := ContractsConfig{}
+	if err := json.NewDecoder(r).Decode(&cfg); err != nil {
+		return fmt.Errorf("error decoding contracts config: %w", err)
+	}
+
+	chain, ok := cfg[chainID]
+	if !ok {
+		return nil
+	}
+
+	for _, c := range chain.Contracts {
+		if err := cr.AddContract(c.Address, c.Decoder); err != nil {
+			return err
+		}
+	}
+	for _, c := range chain.CodeIDs {
+		if err := cr.AddCodeID(c.CodeID, c.Decoder); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RecordCodeIDs passes code ids of contracts instantiated or migrated in log to CodeIDSource,
+// so code ids of contracts seen in processed transactions are not looked up
+func (cr *ContractRegistry) RecordCodeIDs(logf types.LogFormat) {
+	if cr == nil {
+		return
+	}
+	cr.lock.RLock()
+	src := cr.codeIDs
+	cr.lock.RUnlock()
+	if src == nil {
+		return
+	}
+
+	for _, ev := range logf.Events {
+		if (ev.Type != "instantiate_contract" && ev.Type != "migrate_contract") || ev.Attributes == nil {
+			continue
+		}
+		for _, g := range attributeGroups(ev.Attributes, "code_id") {
+			codeID, err := strconv.ParseUint(g["code_id"], 10, 64)
+			if err != nil || g["contract_address"] == "" {
+				continue
+			}
+			src.SetContractCodeID(g["contract_address"], codeID)
+		}
+	}
+}
+
+// Decode decodes call of contract using matching decoder,
+// returns nil when contract is unknown. Nil registry knows no contracts
+func (cr *ContractRegistry) Decode(contract string, execMsg []byte, logf types.LogFormat) ([]structs.SubsetEvent, error) {
+	d, ok, err := cr.decoder(contract)
+	if err != nil || !ok {
+		return nil, err
+	}
+
+	var actions []ContractAction
+	for _, ca := range contractActions(logf) {
+		if ca.Contract == contract {
+			actions = append(actions, ca)
+		}
+	}
+
+	return d.Decode(contract, execMsg, actions)
+}
+
+// IsCW20 reports whether contract is registered as cw20 token (with `cw20` decoder)
+func (cr *ContractRegistry) IsCW20(contract string) (bool, error) {
+	d, ok, err := cr.decoder(contract)
+	if err != nil || !ok {
+		return false, err
+	}
+	_, ok = d.(CW20Decoder)
+	return ok, nil
+}
+
+// decoder returns decoder of contract, matched by address or by current code id of contract
+func (cr *ContractRegistry) decoder(contract string) (d ContractDecoder, ok bool, err error) {
+	if cr == nil {
+		return nil, false, nil
+	}
+
+	cr.lock.RLock()
+	d, ok = cr.byAddress[contract]
+	src := cr.codeIDs
+	byCodeID := len(cr.byCodeID) > 0
+	cr.lock.RUnlock()
+
+	// code id is only resolved when there is anything to match it with
+	if ok || src == nil || !byCodeID {
+		return d, ok, nil
+	}
+
+	codeID, err := src.ContractCodeID(contract)
+	if err != nil {
+		return nil, false, &ContractLookupError{Contract: contract, Err: err}
+	}
+
+	cr.lock.RLock()
+	d, ok = cr.byCodeID[codeID]
+	cr.lock.RUnlock()
+	return d, ok, nil
+}
+
+// contractActions splits `from_contract` events into separate actions.
+// Attributes of multiple contracts are flattened into one event,
+// every contract part starts with `contract_address` key, and every action with `action` key.
+func contractActions(logf types.LogFormat) (actions []ContractAction) {
+	for _, ev := range logf.Events {
+		if ev.Type != "from_contract" || ev.Attributes == nil {
+			continue
+		}
+
+		var current *ContractAction
+		var hasAction bool
+		for _, kv := range ev.Attributes.Ordered {
+			switch {
+			case kv.Key == "contract_address":
+				if current != nil {
+					actions = append(actions, *current)
+				}
+				current = &ContractAction{Contract: kv.Value}
+				hasAction = false
+				continue
+			case current == nil:
+				continue
+			case kv.Key == "action" && hasAction: // next action of the same contract
+				actions = append(actions, *current)
+				current = &ContractAction{Contract: current.Contract}
+			}
+
+			if kv.Key == "action" {
+				hasAction = true
+			}
+			current.Attributes = append(current.Attributes, kv)
+		}
+
+		if current != nil {
+			actions = append(actions, *current)
+		}
+	}
+
+	return actions
+}
+
+// AttributesDecoder is a generic decoder producing one subevent per action reported by contract.
+// Subevent type is the action name, attributes listed in Amounts and Accounts are mapped
+// into amount and node fields, everything else is put into additional.
+type AttributesDecoder struct {
+	Module string
+	// Amounts maps attribute holding amount to the attribute holding its currency.
+	// Empty currency attribute means the value names its currency, as list of assets (eg. `100uusd, 20terra1...`).
+	// Values without known currency are put into additional
+	Amounts map[string]string
+	// Currencies sets fixed currency of amount attributes (eg. stable coin deposits)
+	Currencies map[string]string
+	// Accounts is a list of attributes holding addresses
+	Accounts []string
+}
+
+// Decode fulfills ContractDecoder interface
+func (ad AttributesDecoder) Decode(contract string, execMsg []byte, actions []ContractAction) (subs []structs.SubsetEvent, err error) {
+	for _, ca := range actions {
+		action := ca.Get("action")
+		if action == "" {
+			continue
+		}
+
+		se := structs.SubsetEvent{
+			Type:       []string{action},
+			Module:     ad.Module,
+			Node:       map[string][]structs.Account{"contract": {{ID: contract}}},
+			Additional: map[string][]string{},
+		}
+
+		for _, kv := range ca.Attributes {
+			if kv.Key == "action" {
+				continue
+			}
+
+			if currencyKey, ok := ad.Amounts[kv.Key]; ok {
+				currency := ad.Currencies[kv.Key]
+				if currencyKey != "" {
+					currency = ca.Get(currencyKey)
+				}
+				if ams := amountsFromAttribute(kv.Value, currency); len(ams) > 0 {
+					if se.Amount == nil {
+						se.Amount = map[string]structs.TransactionAmount{}
+					}
+					for i, am := range ams {
+						key := kv.Key
+						if i > 0 {
+							key += "_" + strconv.Itoa(i)
+						}
+						se.Amount[key] = am
+					}
+					continue
+				}
+			}
+
+			if isAccountKey(ad.Accounts, kv.Key) {
+				se.Node[kv.Key] = append(se.Node[kv.Key], structs.Account{ID: kv.Value})
+				continue
+			}
+
+			se.Additional[kv.Key] = append(se.Additional[kv.Key], kv.Value)
+		}
+
+		subs = append(subs, se)
+	}
+
+	return subs, nil
+}
+
+// amountsFromAttribute parses amounts of value in currency, or assets naming their currency when currency is not known
+func amountsFromAttribute(value, currency string) (ams []structs.TransactionAmount) {
+	for _, v := range strings.Split(value, ",") {
+		v = strings.TrimSpace(v)
+		if currency != "" {
+			if am, err := ParseAmount(currency, v); err == nil {
+				ams = append(ams, am)
+			}
+			continue
+		}
+
+		// assets formatted as coins, where native denom or token address follows the number
+		if am, err := ParseCoin(v); err == nil {
+			ams = append(ams, am)
+		}
+	}
+	return ams
+}
+
+func isAccountKey(keys []string, key string) bool {
+	for _, k := range keys {
+		if k == key {
+			return true
+		}
+	}
+	return false
+}
+
+// CW20Decoder marks cw20 token contracts. Their messages are decoded into fields of `execute_contract` subevent
+// (`cw20_action`, `cw20` amount, `owner` and `recipient`), so it produces no subevents
+type CW20Decoder struct{}
+
+// Decode fulfills ContractDecoder interface
+func (CW20Decoder) Decode(contract string, execMsg []byte, actions []ContractAction) ([]structs.SubsetEvent, error) {
+	return nil, nil
+}
+
+// builtinDecoders decoders of well known dApps
+var builtinDecoders = map[string]ContractDecoder{
+	"cw20": CW20Decoder{},
+	// `share` and `withdrawn_share` are amounts of liquidity token of the pair, so they are left in additional
+	"terraswap_pair": AttributesDecoder{
+		Module: "terraswap",
+		Amounts: map[string]string{
+			"offer_amount":      "offer_asset",
+			"return_amount":     "ask_asset",
+			"tax_amount":        "ask_asset",
+			"spread_amount":     "ask_asset",
+			"commission_amount": "ask_asset",
+			"assets":            "",
+			"refund_assets":     "",
+		},
+		Accounts: []string{"sender", "receiver"},
+	},
+	"anchor_market": AttributesDecoder{
+		Module: "anchor",
+		Currencies: map[string]string{
+			"deposit_amount": "uusd",
+			"redeem_amount":  "uusd",
+			"borrow_amount":  "uusd",
+			"repay_amount":   "uusd",
+		},
+		// minted and burned aUST is a token of address differing per chain, so `mint_amount` and `burn_amount` are left in additional
+		Amounts: map[string]string{
+			"deposit_amount": "",
+			"redeem_amount":  "",
+			"borrow_amount":  "",
+			"repay_amount":   "",
+		},
+		Accounts: []string{"depositor", "redeemer", "borrower"},
+	},
+	// mirror formats amounts as assets (eg. `100terra1...`), bare numbers are left in additional
+	"mirror_mint": AttributesDecoder{
+		Module: "mirror",
+		Amounts: map[string]string{
+			"collateral_amount": "",
+			"deposit_amount":    "",
+			"withdraw_amount":   "",
+			"mint_amount":       "",
+			"burn_amount":       "",
+			"protocol_fee":      "",
+			"tax_amount":        "",
+		},
+		Accounts: []string{"owner", "sender"},
+	},
+}
@@ -0,0 +1,267 @@
+package mapper
+
+import (
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/figment-networks/indexer-manager/structs"
+	"github.com/figment-networks/terra-worker/api/types"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testPair  = "terra1jxazgm67et0ce260kvrpfv50acuushpjsz2y0p"
+	testOther = "terra1tndcaqxkpc5ce9qee5ggqf430mr2z3pefe5wj6"
+)
+
+func fromContractLog(kvs ...string) types.LogFormat {
+	attrs := &types.TxEventsAttributes{}
+	for i := 0; i+1 < len(kvs); i += 2 {
+		attrs.Ordered = append(attrs.Ordered, types.TxTags{Key: kvs[i], Value: kvs[i+1]})
+	}
+	return types.LogFormat{Events: []types.TxEvents{{Type: "from_contract", Attributes: attrs}}}
+}
+
+// codeIDs are code ids of contracts, counting calls of ContractCodeID
+type codeIDs struct {
+	ids     map[string]uint64
+	lookups int
+}
+
+func (c *codeIDs) ContractCodeID(address string) (uint64, error) {
+	c.lookups++
+	codeID, ok := c.ids[address]
+	if !ok {
+		return 0, errors.New("contract not found")
+	}
+	return codeID, nil
+}
+
+func (c *codeIDs) SetContractCodeID(address string, codeID uint64) {
+	c.ids[address] = codeID
+}
+
+func TestContractRegistry_LoadConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  string
+		wantErr bool
+		known   []string
+	}{
+		{
+			name:   "contracts of chain",
+			config: `{"columbus-4": {"contracts": [{"address": "` + testPair + `", "decoder": "terraswap_pair"}]}}`,
+			known:  []string{testPair},
+		},
+		{
+			name:   "other chain",
+			config: `{"tequila-0004": {"contracts": [{"address": "` + testPair + `", "decoder": "terraswap_pair"}]}}`,
+		},
+		{
+			name:   "code ids of chain",
+			config: `{"columbus-4": {"code_ids": [{"code_id": 4, "decoder": "terraswap_pair"}]}}`,
+			known:  []string{testPair},
+		},
+		{
+			name:    "unknown decoder",
+			config:  `{"columbus-4": {"contracts": [{"address": "` + testPair + `", "decoder": "unknown"}]}}`,
+			wantErr: true,
+		},
+		{
+			name:    "unknown decoder of code id",
+			config:  `{"columbus-4": {"code_ids": [{"code_id": 4, "decoder": "unknown"}]}}`,
+			wantErr: true,
+		},
+		{
+			name:    "invalid json",
+			config:  `{"columbus-4": [`,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cr := NewContractRegistry()
+			cr.SetCodeIDSource(&codeIDs{ids: map[string]uint64{testPair: 4, testOther: 5}})
+			err := cr.LoadConfig(strings.NewReader(tt.config), "columbus-4")
+			require.Equal(t, tt.wantErr, err != nil, err)
+
+			for _, address := range []string{testPair, testOther} {
+				logf := fromContractLog("contract_address", address, "action", "swap")
+				subs, err := cr.Decode(address, nil, logf)
+				require.NoError(t, err)
+				require.Equal(t, isAccountKey(tt.known, address), subs != nil, address)
+			}
+		})
+	}
+}
+
+func TestContractRegistry_Decode(t *testing.T) {
+	cr := NewContractRegistry()
+	require.NoError(t, cr.AddContract(testPair, "terraswap_pair"))
+
+	logf := fromContractLog(
+		"contract_address", testPair, "action", "swap", "offer_asset", "uusd", "ask_asset", "uluna", "offer_amount", "1000", "return_amount", "10", "sender", testOther,
+		"contract_address", testOther, "action", "transfer", "amount", "10",
+		"contract_address", testPair, "action", "provide_liquidity", "assets", "100uusd, 20"+testOther,
+	)
+
+	subs, err := cr.Decode(testPair, nil, logf)
+	require.NoError(t, err)
+	require.Equal(t, []structs.SubsetEvent{
+		{
+			Type:   []string{"swap"},
+			Module: "terraswap",
+			Node:   map[string][]structs.Account{"contract": {{ID: testPair}}, "sender": {{ID: testOther}}},
+			Amount: map[string]structs.TransactionAmount{
+				"offer_amount":  {Text: "1000", Numeric: big.NewInt(1000), Currency: "uusd"},
+				"return_amount": {Text: "10", Numeric: big.NewInt(10), Currency: "uluna"},
+			},
+			Additional: map[string][]string{"offer_asset": {"uusd"}, "ask_asset": {"uluna"}},
+		},
+		{
+			Type:   []string{"provide_liquidity"},
+			Module: "terraswap",
+			Node:   map[string][]structs.Account{"contract": {{ID: testPair}}},
+			Amount: map[string]structs.TransactionAmount{
+				"assets":   {Text: "100", Numeric: big.NewInt(100), Currency: "uusd"},
+				"assets_1": {Text: "20", Numeric: big.NewInt(20), Currency: testOther},
+			},
+			Additional: map[string][]string{},
+		},
+	}, subs)
+
+	// unknown contract is not decoded
+	subs, err = cr.Decode(testOther, nil, logf)
+	require.NoError(t, err)
+	require.Nil(t, subs)
+
+	// registries are independent
+	subs, err = NewContractRegistry().Decode(testPair, nil, logf)
+	require.NoError(t, err)
+	require.Nil(t, subs)
+}
+
+func TestBuiltinDecoders_Currencies(t *testing.T) {
+	const token = "terra15gwkyepfc6xgca5t5zefzwy42uts8l2m4g40k6"
+
+	tests := []struct {
+		name           string
+		decoder        string
+		kvs            []string
+		wantAmount     map[string]structs.TransactionAmount
+		wantAdditional map[string][]string
+	}{
+		{
+			name:    "anchor deposit leaves minted aUST undecoded",
+			decoder: "anchor_market",
+			kvs:     []string{"action", "deposit_stable", "depositor", testOther, "mint_amount", "900", "deposit_amount", "1000"},
+			wantAmount: map[string]structs.TransactionAmount{
+				"deposit_amount": {Text: "1000", Numeric: big.NewInt(1000), Currency: "uusd"},
+			},
+			wantAdditional: map[string][]string{"mint_amount": {"900"}},
+		},
+		{
+			name:    "mirror amounts name their assets",
+			decoder: "mirror_mint",
+			kvs:     []string{"action", "open_position", "mint_amount", "100" + token, "collateral_amount", "150uusd", "protocol_fee", "3"},
+			wantAmount: map[string]structs.TransactionAmount{
+				"mint_amount":       {Text: "100", Numeric: big.NewInt(100), Currency: token},
+				"collateral_amount": {Text: "150", Numeric: big.NewInt(150), Currency: "uusd"},
+			},
+			wantAdditional: map[string][]string{"protocol_fee": {"3"}},
+		},
+		{
+			name:           "terraswap share is not in currency of the pair",
+			decoder:        "terraswap_pair",
+			kvs:            []string{"action", "provide_liquidity", "share", "50"},
+			wantAdditional: map[string][]string{"share": {"50"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cr := NewContractRegistry()
+			require.NoError(t, cr.AddContract(testPair, tt.decoder))
+
+			subs, err := cr.Decode(testPair, nil, fromContractLog(append([]string{"contract_address", testPair}, tt.kvs...)...))
+			require.NoError(t, err)
+			require.Len(t, subs, 1)
+			require.Equal(t, tt.wantAmount, subs[0].Amount)
+			for k, v := range tt.wantAdditional {
+				require.Equal(t, v, subs[0].Additional[k], k)
+			}
+		})
+	}
+}
+
+func TestContractRegistry_DecodeByCodeID(t *testing.T) {
+	logf := fromContractLog("contract_address", testPair, "action", "swap")
+	migrated := types.LogFormat{Events: []types.TxEvents{{Type: "migrate_contract", Attributes: &types.TxEventsAttributes{
+		Ordered: []types.TxTags{{Key: "code_id", Value: "5"}, {Key: "contract_address", Value: testPair}},
+	}}}}
+	instantiated := types.LogFormat{Events: []types.TxEvents{{Type: "instantiate_contract", Attributes: &types.TxEventsAttributes{
+		Ordered: []types.TxTags{{Key: "owner", Value: testOther}, {Key: "code_id", Value: "4"}, {Key: "contract_address", Value: testPair}},
+	}}}}
+
+	tests := []struct {
+		name        string
+		source      map[string]uint64
+		recorded    []types.LogFormat
+		address     bool
+		want        bool
+		wantErr     bool
+		wantLookups int
+	}{
+		{name: "code id of contract", source: map[string]uint64{testPair: 4}, want: true, wantLookups: 1},
+		{name: "migrated to other code", source: map[string]uint64{testPair: 4}, recorded: []types.LogFormat{migrated}, wantLookups: 1},
+		{name: "instantiated from code", source: map[string]uint64{}, recorded: []types.LogFormat{instantiated}, want: true, wantLookups: 1},
+		{name: "address before code id", source: map[string]uint64{testPair: 5}, address: true, want: true},
+		{name: "no code id source", want: false},
+		{name: "code id lookup failure", source: map[string]uint64{}, wantErr: true, wantLookups: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cr := NewContractRegistry()
+			require.NoError(t, cr.AddCodeID(4, "terraswap_pair"))
+			if tt.address {
+				require.NoError(t, cr.AddContract(testPair, "terraswap_pair"))
+			}
+			src := &codeIDs{ids: tt.source}
+			if tt.source != nil {
+				cr.SetCodeIDSource(src)
+			}
+			for _, lf := range tt.recorded {
+				cr.RecordCodeIDs(lf)
+			}
+
+			subs, err := cr.Decode(testPair, nil, logf)
+			require.Equal(t, tt.wantErr, err != nil, err)
+			require.Equal(t, tt.want, subs != nil)
+			require.Equal(t, tt.wantLookups, src.lookups)
+
+			var lookupErr *ContractLookupError
+			require.Equal(t, tt.wantErr, errors.As(err, &lookupErr))
+		})
+	}
+}
+
+func TestContractRegistry_DecodeNil(t *testing.T) {
+	var cr *ContractRegistry
+	subs, err := cr.Decode(testPair, nil, fromContractLog("contract_address", testPair, "action", "swap"))
+	require.NoError(t, err)
+	require.Nil(t, subs)
+}
+
+func TestContractActions(t *testing.T) {
+	logf := fromContractLog(
+		"action", "ignored",
+		"contract_address", testPair, "action", "swap", "offer_amount", "1", "action", "transfer", "amount", "2",
+		"contract_address", testOther, "amount", "3",
+	)
+	require.Equal(t, []ContractAction{
+		{Contract: testPair, Attributes: []types.TxTags{{Key: "action", Value: "swap"}, {Key: "offer_amount", Value: "1"}}},
+		{Contract: testPair, Attributes: []types.TxTags{{Key: "action", Value: "transfer"}, {Key: "amount", Value: "2"}}},
+		{Contract: testOther, Attributes: []types.TxTags{{Key: "amount", Value: "3"}}},
+	}, contractActions(logf))
+}
@@ -7,9 +7,7 @@ import (
 	"github.com/figment-networks/indexer-manager/structs"
 
 	sdk "github.com/cosmos/cosmos-sdk/types"
-	"github.com/tendermint/tendermint/libs/bech32"
 
-	"github.com/terra-project/core/types/util"
 	crisis "github.com/terra-project/core/x/crisis"
 )
 
@@ -22,7 +20,7 @@ func CrisisVerifyInvariantToSub(msg sdk.Msg) (se structs.SubsetEvent, er error)
 	bech32Addr := ""
 	if !mvi.Sender.Empty() {
 		var err error
-		bech32Addr, err = bech32.ConvertAndEncode(util.Bech32PrefixAccAddr, mvi.Sender.Bytes())
+		bech32Addr, err = AccAddress(mvi.Sender)
 		if err != nil {
 			return se, fmt.Errorf("error converting Sender: %w", err)
 		}
@@ -0,0 +1,141 @@
+package mapper
+
+import (
+	"encoding/json"
+
+	"github.com/figment-networks/indexer-manager/structs"
+	"github.com/figment-networks/terra-worker/api/types"
+)
+
+// cw20Msg holds known shapes of cw20 execute messages
+type cw20Msg struct {
+	Transfer     *cw20MsgBody `json:"transfer,omitempty"`
+	Send         *cw20MsgBody `json:"send,omitempty"`
+	TransferFrom *cw20MsgBody `json:"transfer_from,omitempty"`
+	SendFrom     *cw20MsgBody `json:"send_from,omitempty"`
+	Mint         *cw20MsgBody `json:"mint,omitempty"`
+	Burn         *cw20MsgBody `json:"burn,omitempty"`
+	BurnFrom     *cw20MsgBody `json:"burn_from,omitempty"`
+}
+
+type cw20MsgBody struct {
+	Owner     string `json:"owner,omitempty"`
+	Recipient string `json:"recipient,omitempty"`
+	Contract  string `json:"contract,omitempty"`
+	Amount    string `json:"amount"`
+}
+
+// cw20Action is a single token movement reported by contract in `from_contract` event
+type cw20Action struct {
+	Contract string
+	Action   string
+	From     string
+	To       string
+	Amount   string
+}
+
+// cw20TransferTypes maps cw20 actions to transfer types
+var cw20TransferTypes = map[string]string{
+	"transfer":      "send",
+	"send":          "send",
+	"transfer_from": "send",
+	"send_from":     "send",
+	"mint":          "mint",
+	"burn":          "burn",
+	"burn_from":     "burn",
+}
+
+// cw20FromMessage recognizes cw20 message, returning the action name and its body.
+// Other contracts might accept messages of the same shape, so it's only used for registered cw20 contracts
+func cw20FromMessage(execMsg []byte) (action string, body *cw20MsgBody) {
+	m := &cw20Msg{}
+	if err := json.Unmarshal(execMsg, m); err != nil {
+		return "", nil
+	}
+
+	switch {
+	case m.Transfer != nil:
+		return "transfer", m.Transfer
+	case m.Send != nil:
+		return "send", m.Send
+	case m.TransferFrom != nil:
+		return "transfer_from", m.TransferFrom
+	case m.SendFrom != nil:
+		return "send_from", m.SendFrom
+	case m.Mint != nil:
+		return "mint", m.Mint
+	case m.Burn != nil:
+		return "burn", m.Burn
+	case m.BurnFrom != nil:
+		return "burn_from", m.BurnFrom
+	}
+	return "", nil
+}
+
+// cw20FromLog reads cw20 actions out of `from_contract` events.
+func cw20FromLog(logf types.LogFormat) (actions []cw20Action) {
+	for _, ca := range contractActions(logf) {
+		a := cw20Action{Contract: ca.Contract}
+		for _, kv := range ca.Attributes {
+			switch kv.Key {
+			case "action":
+				a.Action = kv.Value
+			case "from", "owner":
+				a.From = kv.Value
+			case "to", "recipient":
+				a.To = kv.Value
+			case "amount":
+				a.Amount = kv.Value
+			}
+		}
+		if _, ok := cw20TransferTypes[a.Action]; ok && a.Amount != "" {
+			actions = append(actions, a)
+		}
+	}
+
+	return actions
+}
+
+// produceCW20Transfers appends token movements reported by contracts registered as cw20 tokens as transfers,
+// other contracts might report actions of the same names. Contract address is used as currency of transferred amounts.
+// Accounts sending tokens are appended to sender and accounts receiving tokens to recipient of subevent
+func produceCW20Transfers(se *structs.SubsetEvent, logf types.LogFormat, cr *ContractRegistry) {
+	for _, a := range cw20FromLog(logf) {
+		// contracts failing lookup are not known to be tokens
+		if isCW20, err := cr.IsCW20(a.Contract); err != nil || !isCW20 {
+			continue
+		}
+
+		am, err := ParseAmount(a.Contract, a.Amount)
+		if err != nil {
+			continue
+		}
+
+		transferType := cw20TransferTypes[a.Action]
+		if transferType != "mint" && a.From != "" {
+			se.Sender = append(se.Sender, structs.EventTransfer{
+				Account: structs.Account{ID: a.From},
+				Amounts: []structs.TransactionAmount{am},
+			})
+		}
+		if transferType != "burn" && a.To != "" {
+			se.Recipient = append(se.Recipient, structs.EventTransfer{
+				Account: structs.Account{ID: a.To},
+				Amounts: []structs.TransactionAmount{am},
+			})
+		}
+
+		account := a.To
+		if transferType == "burn" {
+			account = a.From
+		}
+
+		if se.Transfers == nil {
+			se.Transfers = make(map[string][]structs.EventTransfer)
+		}
+		se.Transfers[transferType] = append(se.Transfers[transferType], structs.EventTransfer{
+			Account: structs.Account{ID: account},
+			Amounts: []structs.TransactionAmount{am},
+		})
+	}
+}
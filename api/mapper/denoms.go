@@ -0,0 +1,144 @@
+package mapper
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+
+	"github.com/figment-networks/indexer-manager/structs"
+)
+
+// DenomMetadata describes how amounts of denomination are presented to users
+type DenomMetadata struct {
+	// Denom is the on-chain denomination (`uluna`, `ibc/27394FB0...`, cw20 contract address)
+	Denom string `json:"denom"`
+	// Display is the denomination shown to users (`LUNA`)
+	Display string `json:"display"`
+	// Decimals is the number of decimal places between denom and display denom (6 for `uluna` and `LUNA`)
+	Decimals int32 `json:"decimals"`
+}
+
+// DisplayAmount is amount converted into display denomination
+type DisplayAmount struct {
+	Currency string `json:"currency"`
+	Decimals int32  `json:"decimals"`
+	// Text is the human readable value, without trailing zeros (`1.5` for `1500000uluna`)
+	Text string `json:"text"`
+}
+
+// DenomsConfig is a list of additional (cw20, ibc) denominations per chain id
+//
+//	{
+//	  "columbus-4": {
+//	    "denoms": [{"denom": "terra14z56l0fp2lsf86zy3hty2z47ezkhnthtr9yq76", "display": "ANC", "decimals": 6}]
+//	  }
+//	}
+type DenomsConfig map[string]ChainDenoms
+
+// ChainDenoms additional denominations of single chain
+type ChainDenoms struct {
+	Denoms []DenomMetadata `json:"denoms"`
+}
+
+// builtinDenoms are terra natives, the same on every chain
+var builtinDenoms = []DenomMetadata{
+	{Denom: "uluna", Display: "LUNA", Decimals: 6},
+	{Denom: "usdr", Display: "SDT", Decimals: 6},
+	{Denom: "uusd", Display: "UST", Decimals: 6},
+	{Denom: "ukrw", Display: "KRT", Decimals: 6},
+	{Denom: "umnt", Display: "MNT", Decimals: 6},
+	{Denom: "ueur", Display: "EUT", Decimals: 6},
+	{Denom: "ucny", Display: "CNT", Decimals: 6},
+	{Denom: "ujpy", Display: "JPT", Decimals: 6},
+	{Denom: "ugbp", Display: "GBT", Decimals: 6},
+	{Denom: "uinr", Display: "INT", Decimals: 6},
+	{Denom: "ucad", Display: "CAT", Decimals: 6},
+	{Denom: "uchf", Display: "CHT", Decimals: 6},
+	{Denom: "uhkd", Display: "HKT", Decimals: 6},
+	{Denom: "uaud", Display: "AUT", Decimals: 6},
+	{Denom: "usgd", Display: "SGT", Decimals: 6},
+	{Denom: "uthb", Display: "THT", Decimals: 6},
+	{Denom: "usek", Display: "SET", Decimals: 6},
+	{Denom: "unok", Display: "NOT", Decimals: 6},
+	{Denom: "udkk", Display: "DKT", Decimals: 6},
+	{Denom: "uidr", Display: "IDT", Decimals: 6},
+	{Denom: "uphp", Display: "PHT", Decimals: 6},
+}
+
+// DenomRegistry keeps metadata of known denominations
+type DenomRegistry struct {
+	lock   sync.RWMutex
+	denoms map[string]DenomMetadata
+}
+
+// NewDenomRegistry is DenomRegistry constructor, with terra natives registered
+func NewDenomRegistry() *DenomRegistry {
+	dr := &DenomRegistry{denoms: map[string]DenomMetadata{}}
+	for _, dm := range builtinDenoms {
+		dr.denoms[dm.Denom] = dm
+	}
+	return dr
+}
+
+// AddDenom adds or replaces metadata of denomination
+func (dr *DenomRegistry) AddDenom(dm DenomMetadata) error {
+	if dm.Denom == "" || dm.Display == "" {
+		return fmt.Errorf("denom and display denom are required, got %q and %q", dm.Denom, dm.Display)
+	}
+	if dm.Decimals < 0 {
+		return fmt.Errorf("negative decimals of %s", dm.Denom)
+	}
+
+	dr.lock.Lock()
+	defer dr.lock.Unlock()
+	dr.denoms[dm.Denom] = dm
+	return nil
+}
+
+// Get returns metadata of denomination
+func (dr *DenomRegistry) Get(denom string) (dm DenomMetadata, ok bool) {
+	dr.lock.RLock()
+	defer dr.lock.RUnlock()
+	dm, ok = dr.denoms[denom]
+	return dm, ok
+}
+
+// LoadConfig loads denominations of given chain from json config
+func (dr *DenomRegistry) LoadConfig(r io.Reader, chainID string) error {
+	cfg := DenomsConfig{}
+	if err := json.NewDecoder(r).Decode(&cfg); err != nil {
+		return fmt.Errorf("error decoding denoms config: %w", err)
+	}
+
+	chain, ok := cfg[chainID]
+	if !ok {
+		return nil
+	}
+
+	for _, dm := range chain.Denoms {
+		if err := dr.AddDenom(dm); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Display converts amount into display denomination, returns false for unknown currency or amount without value
+func (dr *DenomRegistry) Display(am structs.TransactionAmount) (da DisplayAmount, ok bool) {
+	if am.Numeric == nil {
+		return da, false
+	}
+	dm, ok := dr.Get(am.Currency)
+	if !ok {
+		return da, false
+	}
+
+	text := FormatDecimal(am.Numeric, am.Exp+dm.Decimals)
+	if strings.IndexByte(text, '.') >= 0 {
+		text = strings.TrimRight(strings.TrimRight(text, "0"), ".")
+	}
+
+	return DisplayAmount{Currency: dm.Display, Decimals: dm.Decimals, Text: text}, true
+}
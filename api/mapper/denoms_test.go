@@ -0,0 +1,121 @@
+package mapper
+
+import (
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/figment-networks/indexer-manager/structs"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDenomRegistry_Display(t *testing.T) {
+	dr := NewDenomRegistry()
+	require.NoError(t, dr.AddDenom(DenomMetadata{Denom: "terra14z56l0fp2lsf86zy3hty2z47ezkhnthtr9yq76", Display: "ANC", Decimals: 6}))
+	require.NoError(t, dr.AddDenom(DenomMetadata{Denom: "ibc/0471F1C4E7AFD3F07702BEF6DC365268D64570F7C1FDC98EA6098DD6DE59817B", Display: "OSMO", Decimals: 6}))
+
+	tests := []struct {
+		name   string
+		amount structs.TransactionAmount
+		want   DisplayAmount
+		wantOk bool
+	}{
+		{
+			name:   "native",
+			amount: structs.TransactionAmount{Text: "1500000", Numeric: big.NewInt(1500000), Currency: "uluna"},
+			want:   DisplayAmount{Currency: "LUNA", Decimals: 6, Text: "1.5"},
+			wantOk: true,
+		},
+		{
+			name:   "whole number",
+			amount: structs.TransactionAmount{Text: "12000000", Numeric: big.NewInt(12000000), Currency: "uusd"},
+			want:   DisplayAmount{Currency: "UST", Decimals: 6, Text: "12"},
+			wantOk: true,
+		},
+		{
+			name:   "decimal with sdk precision",
+			amount: structs.TransactionAmount{Text: "1.500000000000000000", Numeric: big.NewInt(1500000000000000000), Exp: 18, Currency: "ukrw"},
+			want:   DisplayAmount{Currency: "KRT", Decimals: 6, Text: "0.0000015"},
+			wantOk: true,
+		},
+		{
+			name:   "zero",
+			amount: structs.TransactionAmount{Text: "0", Numeric: big.NewInt(0), Currency: "uluna"},
+			want:   DisplayAmount{Currency: "LUNA", Decimals: 6, Text: "0"},
+			wantOk: true,
+		},
+		{
+			name:   "negative",
+			amount: structs.TransactionAmount{Text: "-1", Numeric: big.NewInt(-1), Currency: "uluna"},
+			want:   DisplayAmount{Currency: "LUNA", Decimals: 6, Text: "-0.000001"},
+			wantOk: true,
+		},
+		{
+			name:   "cw20",
+			amount: structs.TransactionAmount{Text: "2500", Numeric: big.NewInt(2500), Currency: "terra14z56l0fp2lsf86zy3hty2z47ezkhnthtr9yq76"},
+			want:   DisplayAmount{Currency: "ANC", Decimals: 6, Text: "0.0025"},
+			wantOk: true,
+		},
+		{
+			name:   "ibc",
+			amount: structs.TransactionAmount{Text: "100", Numeric: big.NewInt(100), Currency: "ibc/0471F1C4E7AFD3F07702BEF6DC365268D64570F7C1FDC98EA6098DD6DE59817B"},
+			want:   DisplayAmount{Currency: "OSMO", Decimals: 6, Text: "0.0001"},
+			wantOk: true,
+		},
+		{
+			name:   "unknown currency",
+			amount: structs.TransactionAmount{Text: "1", Numeric: big.NewInt(1), Currency: "uatom"},
+		},
+		{
+			name:   "no currency",
+			amount: structs.TransactionAmount{Text: "1", Numeric: big.NewInt(1)},
+		},
+		{
+			name:   "currency only",
+			amount: structs.TransactionAmount{Currency: "uluna"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := dr.Display(tt.amount)
+			require.Equal(t, tt.wantOk, ok)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestDenomRegistry_LoadConfig(t *testing.T) {
+	config := `{
+		"columbus-4": {"denoms": [
+			{"denom": "terra14z56l0fp2lsf86zy3hty2z47ezkhnthtr9yq76", "display": "ANC", "decimals": 6},
+			{"denom": "uluna", "display": "Luna", "decimals": 6}
+		]},
+		"tequila-0004": {"denoms": [{"denom": "terra1747mad58h0w4y589y3sk84r5efqdev9q4r02pc", "display": "ANC", "decimals": 6}]}
+	}`
+
+	dr := NewDenomRegistry()
+	require.NoError(t, dr.LoadConfig(strings.NewReader(config), "columbus-4"))
+
+	dm, ok := dr.Get("terra14z56l0fp2lsf86zy3hty2z47ezkhnthtr9yq76")
+	require.True(t, ok)
+	require.Equal(t, DenomMetadata{Denom: "terra14z56l0fp2lsf86zy3hty2z47ezkhnthtr9yq76", Display: "ANC", Decimals: 6}, dm)
+
+	// config overrides built-in natives
+	dm, ok = dr.Get("uluna")
+	require.True(t, ok)
+	require.Equal(t, "Luna", dm.Display)
+
+	// natives not present in config are kept
+	_, ok = dr.Get("uusd")
+	require.True(t, ok)
+
+	// denominations of other chains are not loaded
+	_, ok = dr.Get("terra1747mad58h0w4y589y3sk84r5efqdev9q4r02pc")
+	require.False(t, ok)
+
+	require.NoError(t, NewDenomRegistry().LoadConfig(strings.NewReader(config), "bombay-12"))
+	require.Error(t, NewDenomRegistry().LoadConfig(strings.NewReader(`{"columbus-4": {"denoms": [{"denom": "uluna"}]}}`), "columbus-4"))
+	require.Error(t, NewDenomRegistry().LoadConfig(strings.NewReader(`{"columbus-4": {"denoms": [{"denom": "uluna", "display": "LUNA", "decimals": -1}]}}`), "columbus-4"))
+	require.Error(t, NewDenomRegistry().LoadConfig(strings.NewReader(`[]`), "columbus-4"))
+}
@@ -7,12 +7,9 @@ import (
 	"github.com/figment-networks/indexer-manager/structs"
 	"github.com/figment-networks/terra-worker/api/types"
 
-	"github.com/tendermint/tendermint/libs/bech32"
-
 	sdk "github.com/cosmos/cosmos-sdk/types"
 	distributiontypes "github.com/cosmos/cosmos-sdk/x/distribution/types"
 
-	"github.com/terra-project/core/types/util"
 	"github.com/terra-project/core/x/distribution"
 )
 
@@ -22,7 +19,7 @@ func DistributionWithdrawValidatorCommissionToSub(msg sdk.Msg, logf types.LogFor
 		return se, errors.New("Not a withdraw_validator_commission type")
 	}
 
-	bech32ValAddr, err := bech32.ConvertAndEncode(util.Bech32PrefixValAddr, wvc.ValidatorAddress.Bytes())
+	bech32ValAddr, err := ValAddress(wvc.ValidatorAddress)
 	if err != nil {
 		return se, fmt.Errorf("error converting ValidatorAddress: %w", err)
 	}
@@ -46,11 +43,11 @@ func DistributionSetWithdrawAddressToSub(msg sdk.Msg) (se structs.SubsetEvent, e
 		return se, errors.New("Not a set_withdraw_address type")
 	}
 
-	delegatorBech32ValAddr, err := bech32.ConvertAndEncode(util.Bech32PrefixAccAddr, swa.DelegatorAddress.Bytes())
+	delegatorBech32ValAddr, err := AccAddress(swa.DelegatorAddress)
 	if err != nil {
 		return se, fmt.Errorf("error converting DelegatorAddress: %w", err)
 	}
-	withdrawBech32ValAddr, err := bech32.ConvertAndEncode(util.Bech32PrefixAccAddr, swa.WithdrawAddress.Bytes())
+	withdrawBech32ValAddr, err := AccAddress(swa.WithdrawAddress)
 	if err != nil {
 		return se, fmt.Errorf("error converting WithdrawAddress: %w", err)
 	}
@@ -71,12 +68,12 @@ func DistributionWithdrawDelegatorRewardToSub(msg sdk.Msg, logf types.LogFormat)
 		return se, errors.New("Not a withdraw_delegator_reward type")
 	}
 
-	bech32DelAddr, err := bech32.ConvertAndEncode(util.Bech32PrefixAccAddr, wdr.DelegatorAddress.Bytes())
+	bech32DelAddr, err := AccAddress(wdr.DelegatorAddress)
 	if err != nil {
 		return se, fmt.Errorf("error converting DelegatorAddress: %w", err)
 	}
 
-	bech32ValAddr, err := bech32.ConvertAndEncode(util.Bech32PrefixValAddr, wdr.ValidatorAddress.Bytes())
+	bech32ValAddr, err := ValAddress(wdr.ValidatorAddress)
 	if err != nil {
 		return se, fmt.Errorf("error converting ValidatorAddress: %w", err)
 	}
@@ -103,7 +100,7 @@ func DistributionFundCommunityPoolToSub(msg sdk.Msg) (se structs.SubsetEvent, er
 		return se, errors.New("Not a withdraw_fund_community_pool type")
 	}
 
-	bech32DepositerAddr, err := bech32.ConvertAndEncode(util.Bech32PrefixAccAddr, fcp.Depositor.Bytes())
+	bech32DepositerAddr, err := AccAddress(fcp.Depositor)
 	if err != nil {
 		return se, fmt.Errorf("error converting DelegatorAddress: %w", err)
 	}
@@ -125,17 +122,133 @@ func distributionProduceEvTx(account string, coins sdk.Coins) (evt structs.Event
 		Account: structs.Account{ID: account},
 	}
 	if len(coins) > 0 {
-		evt.Amounts = []structs.TransactionAmount{}
-		for _, coin := range coins {
-			txa := structs.TransactionAmount{
-				Currency: coin.Denom,
-				Text:     coin.Amount.String(),
+		evt.Amounts = CoinsAmounts(coins)
+	}
+
+	return evt, nil
+}
+
+// rewardPayout is reward withdrawn from validator and paid out to recipient
+type rewardPayout struct {
+	validator string
+	recipient string
+	amounts   []structs.TransactionAmount
+}
+
+// produceRewardTransfers appends rewards auto-withdrawn by delegation changes as `reward` transfers,
+// with validator of every transfer in `reward_validator` additional (in the same order).
+// Rewards per validator are taken from `withdraw_rewards` events when present, recipients from
+// payouts of distribution module (`transfer` or `coin_received` events), delegator when there are none.
+// Without `withdraw_rewards` events payouts are attributed to validators in the order of withdrawal
+// (source before destination of redelegation), validator is left empty when it can't be determined
+func produceRewardTransfers(se *structs.SubsetEvent, logf types.LogFormat, delegator string, validators ...string) error {
+	withdrawals, payouts, err := rewardsFromLog(logf)
+	if err != nil {
+		return err
+	}
+
+	if len(withdrawals) > 0 {
+		for i := range withdrawals {
+			withdrawals[i].recipient = delegator
+			if i < len(payouts) {
+				withdrawals[i].recipient = payouts[i].recipient
+			}
+		}
+		payouts = withdrawals
+	} else {
+		for i := range payouts {
+			switch {
+			case len(validators) == 1:
+				payouts[i].validator = validators[0]
+			case len(payouts) == len(validators):
+				payouts[i].validator = validators[i]
 			}
+		}
+	}
 
-			txa.Numeric.Set(coin.Amount.BigInt())
-			evt.Amounts = append(evt.Amounts, txa)
+	if len(payouts) == 0 {
+		return nil
+	}
+	if se.Transfers == nil {
+		se.Transfers = make(map[string][]structs.EventTransfer)
+	}
+	if se.Additional == nil {
+		se.Additional = make(map[string][]string)
+	}
+	for _, p := range payouts {
+		se.Transfers["reward"] = append(se.Transfers["reward"], structs.EventTransfer{
+			Account: structs.Account{ID: p.recipient},
+			Amounts: p.amounts,
+		})
+		se.Additional["reward_validator"] = append(se.Additional["reward_validator"], p.validator)
+	}
+	return nil
+}
+
+// rewardsFromLog reads non-zero rewards withdrawn per validator (`withdraw_rewards` events) and payouts
+// of distribution module, from `transfer` events or, when there are no transfers, from `coin_received` events
+// of amounts in `coin_spent` events of distribution module.
+// Transfers without sender (legacy logs) are payouts unless they move coins between staking pools
+func rewardsFromLog(logf types.LogFormat) (withdrawals, payouts []rewardPayout, err error) {
+	var spent, received []map[string]string
+	for _, ev := range logf.Events {
+		switch ev.Type {
+		case "withdraw_rewards":
+			for _, g := range attributeGroups(ev.Attributes, "amount") {
+				amounts, err := ParseCoins(g["amount"])
+				if err != nil {
+					return nil, nil, fmt.Errorf("[TERRA-API] Error parsing withdraw_rewards amount '%s': %w ", g["amount"], err)
+				}
+				if len(amounts) > 0 {
+					withdrawals = append(withdrawals, rewardPayout{validator: g["validator"], amounts: amounts})
+				}
+			}
+		case "transfer":
+			for _, g := range attributeGroups(ev.Attributes, "recipient") {
+				sender, recipient := g["sender"], g["recipient"]
+				if sender != distributionModuleAddr && (sender != "" || isStakingPool(recipient)) {
+					continue
+				}
+				amounts, err := ParseCoins(g["amount"])
+				if err != nil {
+					return nil, nil, fmt.Errorf("[TERRA-API] Error parsing transfer amount '%s': %w ", g["amount"], err)
+				}
+				payouts = append(payouts, rewardPayout{recipient: recipient, amounts: amounts})
+			}
+		case "coin_spent":
+			spent = append(spent, attributeGroups(ev.Attributes, "spender")...)
+		case "coin_received":
+			received = append(received, attributeGroups(ev.Attributes, "receiver")...)
 		}
 	}
 
-	return evt, nil
+	if len(payouts) > 0 {
+		return withdrawals, payouts, nil
+	}
+
+	// payouts are receipts of amounts spent by distribution module, matched by amount as sends of other accounts
+	// may be emitted in between; staking pools and distribution module only receive principal and commission
+	distributed := map[string]int{}
+	for _, g := range spent {
+		if g["spender"] == distributionModuleAddr {
+			distributed[g["amount"]]++
+		}
+	}
+	for _, g := range received {
+		receiver := g["receiver"]
+		if distributed[g["amount"]] == 0 || isStakingPool(receiver) || receiver == distributionModuleAddr {
+			continue
+		}
+		distributed[g["amount"]]--
+		amounts, err := ParseCoins(g["amount"])
+		if err != nil {
+			return nil, nil, fmt.Errorf("[TERRA-API] Error parsing coin_received amount '%s': %w ", g["amount"], err)
+		}
+		payouts = append(payouts, rewardPayout{recipient: receiver, amounts: amounts})
+	}
+	return withdrawals, payouts, nil
+}
+
+func isStakingPool(addr string) bool {
+	return addr == bondedPoolAddr || addr == notBondedPoolAddr
 }
@@ -2,6 +2,7 @@ package mapper
 
 import (
 	"errors"
+	"fmt"
 	"strconv"
 
 	"github.com/figment-networks/indexer-manager/structs"
@@ -17,12 +18,22 @@ func EvidenceSubmitEvidenceToSub(msg sdk.Msg) (se structs.SubsetEvent, er error)
 		return se, errors.New("Not a submit_evidence type")
 	}
 
+	submitter, err := AccAddress(mse.Submitter)
+	if err != nil {
+		return se, fmt.Errorf("error converting Submitter: %w", err)
+	}
+
+	consensus, err := ConsAddress(mse.Evidence.GetConsensusAddress())
+	if err != nil {
+		return se, fmt.Errorf("error converting evidence consensus address: %w", err)
+	}
+
 	return structs.SubsetEvent{
 		Type:   []string{"submit_evidence"},
 		Module: "evidence",
-		Node:   map[string][]structs.Account{"submitter": {{ID: mse.Submitter.String()}}},
+		Node:   map[string][]structs.Account{"submitter": {{ID: submitter}}},
 		Additional: map[string][]string{
-			"evidence_consensus":       {mse.Evidence.GetConsensusAddress().String()},
+			"evidence_consensus":       {consensus},
 			"evidence_height":          {strconv.FormatInt(mse.Evidence.GetHeight(), 10)},
 			"evidence_total_power":     {strconv.FormatInt(mse.Evidence.GetTotalPower(), 10)},
 			"evidence_validator_power": {strconv.FormatInt(mse.Evidence.GetValidatorPower(), 10)},
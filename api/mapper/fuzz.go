@@ -0,0 +1,88 @@
+//go:build gofuzz
+// +build gofuzz
+
+package mapper
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Fuzz targets for go-fuzz (https://github.com/dvyukov/go-fuzz), built with:
+//
+//	go-fuzz-build -func FuzzCoins ./api/mapper && go-fuzz -bin mapper-fuzz.zip -workdir fuzz/coins
+//
+// Targets panic when parsed values break invariants of the parsers, inputs found this way
+// are added to the table tests of coin_test.go, which stay the regression corpus.
+
+// Fuzz runs all parser targets of the package
+func Fuzz(data []byte) int {
+	return FuzzDecimal(data) + FuzzCoins(data) + FuzzAmount(data)
+}
+
+// FuzzDecimal checks that formatted decimal parses back into the same number and decimal places
+func FuzzDecimal(data []byte) int {
+	number, exp, err := ParseDecimal(string(data))
+	if err != nil {
+		return 0
+	}
+
+	s := FormatDecimal(number, exp)
+	n2, exp2, err := ParseDecimal(s)
+	if err != nil {
+		panic(fmt.Sprintf("formatted decimal %q of %q doesn't parse: %s", s, data, err))
+	}
+	if n2.Cmp(number) != 0 || exp2 != exp {
+		panic(fmt.Sprintf("decimal %q parsed as %s/%d, formatted %q as %s/%d", data, number, exp, s, n2, exp2))
+	}
+	return 1
+}
+
+// FuzzCoins checks that every parsed coin is non negative, has currency and Numeric and Exp matching its Text
+func FuzzCoins(data []byte) int {
+	s := string(data)
+	if _, err := ParseCoin(s); err == nil && strings.Contains(s, ",") {
+		panic(fmt.Sprintf("list of coins %q parsed as single coin", s))
+	}
+
+	amounts, err := ParseCoins(s)
+	if err != nil {
+		return 0
+	}
+	if s != "" && len(amounts) != strings.Count(s, ",")+1 {
+		panic(fmt.Sprintf("coins %q parsed into %d amounts", s, len(amounts)))
+	}
+	for _, am := range amounts {
+		if am.Currency == "" {
+			panic(fmt.Sprintf("coin of %q without currency", s))
+		}
+		if am.Numeric.Sign() < 0 {
+			panic(fmt.Sprintf("negative coin of %q", s))
+		}
+		checkAmount(s, am.Text, am.Numeric.String(), am.Exp)
+	}
+	return 1
+}
+
+// FuzzAmount checks that amount keeps the input as Text with Numeric and Exp matching it
+func FuzzAmount(data []byte) int {
+	am, err := ParseAmount("uluna", string(data))
+	if err != nil {
+		return 0
+	}
+	if am.Text != string(data) || am.Currency != "uluna" {
+		panic(fmt.Sprintf("amount of %q has text %q and currency %q", data, am.Text, am.Currency))
+	}
+	checkAmount(string(data), am.Text, am.Numeric.String(), am.Exp)
+	return 1
+}
+
+func checkAmount(input, text, numeric string, exp int32) {
+	number, exp2, err := ParseDecimal(text)
+	if err != nil {
+		panic(fmt.Sprintf("text %q of %q doesn't parse: %s", text, input, err))
+	}
+	if number.String() != numeric || exp2 != exp {
+		panic(fmt.Sprintf("amount of %q is %s/%d, its text %q is %s/%d", input, numeric, exp, text, number, exp2))
+	}
+}
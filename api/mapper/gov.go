@@ -2,14 +2,21 @@ package mapper
 
 import (
 	"errors"
+	"fmt"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/figment-networks/indexer-manager/structs"
 	"github.com/figment-networks/terra-worker/api/types"
 
 	sdk "github.com/cosmos/cosmos-sdk/types"
 
+	"github.com/terra-project/core/x/distribution"
 	"github.com/terra-project/core/x/gov"
+	"github.com/terra-project/core/x/params"
+	"github.com/terra-project/core/x/treasury"
+	"github.com/terra-project/core/x/upgrade"
 )
 
 func GovDepositToSub(msg sdk.Msg, logf types.LogFormat) (se structs.SubsetEvent, err error) {
@@ -18,22 +25,23 @@ func GovDepositToSub(msg sdk.Msg, logf types.LogFormat) (se structs.SubsetEvent,
 		return se, errors.New("Not a deposit type")
 	}
 
+	depositor, err := AccAddress(dep.Depositor)
+	if err != nil {
+		return se, fmt.Errorf("error converting Depositor: %w", err)
+	}
+
 	se = structs.SubsetEvent{
 		Type:       []string{"deposit"},
 		Module:     "gov",
-		Node:       map[string][]structs.Account{"depositor": {{ID: dep.Depositor.String()}}},
+		Node:       map[string][]structs.Account{"depositor": {{ID: depositor}}},
 		Additional: map[string][]string{"proposalID": {strconv.FormatUint(dep.ProposalID, 10)}},
 	}
 
-	sender := structs.EventTransfer{Account: structs.Account{ID: dep.Depositor.String()}}
+	sender := structs.EventTransfer{Account: structs.Account{ID: depositor}}
 	txAmount := map[string]structs.TransactionAmount{}
 
 	for i, coin := range dep.Amount {
-		am := structs.TransactionAmount{
-			Currency: coin.Denom,
-			Numeric:  coin.Amount.BigInt(),
-			Text:     coin.Amount.String(),
-		}
+		am := CoinAmount(coin)
 
 		sender.Amounts = append(sender.Amounts, am)
 		key := "deposit"
@@ -57,10 +65,15 @@ func GovVoteToSub(msg sdk.Msg) (se structs.SubsetEvent, err error) {
 		return se, errors.New("Not a vote type")
 	}
 
+	voter, err := AccAddress(vote.Voter)
+	if err != nil {
+		return se, fmt.Errorf("error converting Voter: %w", err)
+	}
+
 	return structs.SubsetEvent{
 		Type:   []string{"vote"},
 		Module: "gov",
-		Node:   map[string][]structs.Account{"voter": {{ID: vote.Voter.String()}}},
+		Node:   map[string][]structs.Account{"voter": {{ID: voter}}},
 		Additional: map[string][]string{
 			"proposalID": {strconv.FormatUint(vote.ProposalID, 10)},
 			"option":     {vote.Option.String()},
@@ -74,21 +87,22 @@ func GovSubmitProposalToSub(msg sdk.Msg, logf types.LogFormat) (se structs.Subse
 		return se, errors.New("Not a submit_proposal type")
 	}
 
+	proposer, err := AccAddress(sp.Proposer)
+	if err != nil {
+		return se, fmt.Errorf("error converting Proposer: %w", err)
+	}
+
 	se = structs.SubsetEvent{
 		Type:   []string{"submit_proposal"},
 		Module: "gov",
-		Node:   map[string][]structs.Account{"proposer": {{ID: sp.Proposer.String()}}},
+		Node:   map[string][]structs.Account{"proposer": {{ID: proposer}}},
 	}
 
-	sender := structs.EventTransfer{Account: structs.Account{ID: sp.Proposer.String()}}
+	sender := structs.EventTransfer{Account: structs.Account{ID: proposer}}
 	txAmount := map[string]structs.TransactionAmount{}
 
 	for i, coin := range sp.InitialDeposit {
-		am := structs.TransactionAmount{
-			Currency: coin.Denom,
-			Numeric:  coin.Amount.BigInt(),
-			Text:     coin.Amount.String(),
-		}
+		am := CoinAmount(coin)
 
 		sender.Amounts = append(sender.Amounts, am)
 		key := "initial_deposit"
@@ -118,7 +132,63 @@ func GovSubmitProposalToSub(msg sdk.Msg, logf types.LogFormat) (se structs.Subse
 	if sp.Content.String() != "" {
 		se.Additional["content"] = []string{sp.Content.String()}
 	}
+	if proposalID := logAttribute(logf, "submit_proposal", "proposal_id"); proposalID != "" {
+		se.Additional["proposalID"] = []string{proposalID}
+	}
+
+	if err = decodeProposalContent(&se, sp.Content); err != nil {
+		return se, err
+	}
 
 	err = produceTransfers(&se, "send", "", logf)
 	return se, err
 }
+
+// decodeProposalContent maps fields of known proposal types into subevent
+func decodeProposalContent(se *structs.SubsetEvent, content gov.Content) error {
+	switch c := content.(type) {
+	case gov.TextProposal:
+	case params.ParameterChangeProposal:
+		for _, pc := range c.Changes {
+			se.Additional["param_subspace"] = append(se.Additional["param_subspace"], pc.Subspace)
+			se.Additional["param_key"] = append(se.Additional["param_key"], pc.Key)
+			se.Additional["param_value"] = append(se.Additional["param_value"], pc.Value)
+		}
+	case distribution.CommunityPoolSpendProposal:
+		recipient, err := AccAddress(c.Recipient)
+		if err != nil {
+			return fmt.Errorf("error converting Recipient: %w", err)
+		}
+		se.Node["recipient"] = []structs.Account{{ID: recipient}}
+		// content description is formatted with global sdk prefix
+		for i, text := range se.Additional["content"] {
+			se.Additional["content"][i] = strings.ReplaceAll(text, c.Recipient.String(), recipient)
+		}
+		for i, coin := range c.Amount {
+			key := "spend"
+			if i > 0 {
+				key += "_" + strconv.Itoa(i)
+			}
+			se.Amount[key] = CoinAmount(coin)
+		}
+	case upgrade.SoftwareUpgradeProposal:
+		se.Additional["plan_name"] = []string{c.Plan.Name}
+		if c.Plan.Height > 0 {
+			se.Additional["plan_height"] = []string{strconv.FormatInt(c.Plan.Height, 10)}
+		}
+		if !c.Plan.Time.IsZero() {
+			se.Additional["plan_time"] = []string{c.Plan.Time.UTC().Format(time.RFC3339)}
+		}
+		if c.Plan.Info != "" {
+			se.Additional["plan_info"] = []string{c.Plan.Info}
+		}
+	case upgrade.CancelSoftwareUpgradeProposal:
+	case treasury.TaxRateUpdateProposal:
+		se.Additional["tax_rate"] = []string{c.TaxRate.String()}
+		se.Amount["tax_rate"] = DecAmount("", c.TaxRate)
+	case treasury.RewardWeightUpdateProposal:
+		se.Additional["reward_weight"] = []string{c.RewardWeight.String()}
+		se.Amount["reward_weight"] = DecAmount("", c.RewardWeight)
+	}
+	return nil
+}
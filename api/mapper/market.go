@@ -2,14 +2,13 @@ package mapper
 
 import (
 	"errors"
+	"fmt"
 
 	"github.com/figment-networks/indexer-manager/structs"
 	"github.com/figment-networks/terra-worker/api/types"
 
 	sdk "github.com/cosmos/cosmos-sdk/types"
-	"github.com/tendermint/tendermint/libs/bech32"
 
-	"github.com/terra-project/core/types/util"
 	"github.com/terra-project/core/x/market"
 )
 
@@ -25,15 +24,11 @@ func MarketSwapToSub(msg sdk.Msg, logf types.LogFormat) (se structs.SubsetEvent,
 
 	se.Node = map[string][]structs.Account{}
 	if !swap.Trader.Empty() {
-		traderBech32Addr, _ := bech32.ConvertAndEncode(util.Bech32PrefixAccAddr, swap.Trader.Bytes())
+		traderBech32Addr, _ := AccAddress(swap.Trader)
 		traderAccount := structs.Account{ID: traderBech32Addr}
 		se.Node["trader"] = []structs.Account{traderAccount}
 
-		offerRt := structs.TransactionAmount{
-			Currency: swap.OfferCoin.Denom,
-			Text:     swap.OfferCoin.Amount.String(),
-			Numeric:  swap.OfferCoin.Amount.BigInt(),
-		}
+		offerRt := CoinAmount(swap.OfferCoin)
 		ask := structs.TransactionAmount{Currency: swap.AskDenom}
 		se.Sender = append(se.Sender, structs.EventTransfer{
 			Account: traderAccount,
@@ -46,6 +41,10 @@ func MarketSwapToSub(msg sdk.Msg, logf types.LogFormat) (se structs.SubsetEvent,
 		}
 	}
 
+	if err = produceSwapResult(&se, logf); err != nil {
+		return se, err
+	}
+
 	err = produceTransfers(&se, "send", "", logf)
 	return se, err
 }
@@ -60,16 +59,12 @@ func MarketSwapSendToSub(msg sdk.Msg, logf types.LogFormat) (se structs.SubsetEv
 		Module: "market",
 	}
 
-	offerRt := structs.TransactionAmount{
-		Currency: swap.OfferCoin.Denom,
-		Text:     swap.OfferCoin.Amount.String(),
-		Numeric:  swap.OfferCoin.Amount.BigInt(),
-	}
+	offerRt := CoinAmount(swap.OfferCoin)
 	ask := structs.TransactionAmount{Currency: swap.AskDenom}
 
 	se.Node = map[string][]structs.Account{}
 
-	fromBech32Addr, _ := bech32.ConvertAndEncode(util.Bech32PrefixAccAddr, swap.FromAddress.Bytes())
+	fromBech32Addr, _ := AccAddress(swap.FromAddress)
 	fromAccount := structs.Account{ID: fromBech32Addr}
 
 	se.Sender = append(se.Sender, structs.EventTransfer{
@@ -77,12 +72,12 @@ func MarketSwapSendToSub(msg sdk.Msg, logf types.LogFormat) (se structs.SubsetEv
 		Amounts: []structs.TransactionAmount{offerRt, ask},
 	})
 
-	toBech32Addr, _ := bech32.ConvertAndEncode(util.Bech32PrefixAccAddr, swap.ToAddress.Bytes())
+	toBech32Addr, _ := AccAddress(swap.ToAddress)
 	toAccount := structs.Account{ID: toBech32Addr}
 
-	se.Recipient = append(se.Sender, structs.EventTransfer{
+	se.Recipient = append(se.Recipient, structs.EventTransfer{
 		Account: toAccount,
-		Amounts: []structs.TransactionAmount{offerRt, ask},
+		Amounts: []structs.TransactionAmount{ask},
 	})
 
 	se.Amount = map[string]structs.TransactionAmount{
@@ -90,6 +85,73 @@ func MarketSwapSendToSub(msg sdk.Msg, logf types.LogFormat) (se structs.SubsetEv
 		"ask":   ask,
 	}
 
+	if err = produceSwapResult(&se, logf); err != nil {
+		return se, err
+	}
+
 	err = produceTransfers(&se, "send", "", logf)
 	return se, err
 }
+
+// produceSwapResult fills received coins and spread fee taken from the `swap` log event.
+// It replaces the denom-only "ask" amount (also in sender and recipient amounts) with the actual one.
+func produceSwapResult(se *structs.SubsetEvent, logf types.LogFormat) error {
+	for _, ev := range logf.Events {
+		if ev.Type != "swap" || ev.Attributes == nil {
+			continue
+		}
+		attr := ev.Attributes
+
+		if se.Amount == nil {
+			se.Amount = map[string]structs.TransactionAmount{}
+		}
+
+		if swapFee, ok := attr.Others["swap_fee"]; ok && len(swapFee) > 0 {
+			fee, err := ParseCoin(swapFee[0])
+			if err != nil {
+				return fmt.Errorf("[TERRA-API] Error parsing swap_fee '%s': %w ", swapFee[0], err)
+			}
+			se.Amount["swap_fee"] = fee
+		}
+
+		swapCoin, ok := attr.Others["swap_coin"]
+		if !ok || len(swapCoin) == 0 {
+			continue
+		}
+
+		ask, err := ParseCoin(swapCoin[0])
+		if err != nil {
+			return fmt.Errorf("[TERRA-API] Error parsing swap_coin '%s': %w ", swapCoin[0], err)
+		}
+		se.Amount["ask"] = ask
+
+		for _, et := range [][]structs.EventTransfer{se.Sender, se.Recipient} {
+			for i := range et {
+				for j, am := range et[i].Amounts {
+					if am.Numeric == nil && am.Currency == ask.Currency {
+						et[i].Amounts[j] = ask
+					}
+				}
+			}
+		}
+
+		recipient := se.Recipient
+		if len(attr.Recipient) > 0 {
+			recipient = []structs.EventTransfer{{Account: structs.Account{ID: attr.Recipient[0]}}}
+		} else if len(recipient) == 0 {
+			recipient = se.Sender
+		}
+
+		if se.Transfers == nil {
+			se.Transfers = make(map[string][]structs.EventTransfer)
+		}
+		for _, r := range recipient {
+			se.Transfers["swap"] = append(se.Transfers["swap"], structs.EventTransfer{
+				Account: r.Account,
+				Amounts: []structs.TransactionAmount{ask},
+			})
+		}
+	}
+
+	return nil
+}
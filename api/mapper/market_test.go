@@ -0,0 +1,216 @@
+package mapper
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/figment-networks/indexer-manager/structs"
+	"github.com/figment-networks/terra-worker/api/types"
+	"github.com/stretchr/testify/require"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/terra-project/core/x/market"
+)
+
+func TestProduceSwapResult(t *testing.T) {
+	trader := structs.Account{ID: "terra190vqdjtlpcq27xslcveglfmr4ynfwg7gxlzhjn"}
+	recipient := structs.Account{ID: "terra1sxmr0k8u6trd5c6eu6trzyapzux7090yhcwdln"}
+	coin := func(s string) structs.TransactionAmount {
+		am, err := ParseCoin(s)
+		require.NoError(t, err)
+		return am
+	}
+	transfer := func(acc structs.Account, amounts ...structs.TransactionAmount) structs.EventTransfer {
+		return structs.EventTransfer{Account: acc, Amounts: amounts}
+	}
+	offer := coin("1000000uluna")
+	askUusd := structs.TransactionAmount{Currency: "uusd"}
+
+	tests := []struct {
+		name          string
+		sender        []structs.EventTransfer
+		recipient     []structs.EventTransfer
+		log           string
+		wantAmount    map[string]structs.TransactionAmount
+		wantSender    []structs.EventTransfer
+		wantRecipient []structs.EventTransfer
+		wantSwap      []structs.EventTransfer
+		wantErr       bool
+	}{
+		{
+			name:   "swap to recipient of log",
+			sender: []structs.EventTransfer{transfer(trader, offer, askUusd)},
+			log: `{"events": [{"type": "swap", "attributes": [
+				{"key": "offer", "value": "1000000uluna"}, {"key": "trader", "value": "` + trader.ID + `"}, {"key": "recipient", "value": "` + trader.ID + `"},
+				{"key": "swap_coin", "value": "12891340uusd"}, {"key": "swap_fee", "value": "25782.680000000000000000uusd"}]}]}`,
+			wantAmount: map[string]structs.TransactionAmount{"ask": coin("12891340uusd"), "swap_fee": coin("25782.680000000000000000uusd")},
+			wantSender: []structs.EventTransfer{transfer(trader, offer, coin("12891340uusd"))},
+			wantSwap:   []structs.EventTransfer{transfer(trader, coin("12891340uusd"))},
+		},
+		{
+			name:      "swapsend fills ask of sender and recipient",
+			sender:    []structs.EventTransfer{transfer(trader, coin("1000000uusd"), structs.TransactionAmount{Currency: "ukrw"})},
+			recipient: []structs.EventTransfer{transfer(recipient, structs.TransactionAmount{Currency: "ukrw"})},
+			log: `{"events": [{"type": "swap", "attributes": [
+				{"key": "offer", "value": "1000000uusd"}, {"key": "trader", "value": "` + trader.ID + `"}, {"key": "recipient", "value": "` + recipient.ID + `"},
+				{"key": "swap_coin", "value": "1180000ukrw"}, {"key": "swap_fee", "value": "3540.000000000000000000ukrw"}]}]}`,
+			wantAmount:    map[string]structs.TransactionAmount{"ask": coin("1180000ukrw"), "swap_fee": coin("3540.000000000000000000ukrw")},
+			wantSender:    []structs.EventTransfer{transfer(trader, coin("1000000uusd"), coin("1180000ukrw"))},
+			wantRecipient: []structs.EventTransfer{transfer(recipient, coin("1180000ukrw"))},
+			wantSwap:      []structs.EventTransfer{transfer(recipient, coin("1180000ukrw"))},
+		},
+		{
+			name:      "without recipient in log swap goes to recipient of message",
+			sender:    []structs.EventTransfer{transfer(trader, offer, askUusd)},
+			recipient: []structs.EventTransfer{transfer(recipient, askUusd)},
+			log: `{"events": [{"type": "swap", "attributes": [
+				{"key": "offer", "value": "1000000uluna"}, {"key": "swap_coin", "value": "12891340uusd"}]}]}`,
+			wantAmount:    map[string]structs.TransactionAmount{"ask": coin("12891340uusd")},
+			wantSender:    []structs.EventTransfer{transfer(trader, offer, coin("12891340uusd"))},
+			wantRecipient: []structs.EventTransfer{transfer(recipient, coin("12891340uusd"))},
+			wantSwap:      []structs.EventTransfer{transfer(recipient, coin("12891340uusd"))},
+		},
+		{
+			name:   "without any recipient swap goes to sender",
+			sender: []structs.EventTransfer{transfer(trader, offer, askUusd)},
+			log: `{"events": [{"type": "swap", "attributes": [
+				{"key": "offer", "value": "1000000uluna"}, {"key": "swap_coin", "value": "12891340uusd"}]}]}`,
+			wantAmount: map[string]structs.TransactionAmount{"ask": coin("12891340uusd")},
+			wantSender: []structs.EventTransfer{transfer(trader, offer, coin("12891340uusd"))},
+			wantSwap:   []structs.EventTransfer{transfer(trader, coin("12891340uusd"))},
+		},
+		{
+			name:   "fee without swap coin",
+			sender: []structs.EventTransfer{transfer(trader, offer, askUusd)},
+			log: `{"events": [{"type": "swap", "attributes": [
+				{"key": "offer", "value": "1000000uluna"}, {"key": "swap_fee", "value": "25782.680000000000000000uusd"}]}]}`,
+			wantAmount: map[string]structs.TransactionAmount{"swap_fee": coin("25782.680000000000000000uusd")},
+			wantSender: []structs.EventTransfer{transfer(trader, offer, askUusd)},
+		},
+		{
+			name:       "no swap event",
+			sender:     []structs.EventTransfer{transfer(trader, offer, askUusd)},
+			log:        `{"events": [{"type": "message", "attributes": [{"key": "action", "value": "swap"}]}]}`,
+			wantSender: []structs.EventTransfer{transfer(trader, offer, askUusd)},
+		},
+		{
+			name:    "invalid swap coin",
+			sender:  []structs.EventTransfer{transfer(trader, offer, askUusd)},
+			log:     `{"events": [{"type": "swap", "attributes": [{"key": "swap_coin", "value": "uusd"}]}]}`,
+			wantErr: true,
+		},
+		{
+			name:    "invalid swap fee",
+			sender:  []structs.EventTransfer{transfer(trader, offer, askUusd)},
+			log:     `{"events": [{"type": "swap", "attributes": [{"key": "swap_fee", "value": "1,5uusd"}]}]}`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logf := types.LogFormat{}
+			require.NoError(t, json.Unmarshal([]byte(tt.log), &logf))
+
+			se := structs.SubsetEvent{Sender: tt.sender, Recipient: tt.recipient}
+			err := produceSwapResult(&se, logf)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			for k, want := range tt.wantAmount {
+				require.Equal(t, want, se.Amount[k], k)
+			}
+			if tt.wantAmount == nil {
+				require.Empty(t, se.Amount)
+			}
+			require.Equal(t, tt.wantSender, se.Sender)
+			require.Equal(t, tt.wantRecipient, se.Recipient)
+			require.Equal(t, tt.wantSwap, se.Transfers["swap"])
+		})
+	}
+}
+
+func TestMarketSwapToSub(t *testing.T) {
+	trader := sdk.AccAddress([]byte("trader_address_00001"))
+	to := sdk.AccAddress([]byte("recipient_address_01"))
+	traderStr, _ := AccAddress(trader)
+	toStr, _ := AccAddress(to)
+	// market module account of columbus
+	const marketAddr = "terra1untf85jwv3kt0puyyc39myxjvplagr3wstgs5s"
+
+	coin := func(s string) structs.TransactionAmount {
+		am, err := ParseCoin(s)
+		require.NoError(t, err)
+		return am
+	}
+	transfer := func(acc string, amounts ...structs.TransactionAmount) structs.EventTransfer {
+		return structs.EventTransfer{Account: structs.Account{ID: acc}, Amounts: amounts}
+	}
+	swapLog := func(recipient string) string {
+		return `{"events": [
+			{"type": "swap", "attributes": [
+				{"key": "offer", "value": "1000000uusd"}, {"key": "trader", "value": "` + traderStr + `"}, {"key": "recipient", "value": "` + recipient + `"},
+				{"key": "swap_coin", "value": "1180000ukrw"}, {"key": "swap_fee", "value": "3540.000000000000000000ukrw"}]},
+			{"type": "transfer", "attributes": [
+				{"key": "recipient", "value": "` + marketAddr + `"}, {"key": "sender", "value": "` + traderStr + `"}, {"key": "amount", "value": "1000000uusd"},
+				{"key": "recipient", "value": "` + recipient + `"}, {"key": "sender", "value": "` + marketAddr + `"}, {"key": "amount", "value": "1180000ukrw"}]}]}`
+	}
+	offer := coin("1000000uusd")
+	ask := coin("1180000ukrw")
+
+	tests := []struct {
+		name          string
+		msg           sdk.Msg
+		log           string
+		wantSender    []structs.EventTransfer
+		wantRecipient []structs.EventTransfer
+		wantSwap      []structs.EventTransfer
+		wantSend      []structs.EventTransfer
+	}{
+		{
+			name:       "swap",
+			msg:        market.NewMsgSwap(trader, sdk.NewInt64Coin("uusd", 1000000), "ukrw"),
+			log:        swapLog(traderStr),
+			wantSender: []structs.EventTransfer{transfer(traderStr, offer, ask)},
+			wantSwap:   []structs.EventTransfer{transfer(traderStr, ask)},
+			wantSend:   []structs.EventTransfer{transfer(marketAddr, offer), transfer(traderStr, ask)},
+		},
+		{
+			name:          "swapsend recipient is only the receiving account",
+			msg:           market.NewMsgSwapSend(trader, to, sdk.NewInt64Coin("uusd", 1000000), "ukrw"),
+			log:           swapLog(toStr),
+			wantSender:    []structs.EventTransfer{transfer(traderStr, offer, ask)},
+			wantRecipient: []structs.EventTransfer{transfer(toStr, ask)},
+			wantSwap:      []structs.EventTransfer{transfer(toStr, ask)},
+			wantSend:      []structs.EventTransfer{transfer(marketAddr, offer), transfer(toStr, ask)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logf := types.LogFormat{}
+			require.NoError(t, json.Unmarshal([]byte(tt.log), &logf))
+
+			var se structs.SubsetEvent
+			var err error
+			switch tt.msg.Type() {
+			case "swap":
+				se, err = MarketSwapToSub(tt.msg, logf)
+			case "swapsend":
+				se, err = MarketSwapSendToSub(tt.msg, logf)
+			}
+			require.NoError(t, err)
+			require.Equal(t, []string{tt.msg.Type()}, se.Type)
+			require.Equal(t, offer, se.Amount["offer"])
+			require.Equal(t, ask, se.Amount["ask"])
+			require.Equal(t, coin("3540.000000000000000000ukrw"), se.Amount["swap_fee"])
+			require.Equal(t, tt.wantSender, se.Sender)
+			require.Equal(t, tt.wantRecipient, se.Recipient)
+			// transfers of log don't overwrite the swap result
+			require.Equal(t, tt.wantSwap, se.Transfers["swap"])
+			require.Equal(t, tt.wantSend, se.Transfers["send"])
+		})
+	}
+}
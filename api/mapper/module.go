@@ -0,0 +1,23 @@
+package mapper
+
+import (
+	"github.com/terra-project/core/x/distribution"
+	"github.com/terra-project/core/x/staking"
+	"github.com/terra-project/core/x/supply"
+)
+
+// Addresses of module accounts taking part in delegations. They are derived, not read from the chain:
+// module account address is the first 20 bytes of sha256 of the module name (supply.NewModuleAddress),
+// the same function the supply keeper of terra core uses when it creates module accounts. It depends only on
+// the name, so the addresses are the same on every columbus chain and don't change with height
+var (
+	distributionModuleAddr = ModuleAddress(distribution.ModuleName)
+	bondedPoolAddr         = ModuleAddress(staking.BondedPoolName)
+	notBondedPoolAddr      = ModuleAddress(staking.NotBondedPoolName)
+)
+
+// ModuleAddress returns account address of chain module (eg. `fee_collector`, `market`), derived from its name
+func ModuleAddress(name string) string {
+	addr, _ := AccAddress(supply.NewModuleAddress(name))
+	return addr
+}
@@ -0,0 +1,35 @@
+package mapper
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestModuleAddress checks derived addresses against known module accounts of columbus-3 and columbus-4
+// (`ModuleAccount` type with the module name in lcd `/auth/accounts/<addr>`). Expected values are not
+// recorded responses, they guard the derivation against changes of supply.NewModuleAddress or bech32 prefix
+func TestModuleAddress(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"distribution", "terra1jv65s3grqf6v6jl3dp4t6c9t9rk99cd8pm7utl"},
+		{"fee_collector", "terra17xpfvakm2amg962yls6f84z3kell8c5lkaeqfa"},
+		{"bonded_tokens_pool", "terra1fl48vsnmsdzcv85q5d2q4z5ajdha8yu3nln0mh"},
+		{"not_bonded_tokens_pool", "terra1tygms3xhhs3yv487phx3dw4a95jn7t7l8l07dr"},
+		{"market", "terra1untf85jwv3kt0puyyc39myxjvplagr3wstgs5s"},
+		{"oracle", "terra1jgp27m8fykex4e4jtt0l7ze8q528ux2lh4zh0f"},
+		{"treasury", "terra1vmafl8f3s6uuzwnxkqz0eza47v6ecn0t0yeca7"},
+		{"gov", "terra10d07y265gmmuvt4z0w9aw880jnsr700juxf95n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, ModuleAddress(tt.name))
+		})
+	}
+
+	require.Equal(t, "terra1jv65s3grqf6v6jl3dp4t6c9t9rk99cd8pm7utl", distributionModuleAddr)
+	require.Equal(t, "terra1fl48vsnmsdzcv85q5d2q4z5ajdha8yu3nln0mh", bondedPoolAddr)
+	require.Equal(t, "terra1tygms3xhhs3yv487phx3dw4a95jn7t7l8l07dr", notBondedPoolAddr)
+}
@@ -7,9 +7,7 @@ import (
 	"github.com/figment-networks/indexer-manager/structs"
 
 	sdk "github.com/cosmos/cosmos-sdk/types"
-	"github.com/tendermint/tendermint/libs/bech32"
 
-	"github.com/terra-project/core/types/util"
 	"github.com/terra-project/core/x/msgauth"
 )
 
@@ -20,7 +18,7 @@ func MsgauthExecAuthorizedToSub(msg sdk.Msg) (se structs.SubsetEvent, msgs []sdk
 	}
 	bech32Addr := ""
 	if !execAuthorized.Grantee.Empty() {
-		bech32Addr, err = bech32.ConvertAndEncode(util.Bech32PrefixAccAddr, execAuthorized.Grantee.Bytes())
+		bech32Addr, err = AccAddress(execAuthorized.Grantee)
 	}
 
 	return structs.SubsetEvent{
@@ -51,14 +49,14 @@ func MsgauthGrantAuthorizationToSub(msg sdk.Msg) (se structs.SubsetEvent, err er
 
 	grantAuthorized.Period.Seconds()
 	if !grantAuthorized.Grantee.Empty() {
-		bech32Addr, err := bech32.ConvertAndEncode(util.Bech32PrefixAccAddr, grantAuthorized.Grantee.Bytes())
+		bech32Addr, err := AccAddress(grantAuthorized.Grantee)
 		if err != nil {
 			return se, fmt.Errorf("error converting ValidatorAddress: %w", err)
 		}
 		subev.Node["grantee"] = []structs.Account{{ID: bech32Addr}}
 	}
 	if !grantAuthorized.Grantee.Empty() {
-		bech32Addr, err := bech32.ConvertAndEncode(util.Bech32PrefixAccAddr, grantAuthorized.Granter.Bytes())
+		bech32Addr, err := AccAddress(grantAuthorized.Granter)
 		if err != nil {
 			return se, fmt.Errorf("error converting ValidatorAddress: %w", err)
 		}
@@ -83,14 +81,14 @@ func MsgauthRevokeAuthorizationToSub(msg sdk.Msg) (se structs.SubsetEvent, err e
 	}
 
 	if !revokeAuthorized.Grantee.Empty() {
-		bech32Addr, err := bech32.ConvertAndEncode(util.Bech32PrefixAccAddr, revokeAuthorized.Grantee.Bytes())
+		bech32Addr, err := AccAddress(revokeAuthorized.Grantee)
 		if err != nil {
 			return se, fmt.Errorf("error converting ValidatorAddress: %w", err)
 		}
 		subev.Node["grantee"] = []structs.Account{{ID: bech32Addr}}
 	}
 	if !revokeAuthorized.Grantee.Empty() {
-		bech32Addr, err := bech32.ConvertAndEncode(util.Bech32PrefixAccAddr, revokeAuthorized.Granter.Bytes())
+		bech32Addr, err := AccAddress(revokeAuthorized.Granter)
 		if err != nil {
 			return se, fmt.Errorf("error converting ValidatorAddress: %w", err)
 		}
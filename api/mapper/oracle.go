@@ -2,15 +2,12 @@ package mapper
 
 import (
 	"errors"
-	"math/big"
 	"strings"
 
 	"github.com/figment-networks/indexer-manager/structs"
 
 	sdk "github.com/cosmos/cosmos-sdk/types"
-	"github.com/tendermint/tendermint/libs/bech32"
 
-	"github.com/terra-project/core/types/util"
 	"github.com/terra-project/core/x/oracle"
 )
 
@@ -27,29 +24,16 @@ func OracleExchangeRateVoteToSub(msg sdk.Msg) (se structs.SubsetEvent, err error
 	se.Node = map[string][]structs.Account{}
 
 	if !exrv.Validator.Empty() {
-		validatorBech32Addr, _ := bech32.ConvertAndEncode(util.Bech32PrefixValAddr, exrv.Validator.Bytes())
+		validatorBech32Addr, _ := ValAddress(exrv.Validator)
 		se.Node["validator"] = []structs.Account{{ID: validatorBech32Addr}}
 	}
 
 	if !exrv.Feeder.Empty() {
-		feederBech32Addr, _ := bech32.ConvertAndEncode(util.Bech32PrefixAccAddr, exrv.Feeder.Bytes())
+		feederBech32Addr, _ := AccAddress(exrv.Feeder)
 		se.Node["feeder"] = []structs.Account{{ID: feederBech32Addr}}
 	}
 
-	excRt := structs.TransactionAmount{
-		Currency: exrv.Denom,
-	}
-
-	// (lukanus): Saddly SDK int is not normal big.Int but weirdly formated one with enforced precision saved outside of the structure
-	// the safest way would be to convert that to string using library rules and then convert it back to regular big.Int
-	exchangeRateIntString := exrv.ExchangeRate.String()
-
-	if exrv.ExchangeRate.Int != nil {
-		excRt.Numeric = big.NewInt(0)
-		excRt.Numeric.SetString(exchangeRateIntString, 10)
-		excRt.Text = exchangeRateIntString
-	}
-	se.Amount = map[string]structs.TransactionAmount{"exchangeRate": excRt}
+	se.Amount = map[string]structs.TransactionAmount{"exchangeRate": DecAmount(exrv.Denom, exrv.ExchangeRate)}
 
 	return se, nil
 }
@@ -67,12 +51,12 @@ func OracleExchangeRatePrevoteToSub(msg sdk.Msg) (se structs.SubsetEvent, er err
 	se.Node = map[string][]structs.Account{}
 
 	if !exrv.Validator.Empty() {
-		validatorBech32Addr, _ := bech32.ConvertAndEncode(util.Bech32PrefixValAddr, exrv.Validator.Bytes())
+		validatorBech32Addr, _ := ValAddress(exrv.Validator)
 		se.Node["validator"] = []structs.Account{{ID: validatorBech32Addr}}
 	}
 
 	if !exrv.Feeder.Empty() {
-		feederBech32Addr, _ := bech32.ConvertAndEncode(util.Bech32PrefixAccAddr, exrv.Feeder.Bytes())
+		feederBech32Addr, _ := AccAddress(exrv.Feeder)
 		se.Node["feeder"] = []structs.Account{{ID: feederBech32Addr}}
 	}
 
@@ -98,12 +82,12 @@ func OracleDelegateFeedConsent(msg sdk.Msg) (se structs.SubsetEvent, er error) {
 	se.Node = map[string][]structs.Account{}
 
 	if !dfc.Operator.Empty() {
-		operatorBech32Addr, _ := bech32.ConvertAndEncode(util.Bech32PrefixValAddr, dfc.Operator.Bytes())
+		operatorBech32Addr, _ := ValAddress(dfc.Operator)
 		se.Node["operator"] = []structs.Account{{ID: operatorBech32Addr}}
 	}
 
 	if !dfc.Delegate.Empty() {
-		feederBech32Addr, _ := bech32.ConvertAndEncode(util.Bech32PrefixAccAddr, dfc.Delegate.Bytes())
+		feederBech32Addr, _ := AccAddress(dfc.Delegate)
 		se.Node["delegate"] = []structs.Account{{ID: feederBech32Addr}}
 	}
 
@@ -123,12 +107,12 @@ func OracleAggregateExchangeRatePrevoteToSub(msg sdk.Msg) (se structs.SubsetEven
 	se.Node = map[string][]structs.Account{}
 
 	if !exrv.Validator.Empty() {
-		validatorBech32Addr, _ := bech32.ConvertAndEncode(util.Bech32PrefixValAddr, exrv.Validator.Bytes())
+		validatorBech32Addr, _ := ValAddress(exrv.Validator)
 		se.Node["validator"] = []structs.Account{{ID: validatorBech32Addr}}
 	}
 
 	if !exrv.Feeder.Empty() {
-		feederBech32Addr, _ := bech32.ConvertAndEncode(util.Bech32PrefixAccAddr, exrv.Feeder.Bytes())
+		feederBech32Addr, _ := AccAddress(exrv.Feeder)
 		se.Node["feeder"] = []structs.Account{{ID: feederBech32Addr}}
 	}
 
@@ -140,22 +124,22 @@ func OracleAggregateExchangeRatePrevoteToSub(msg sdk.Msg) (se structs.SubsetEven
 func OracleAggregateExchangeRateVoteToSub(msg sdk.Msg) (se structs.SubsetEvent, err error) {
 	exrv, ok := msg.(oracle.MsgAggregateExchangeRateVote)
 	if !ok {
-		return se, errors.New("Not a AggregateExchangeRatePrevote type")
+		return se, errors.New("Not a AggregateExchangeRateVote type")
 	}
 	se = structs.SubsetEvent{
-		Type:   []string{"aggregateexchangerateprevote"},
+		Type:   []string{"aggregateexchangeratevote"},
 		Module: "oracle",
 	}
 
 	se.Node = map[string][]structs.Account{}
 
 	if !exrv.Validator.Empty() {
-		validatorBech32Addr, _ := bech32.ConvertAndEncode(util.Bech32PrefixValAddr, exrv.Validator.Bytes())
+		validatorBech32Addr, _ := ValAddress(exrv.Validator)
 		se.Node["validator"] = []structs.Account{{ID: validatorBech32Addr}}
 	}
 
 	if !exrv.Feeder.Empty() {
-		feederBech32Addr, _ := bech32.ConvertAndEncode(util.Bech32PrefixAccAddr, exrv.Feeder.Bytes())
+		feederBech32Addr, _ := AccAddress(exrv.Feeder)
 		se.Node["feeder"] = []structs.Account{{ID: feederBech32Addr}}
 	}
 
@@ -7,8 +7,6 @@ import (
 	"github.com/figment-networks/indexer-manager/structs"
 
 	sdk "github.com/cosmos/cosmos-sdk/types"
-	"github.com/tendermint/tendermint/libs/bech32"
-	"github.com/terra-project/core/types/util"
 	"github.com/terra-project/core/x/slashing"
 )
 
@@ -18,7 +16,7 @@ func SlashingUnjailToSub(msg sdk.Msg) (se structs.SubsetEvent, er error) {
 		return se, errors.New("Not a unjail type")
 	}
 
-	bech32ValAddr, err := bech32.ConvertAndEncode(util.Bech32PrefixValAddr, unjail.ValidatorAddr.Bytes())
+	bech32ValAddr, err := ValAddress(unjail.ValidatorAddr)
 	if err != nil {
 		return se, fmt.Errorf("error converting ValidatorAddress: %w", err)
 	}
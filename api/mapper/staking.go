@@ -8,25 +8,21 @@ import (
 	"github.com/figment-networks/terra-worker/api/types"
 
 	sdk "github.com/cosmos/cosmos-sdk/types"
-	"github.com/tendermint/tendermint/libs/bech32"
-	"github.com/terra-project/core/types/util"
 	"github.com/terra-project/core/x/staking"
 )
 
-const unbondedTokensPoolAddr = "terra1tygms3xhhs3yv487phx3dw4a95jn7t7l8l07dr"
-
 func StakingUndelegateToSub(msg sdk.Msg, logf types.LogFormat) (se structs.SubsetEvent, err error) {
 	u, ok := msg.(staking.MsgUndelegate)
 	if !ok {
 		return se, errors.New("Not a begin_unbonding type")
 	}
 
-	bech32ValAddr, err := bech32.ConvertAndEncode(util.Bech32PrefixValAddr, u.ValidatorAddress.Bytes())
+	bech32ValAddr, err := ValAddress(u.ValidatorAddress)
 	if err != nil {
 		return se, fmt.Errorf("error converting ValidatorAddress: %w", err)
 	}
 
-	bech32DelAddr, err := bech32.ConvertAndEncode(util.Bech32PrefixAccAddr, u.DelegatorAddress.Bytes())
+	bech32DelAddr, err := AccAddress(u.DelegatorAddress)
 	if err != nil {
 		return se, fmt.Errorf("error converting DelegatorAddress: %w", err)
 	}
@@ -39,15 +35,11 @@ func StakingUndelegateToSub(msg sdk.Msg, logf types.LogFormat) (se structs.Subse
 			"validator": {{ID: bech32ValAddr}},
 		},
 		Amount: map[string]structs.TransactionAmount{
-			"undelegate": {
-				Currency: u.Amount.Denom,
-				Numeric:  u.Amount.Amount.BigInt(),
-				Text:     u.Amount.String(),
-			},
+			"undelegate": CoinAmount(u.Amount),
 		},
 	}
 
-	err = produceTransfers(&se, "reward", unbondedTokensPoolAddr, logf)
+	err = produceRewardTransfers(&se, logf, bech32DelAddr, bech32ValAddr)
 	return se, err
 }
 
@@ -57,12 +49,12 @@ func StakingDelegateToSub(msg sdk.Msg, logf types.LogFormat) (se structs.SubsetE
 		return se, errors.New("Not a delegate type")
 	}
 
-	bech32ValAddr, err := bech32.ConvertAndEncode(util.Bech32PrefixValAddr, d.ValidatorAddress.Bytes())
+	bech32ValAddr, err := ValAddress(d.ValidatorAddress)
 	if err != nil {
 		return se, fmt.Errorf("error converting ValidatorAddress: %w", err)
 	}
 
-	bech32DelAddr, err := bech32.ConvertAndEncode(util.Bech32PrefixAccAddr, d.DelegatorAddress.Bytes())
+	bech32DelAddr, err := AccAddress(d.DelegatorAddress)
 	if err != nil {
 		return se, fmt.Errorf("error converting DelegatorAddress: %w", err)
 	}
@@ -75,15 +67,11 @@ func StakingDelegateToSub(msg sdk.Msg, logf types.LogFormat) (se structs.SubsetE
 			"validator": {{ID: bech32ValAddr}},
 		},
 		Amount: map[string]structs.TransactionAmount{
-			"delegate": {
-				Currency: d.Amount.Denom,
-				Numeric:  d.Amount.Amount.BigInt(),
-				Text:     d.Amount.String(),
-			},
+			"delegate": CoinAmount(d.Amount),
 		},
 	}
 
-	err = produceTransfers(&se, "reward", "", logf)
+	err = produceRewardTransfers(&se, logf, bech32DelAddr, bech32ValAddr)
 	return se, err
 }
 
@@ -93,17 +81,17 @@ func StakingBeginRedelegateToSub(msg sdk.Msg, logf types.LogFormat) (se structs.
 		return se, errors.New("Not a begin_redelegate type")
 	}
 
-	bech32ValDstAddr, err := bech32.ConvertAndEncode(util.Bech32PrefixValAddr, br.ValidatorDstAddress.Bytes())
+	bech32ValDstAddr, err := ValAddress(br.ValidatorDstAddress)
 	if err != nil {
 		return se, fmt.Errorf("error converting ValidatorAddress: %w", err)
 	}
 
-	bech32ValSrcAddr, err := bech32.ConvertAndEncode(util.Bech32PrefixValAddr, br.ValidatorSrcAddress.Bytes())
+	bech32ValSrcAddr, err := ValAddress(br.ValidatorSrcAddress)
 	if err != nil {
 		return se, fmt.Errorf("error converting ValidatorAddress: %w", err)
 	}
 
-	bech32DelAddr, err := bech32.ConvertAndEncode(util.Bech32PrefixAccAddr, br.DelegatorAddress.Bytes())
+	bech32DelAddr, err := AccAddress(br.DelegatorAddress)
 	if err != nil {
 		return se, fmt.Errorf("error converting DelegatorAddress: %w", err)
 	}
@@ -117,15 +105,11 @@ func StakingBeginRedelegateToSub(msg sdk.Msg, logf types.LogFormat) (se structs.
 			"validator_source":      {{ID: bech32ValSrcAddr}},
 		},
 		Amount: map[string]structs.TransactionAmount{
-			"delegate": {
-				Currency: br.Amount.Denom,
-				Numeric:  br.Amount.Amount.BigInt(),
-				Text:     br.Amount.String(),
-			},
+			"delegate": CoinAmount(br.Amount),
 		},
 	}
 
-	err = produceTransfers(&se, "reward", "", logf)
+	err = produceRewardTransfers(&se, logf, bech32DelAddr, bech32ValSrcAddr, bech32ValDstAddr)
 	return se, err
 }
 
@@ -135,12 +119,12 @@ func StakingCreateValidatorToSub(msg sdk.Msg) (se structs.SubsetEvent, err error
 		return se, errors.New("Not a create_validator type")
 	}
 
-	bech32ValAddr, err := bech32.ConvertAndEncode(util.Bech32PrefixValAddr, ev.ValidatorAddress.Bytes())
+	bech32ValAddr, err := ValAddress(ev.ValidatorAddress)
 	if err != nil {
 		return se, fmt.Errorf("error converting ValidatorAddress: %w", err)
 	}
 
-	bech32DelAddr, err := bech32.ConvertAndEncode(util.Bech32PrefixAccAddr, ev.DelegatorAddress.Bytes())
+	bech32DelAddr, err := AccAddress(ev.DelegatorAddress)
 	if err != nil {
 		return se, fmt.Errorf("error converting DelegatorAddress: %w", err)
 	}
@@ -163,27 +147,12 @@ func StakingCreateValidatorToSub(msg sdk.Msg) (se structs.SubsetEvent, err error
 			},
 		},
 		Amount: map[string]structs.TransactionAmount{
-			"self_delegation": {
-				Currency: ev.Value.Denom,
-				Numeric:  ev.Value.Amount.BigInt(),
-				Text:     ev.Value.String(),
-			},
-			"self_delegation_min": {
-				Text:    ev.MinSelfDelegation.String(),
-				Numeric: ev.MinSelfDelegation.BigInt(),
-			},
-			"commission_rate": {
-				Text:    ev.Commission.Rate.String(),
-				Numeric: ev.Commission.Rate.Int,
-			},
-			"commission_max_rate": {
-				Text:    ev.Commission.MaxRate.String(),
-				Numeric: ev.Commission.MaxRate.Int,
-			},
-			"commission_max_change_rate": {
-				Text:    ev.Commission.MaxChangeRate.String(),
-				Numeric: ev.Commission.MaxChangeRate.Int,
-			}},
+			"self_delegation":            CoinAmount(ev.Value),
+			"self_delegation_min":        IntAmount("", ev.MinSelfDelegation),
+			"commission_rate":            DecAmount("", ev.Commission.Rate),
+			"commission_max_rate":        DecAmount("", ev.Commission.MaxRate),
+			"commission_max_change_rate": DecAmount("", ev.Commission.MaxChangeRate),
+		},
 	}, err
 }
 
@@ -192,7 +161,7 @@ func StakingEditValidatorToSub(msg sdk.Msg) (se structs.SubsetEvent, err error)
 	if !ok {
 		return se, errors.New("Not a edit_validator type")
 	}
-	bech32ValAddr, err := bech32.ConvertAndEncode(util.Bech32PrefixValAddr, ev.ValidatorAddress.Bytes())
+	bech32ValAddr, err := ValAddress(ev.ValidatorAddress)
 	if err != nil {
 		return se, fmt.Errorf("error converting ValidatorAddress: %w", err)
 	}
@@ -218,17 +187,11 @@ func StakingEditValidatorToSub(msg sdk.Msg) (se structs.SubsetEvent, err error)
 	if ev.MinSelfDelegation != nil || ev.CommissionRate != nil {
 		sev.Amount = map[string]structs.TransactionAmount{}
 		if ev.MinSelfDelegation != nil {
-			sev.Amount["self_delegation_min"] = structs.TransactionAmount{
-				Text:    ev.MinSelfDelegation.String(),
-				Numeric: ev.MinSelfDelegation.BigInt(),
-			}
+			sev.Amount["self_delegation_min"] = IntAmount("", *ev.MinSelfDelegation)
 		}
 
 		if ev.CommissionRate != nil {
-			sev.Amount["commission_rate"] = structs.TransactionAmount{
-				Text:    ev.CommissionRate.String(),
-				Numeric: ev.CommissionRate.Int,
-			}
+			sev.Amount["commission_rate"] = DecAmount("", *ev.CommissionRate)
 		}
 	}
 	return sev, err
@@ -0,0 +1,151 @@
+package mapper
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/figment-networks/indexer-manager/structs"
+	"github.com/figment-networks/terra-worker/api/types"
+	"github.com/stretchr/testify/require"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/terra-project/core/x/staking"
+)
+
+func TestStakingRewardTransfers(t *testing.T) {
+	delegator := sdk.AccAddress([]byte("delegator_address_01"))
+	withdraw := sdk.AccAddress([]byte("withdraw_address_0001"))
+	src := sdk.ValAddress([]byte("validator_address_01"))
+	dst := sdk.ValAddress([]byte("validator_address_02"))
+
+	delegatorStr, _ := AccAddress(delegator)
+	withdrawStr, _ := AccAddress(withdraw)
+	srcStr, _ := ValAddress(src)
+	dstStr, _ := ValAddress(dst)
+
+	redelegate := staking.NewMsgBeginRedelegate(delegator, src, dst, sdk.NewInt64Coin("uluna", 1000))
+	reward := func(recipient string, n int64) structs.EventTransfer {
+		return structs.EventTransfer{
+			Account: structs.Account{ID: recipient},
+			Amounts: []structs.TransactionAmount{{Text: big.NewInt(n).String(), Numeric: big.NewInt(n), Currency: "uluna"}},
+		}
+	}
+
+	tests := []struct {
+		name           string
+		msg            sdk.Msg
+		log            string
+		wantTransfers  []structs.EventTransfer
+		wantValidators []string
+	}{
+		{
+			name: "withdraw_rewards with coin_received",
+			msg:  redelegate,
+			log: `{"events": [
+				{"type": "withdraw_rewards", "attributes": [
+					{"key": "amount", "value": "15uluna"}, {"key": "validator", "value": "` + srcStr + `"},
+					{"key": "amount", "value": ""}, {"key": "validator", "value": "` + dstStr + `"}]},
+				{"type": "coin_spent", "attributes": [
+					{"key": "spender", "value": "` + distributionModuleAddr + `"}, {"key": "amount", "value": "15uluna"},
+					{"key": "spender", "value": "` + bondedPoolAddr + `"}, {"key": "amount", "value": "1000uluna"}]},
+				{"type": "coin_received", "attributes": [
+					{"key": "receiver", "value": "` + withdrawStr + `"}, {"key": "amount", "value": "15uluna"},
+					{"key": "receiver", "value": "` + notBondedPoolAddr + `"}, {"key": "amount", "value": "1000uluna"}]}
+			]}`,
+			wantTransfers:  []structs.EventTransfer{reward(withdrawStr, 15)},
+			wantValidators: []string{srcStr},
+		},
+		{
+			name: "coin_received matched by amount of distribution coin_spent",
+			msg:  staking.NewMsgDelegate(delegator, src, sdk.NewInt64Coin("uluna", 1000)),
+			log: `{"events": [
+				{"type": "coin_spent", "attributes": [
+					{"key": "spender", "value": "` + delegatorStr + `"}, {"key": "amount", "value": "1000uluna"},
+					{"key": "spender", "value": "` + distributionModuleAddr + `"}, {"key": "amount", "value": "15uluna"}]},
+				{"type": "coin_received", "attributes": [
+					{"key": "receiver", "value": "` + delegatorStr + `"}, {"key": "amount", "value": "15uluna"},
+					{"key": "receiver", "value": "` + bondedPoolAddr + `"}, {"key": "amount", "value": "1000uluna"}]}
+			]}`,
+			wantTransfers:  []structs.EventTransfer{reward(delegatorStr, 15)},
+			wantValidators: []string{srcStr},
+		},
+		{
+			name: "principal of the same amount as reward",
+			msg:  staking.NewMsgDelegate(delegator, src, sdk.NewInt64Coin("uluna", 15)),
+			log: `{"events": [
+				{"type": "coin_spent", "attributes": [
+					{"key": "spender", "value": "` + delegatorStr + `"}, {"key": "amount", "value": "15uluna"},
+					{"key": "spender", "value": "` + distributionModuleAddr + `"}, {"key": "amount", "value": "15uluna"}]},
+				{"type": "coin_received", "attributes": [
+					{"key": "receiver", "value": "` + bondedPoolAddr + `"}, {"key": "amount", "value": "15uluna"},
+					{"key": "receiver", "value": "` + withdrawStr + `"}, {"key": "amount", "value": "15uluna"}]}
+			]}`,
+			wantTransfers:  []structs.EventTransfer{reward(withdrawStr, 15)},
+			wantValidators: []string{srcStr},
+		},
+		{
+			name: "withdraw_rewards without payouts",
+			msg:  redelegate,
+			log: `{"events": [{"type": "withdraw_rewards", "attributes": [
+				{"key": "amount", "value": "15uluna"}, {"key": "validator", "value": "` + srcStr + `"},
+				{"key": "amount", "value": "7uluna"}, {"key": "validator", "value": "` + dstStr + `"}]}]}`,
+			wantTransfers:  []structs.EventTransfer{reward(delegatorStr, 15), reward(delegatorStr, 7)},
+			wantValidators: []string{srcStr, dstStr},
+		},
+		{
+			name: "transfers of distribution module",
+			msg:  redelegate,
+			log: `{"events": [{"type": "transfer", "attributes": [
+				{"key": "recipient", "value": "` + notBondedPoolAddr + `"}, {"key": "sender", "value": "` + bondedPoolAddr + `"}, {"key": "amount", "value": "1000uluna"},
+				{"key": "recipient", "value": "` + delegatorStr + `"}, {"key": "sender", "value": "` + distributionModuleAddr + `"}, {"key": "amount", "value": "15uluna"},
+				{"key": "recipient", "value": "` + delegatorStr + `"}, {"key": "sender", "value": "` + distributionModuleAddr + `"}, {"key": "amount", "value": "7uluna"}]}]}`,
+			wantTransfers:  []structs.EventTransfer{reward(delegatorStr, 15), reward(delegatorStr, 7)},
+			wantValidators: []string{srcStr, dstStr},
+		},
+		{
+			name: "single payout of redelegation",
+			msg:  redelegate,
+			log: `{"events": [{"type": "transfer", "attributes": [
+				{"key": "recipient", "value": "` + delegatorStr + `"}, {"key": "sender", "value": "` + distributionModuleAddr + `"}, {"key": "amount", "value": "15uluna"}]}]}`,
+			wantTransfers:  []structs.EventTransfer{reward(delegatorStr, 15)},
+			wantValidators: []string{""},
+		},
+		{
+			name: "legacy transfers without sender",
+			msg:  staking.NewMsgUndelegate(delegator, src, sdk.NewInt64Coin("uluna", 1000)),
+			log: `{"events": [{"type": "transfer", "attributes": [
+				{"key": "recipient", "value": "` + notBondedPoolAddr + `"}, {"key": "amount", "value": "1000uluna"},
+				{"key": "recipient", "value": "` + delegatorStr + `"}, {"key": "amount", "value": "15uluna"}]}]}`,
+			wantTransfers:  []structs.EventTransfer{reward(delegatorStr, 15)},
+			wantValidators: []string{srcStr},
+		},
+		{
+			name: "no rewards",
+			msg:  staking.NewMsgDelegate(delegator, src, sdk.NewInt64Coin("uluna", 1000)),
+			log: `{"events": [{"type": "transfer", "attributes": [
+				{"key": "recipient", "value": "` + bondedPoolAddr + `"}, {"key": "sender", "value": "` + delegatorStr + `"}, {"key": "amount", "value": "1000uluna"}]}]}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logf := types.LogFormat{}
+			require.NoError(t, json.Unmarshal([]byte(tt.log), &logf))
+
+			var se structs.SubsetEvent
+			var err error
+			switch tt.msg.Type() {
+			case "delegate":
+				se, err = StakingDelegateToSub(tt.msg, logf)
+			case "begin_unbonding":
+				se, err = StakingUndelegateToSub(tt.msg, logf)
+			case "begin_redelegate":
+				se, err = StakingBeginRedelegateToSub(tt.msg, logf)
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.wantTransfers, se.Transfers["reward"])
+			require.Equal(t, tt.wantValidators, se.Additional["reward_validator"])
+		})
+	}
+}
@@ -1,43 +1,71 @@
 package mapper
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"strconv"
 
 	"github.com/figment-networks/indexer-manager/structs"
-
-	"github.com/tendermint/tendermint/libs/bech32"
+	"github.com/figment-networks/terra-worker/api/types"
 
 	sdk "github.com/cosmos/cosmos-sdk/types"
 
-	"github.com/terra-project/core/types/util"
 	"github.com/terra-project/core/x/wasm"
 )
 
-func WasmExecuteContractToSub(msg sdk.Msg) (se structs.SubsetEvent, err error) {
+// WasmByteCodeMode sets how contract code uploaded with `store_code` is returned
+type WasmByteCodeMode string
+
+const (
+	// WasmByteCodeHash sets only checksum and size of the code in subevents, code is available with GetContractCode task
+	WasmByteCodeHash WasmByteCodeMode = "hash"
+	// WasmByteCodeEmbed embeds the whole code into subevents
+	WasmByteCodeEmbed WasmByteCodeMode = "embed"
+	// WasmByteCodeOmit sets only checksum and size of the code, code is never returned
+	WasmByteCodeOmit WasmByteCodeMode = "omit"
+)
+
+// ParseWasmByteCodeMode validates mode name
+func ParseWasmByteCodeMode(s string) (WasmByteCodeMode, error) {
+	switch m := WasmByteCodeMode(s); m {
+	case WasmByteCodeHash, WasmByteCodeEmbed, WasmByteCodeOmit:
+		return m, nil
+	}
+	return "", fmt.Errorf("unknown wasm byte code mode %q (expected hash, embed or omit)", s)
+}
+
+// Options configure mapping of messages into subevents
+type Options struct {
+	// EmbedWasmByteCode embeds the whole contract code into `store_code` subevents,
+	// otherwise only the checksum and the size are set
+	EmbedWasmByteCode bool
+	// Contracts decodes calls of well known contracts in `execute_contract` subevents, nil disables decoding
+	Contracts *ContractRegistry
+}
+
+func WasmExecuteContractToSub(msg sdk.Msg, logf types.LogFormat, opts Options) (se structs.SubsetEvent, err error) {
 	ec, ok := msg.(wasm.MsgExecuteContract)
 	if !ok {
 		return se, errors.New("Not a execute_contract type")
 	}
 
-	senderBech32ValAddr, err := bech32.ConvertAndEncode(util.Bech32PrefixAccAddr, ec.Sender.Bytes())
+	senderBech32ValAddr, err := AccAddress(ec.Sender)
 	if err != nil {
 		return se, fmt.Errorf("error converting Sender address: %w", err)
 	}
 
+	contractBech32ValAddr, err := AccAddress(ec.Contract)
+	if err != nil {
+		return se, fmt.Errorf("error converting contract address: %w", err)
+	}
+
 	evt := structs.EventTransfer{
 		Account: structs.Account{ID: senderBech32ValAddr},
 	}
 	if len(ec.Coins) > 0 {
-		evt.Amounts = []structs.TransactionAmount{}
-		for _, coin := range ec.Coins {
-			evt.Amounts = append(evt.Amounts, structs.TransactionAmount{
-				Currency: coin.Denom,
-				Numeric:  coin.Amount.BigInt(),
-				Text:     coin.Amount.String(),
-			})
-		}
+		evt.Amounts = CoinsAmounts(ec.Coins)
 	}
 
 	b, err := ec.ExecuteMsg.MarshalJSON()
@@ -45,29 +73,63 @@ func WasmExecuteContractToSub(msg sdk.Msg) (se structs.SubsetEvent, err error) {
 		return se, fmt.Errorf("error converting ExecuteMsg: %w", err)
 	}
 
-	return structs.SubsetEvent{
-		Type:       []string{"execute_contract"},
-		Module:     "wasm",
-		Sender:     []structs.EventTransfer{evt},
-		Additional: map[string][]string{"execute_message": {string(b)}},
-	}, err
+	se = structs.SubsetEvent{
+		Type:   []string{"execute_contract"},
+		Module: "wasm",
+		Sender: []structs.EventTransfer{evt},
+		Node: map[string][]structs.Account{
+			"contract": {{ID: contractBech32ValAddr}},
+		},
+		Additional: map[string][]string{
+			"contract":        {contractBech32ValAddr},
+			"execute_message": {string(b)},
+		},
+	}
+
+	isCW20, err := opts.Contracts.IsCW20(contractBech32ValAddr)
+	if err != nil {
+		// the call is left undecoded, ContractLookupError is returned with the subevent
+		return se, err
+	}
+
+	if action, body := cw20FromMessage(ec.ExecuteMsg); isCW20 && body != nil {
+		se.Additional["cw20_action"] = []string{action}
+		if am, err := ParseAmount(contractBech32ValAddr, body.Amount); err == nil {
+			se.Amount = map[string]structs.TransactionAmount{"cw20": am}
+		}
+		if body.Owner != "" {
+			se.Node["owner"] = []structs.Account{{ID: body.Owner}}
+		}
+		if body.Recipient != "" {
+			se.Node["recipient"] = []structs.Account{{ID: body.Recipient}}
+		}
+		if body.Contract != "" {
+			se.Node["recipient"] = []structs.Account{{ID: body.Contract}}
+		}
+	}
+
+	produceCW20Transfers(&se, logf, opts.Contracts)
+
+	se.Sub, err = opts.Contracts.Decode(contractBech32ValAddr, ec.ExecuteMsg, logf)
+	if err != nil {
+		return se, fmt.Errorf("error decoding contract %s call: %w", contractBech32ValAddr, err)
+	}
+
+	return se, err
 }
 
-func WasmStoreCodeToSub(msg sdk.Msg) (se structs.SubsetEvent, err error) {
+func WasmStoreCodeToSub(msg sdk.Msg, logf types.LogFormat, opts Options) (se structs.SubsetEvent, err error) {
 	sc, ok := msg.(wasm.MsgStoreCode)
 	if !ok {
 		return se, errors.New("Not a store_code type")
 	}
 
-	senderBech32ValAddr, err := bech32.ConvertAndEncode(util.Bech32PrefixAccAddr, sc.Sender.Bytes())
+	senderBech32ValAddr, err := AccAddress(sc.Sender)
 	if err != nil {
 		return se, fmt.Errorf("error converting Sender address: %w", err)
 	}
 
-	b, err := sc.WASMByteCode.MarshalJSON()
-	if err != nil {
-		return se, fmt.Errorf("error converting ExecuteMsg: %w", err)
-	}
+	checksum := sha256.Sum256(sc.WASMByteCode)
 
 	se = structs.SubsetEvent{
 		Type:   []string{"store_code"},
@@ -75,7 +137,22 @@ func WasmStoreCodeToSub(msg sdk.Msg) (se structs.SubsetEvent, err error) {
 		Sender: []structs.EventTransfer{
 			{Account: structs.Account{ID: senderBech32ValAddr}},
 		},
-		Additional: map[string][]string{"wasm_byte_code": {string(b)}},
+		Additional: map[string][]string{
+			"wasm_byte_code_sha256": {hex.EncodeToString(checksum[:])},
+			"wasm_byte_code_size":   {strconv.Itoa(len(sc.WASMByteCode))},
+		},
+	}
+
+	if opts.EmbedWasmByteCode {
+		b, err := sc.WASMByteCode.MarshalJSON()
+		if err != nil {
+			return se, fmt.Errorf("error converting WASMByteCode: %w", err)
+		}
+		se.Additional["wasm_byte_code"] = []string{string(b)}
+	}
+
+	if codeID := logAttribute(logf, "store_code", "code_id"); codeID != "" {
+		se.Additional["code_id"] = []string{codeID}
 	}
 
 	return se, err
@@ -87,17 +164,17 @@ func WasmMsgUpdateContractOwnerToSub(msg sdk.Msg) (se structs.SubsetEvent, err e
 		return se, errors.New("Not a update_contract_owner type")
 	}
 
-	contractBech32ValAddr, err := bech32.ConvertAndEncode(util.Bech32PrefixAccAddr, uco.Contract.Bytes())
+	contractBech32ValAddr, err := AccAddress(uco.Contract)
 	if err != nil {
 		return se, fmt.Errorf("error converting cntract address: %w", err)
 	}
 
-	newOwnerBech32ValAddr, err := bech32.ConvertAndEncode(util.Bech32PrefixAccAddr, uco.NewOwner.Bytes())
+	newOwnerBech32ValAddr, err := AccAddress(uco.NewOwner)
 	if err != nil {
 		return se, fmt.Errorf("error converting new owner address: %w", err)
 	}
 
-	ownerBech32ValAddr, err := bech32.ConvertAndEncode(util.Bech32PrefixAccAddr, uco.Owner.Bytes())
+	ownerBech32ValAddr, err := AccAddress(uco.Owner)
 	if err != nil {
 		return se, fmt.Errorf("error converting owner address: %w", err)
 	}
@@ -113,13 +190,13 @@ func WasmMsgUpdateContractOwnerToSub(msg sdk.Msg) (se structs.SubsetEvent, err e
 	}, err
 }
 
-func WasmMsgInstantiateContractToSub(msg sdk.Msg) (se structs.SubsetEvent, err error) {
+func WasmMsgInstantiateContractToSub(msg sdk.Msg, logf types.LogFormat) (se structs.SubsetEvent, err error) {
 	ic, ok := msg.(wasm.MsgInstantiateContract)
 	if !ok {
 		return se, errors.New("Not a instantiate_contract type")
 	}
 
-	ownerBech32ValAddr, err := bech32.ConvertAndEncode(util.Bech32PrefixAccAddr, ic.Owner.Bytes())
+	ownerBech32ValAddr, err := AccAddress(ic.Owner)
 	if err != nil {
 		return se, fmt.Errorf("error converting owner address: %w", err)
 	}
@@ -148,13 +225,14 @@ func WasmMsgInstantiateContractToSub(msg sdk.Msg) (se structs.SubsetEvent, err e
 		Amount: map[string]structs.TransactionAmount{},
 	}
 
+	if contract := logAttribute(logf, "instantiate_contract", "contract_address"); contract != "" {
+		se.Node["contract"] = []structs.Account{{ID: contract}}
+		se.Additional["contract_address"] = []string{contract}
+	}
+
 	if len(ic.InitCoins) > 0 {
 		for i, coin := range ic.InitCoins {
-			se.Amount["init_coin_"+strconv.Itoa(i)] = structs.TransactionAmount{
-				Currency: coin.Denom,
-				Numeric:  coin.Amount.BigInt(),
-				Text:     coin.Amount.String(),
-			}
+			se.Amount["init_coin_"+strconv.Itoa(i)] = CoinAmount(coin)
 		}
 	}
 
@@ -167,12 +245,12 @@ func WasmMsgMigrateContractToSub(msg sdk.Msg) (se structs.SubsetEvent, err error
 		return se, errors.New("Not a migrate_contract type")
 	}
 
-	contractBech32ValAddr, err := bech32.ConvertAndEncode(util.Bech32PrefixAccAddr, mc.Contract.Bytes())
+	contractBech32ValAddr, err := AccAddress(mc.Contract)
 	if err != nil {
 		return se, fmt.Errorf("error converting contract address: %w", err)
 	}
 
-	ownerBech32ValAddr, err := bech32.ConvertAndEncode(util.Bech32PrefixAccAddr, mc.Owner.Bytes())
+	ownerBech32ValAddr, err := AccAddress(mc.Owner)
 	if err != nil {
 		return se, fmt.Errorf("error converting owner address: %w", err)
 	}
@@ -195,3 +273,18 @@ func WasmMsgMigrateContractToSub(msg sdk.Msg) (se structs.SubsetEvent, err error
 	}, err
 
 }
+
+// logAttribute returns first value of attribute of given log event
+func logAttribute(logf types.LogFormat, eventType, key string) string {
+	for _, ev := range logf.Events {
+		if ev.Type != eventType || ev.Attributes == nil {
+			continue
+		}
+		for _, kv := range ev.Attributes.Ordered {
+			if kv.Key == key {
+				return kv.Value
+			}
+		}
+	}
+	return ""
+}
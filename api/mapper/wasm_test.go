@@ -0,0 +1,175 @@
+package mapper
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+
+	"github.com/figment-networks/indexer-manager/structs"
+	"github.com/figment-networks/terra-worker/api/types"
+	"github.com/stretchr/testify/require"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/terra-project/core/x/wasm"
+)
+
+func TestParseWasmByteCodeMode(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    WasmByteCodeMode
+		wantErr bool
+	}{
+		{"hash", WasmByteCodeHash, false},
+		{"embed", WasmByteCodeEmbed, false},
+		{"omit", WasmByteCodeOmit, false},
+		{"embedd", "", true},
+		{"", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := ParseWasmByteCodeMode(tt.value)
+			require.Equal(t, tt.wantErr, err != nil, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestWasmStoreCodeToSub(t *testing.T) {
+	msg := wasm.NewMsgStoreCode(sdk.AccAddress("sender______________"), []byte("code"))
+
+	se, err := WasmStoreCodeToSub(msg, types.LogFormat{}, Options{})
+	require.NoError(t, err)
+	require.Equal(t, []string{"4"}, se.Additional["wasm_byte_code_size"])
+	require.NotContains(t, se.Additional, "wasm_byte_code")
+
+	se, err = WasmStoreCodeToSub(msg, types.LogFormat{}, Options{EmbedWasmByteCode: true})
+	require.NoError(t, err)
+	require.Equal(t, []string{`"Y29kZQ=="`}, se.Additional["wasm_byte_code"])
+}
+
+// cw20Contracts returns registry with contracts registered as cw20 tokens
+func cw20Contracts(t *testing.T, contracts ...string) *ContractRegistry {
+	t.Helper()
+	cr := NewContractRegistry()
+	for _, c := range contracts {
+		require.NoError(t, cr.AddContract(c, "cw20"))
+	}
+	return cr
+}
+
+func TestWasmExecuteContractToSub(t *testing.T) {
+	sender := sdk.AccAddress("sender______________")
+	contract := sdk.AccAddress("contract____________")
+	recipient := mustAccAddress(t, sdk.AccAddress("recipient___________"))
+
+	tests := []struct {
+		name          string
+		execMsg       string
+		contracts     *ContractRegistry
+		wantAction    string
+		wantRecipient string
+	}{
+		{
+			name:          "transfer of cw20 token",
+			execMsg:       `{"transfer":{"recipient":"` + recipient + `","amount":"15"}}`,
+			contracts:     cw20Contracts(t, mustAccAddress(t, contract)),
+			wantAction:    "transfer",
+			wantRecipient: recipient,
+		},
+		{
+			name:          "send of cw20 token",
+			execMsg:       `{"send":{"contract":"` + recipient + `","amount":"15","msg":"e30="}}`,
+			contracts:     cw20Contracts(t, mustAccAddress(t, contract)),
+			wantAction:    "send",
+			wantRecipient: recipient,
+		},
+		{
+			name:      "send to contract not registered as cw20",
+			execMsg:   `{"send":{"contract":"` + recipient + `","amount":"15","msg":"e30="}}`,
+			contracts: cw20Contracts(t),
+		},
+		{
+			name:    "send without registry",
+			execMsg: `{"send":{"contract":"` + recipient + `","amount":"15","msg":"e30="}}`,
+		},
+		{
+			name:      "other message of cw20 token",
+			execMsg:   `{"increase_allowance":{"spender":"` + recipient + `","amount":"15"}}`,
+			contracts: cw20Contracts(t, mustAccAddress(t, contract)),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msg := wasm.NewMsgExecuteContract(sender, contract, []byte(tt.execMsg), nil)
+			se, err := WasmExecuteContractToSub(msg, types.LogFormat{}, Options{Contracts: tt.contracts})
+			require.NoError(t, err)
+
+			if tt.wantAction == "" {
+				require.NotContains(t, se.Additional, "cw20_action")
+				require.Nil(t, se.Amount)
+				require.NotContains(t, se.Node, "recipient")
+				return
+			}
+			require.Equal(t, []string{tt.wantAction}, se.Additional["cw20_action"])
+			require.Equal(t, "15", se.Amount["cw20"].Text)
+			require.Equal(t, mustAccAddress(t, contract), se.Amount["cw20"].Currency)
+			require.Equal(t, []structs.Account{{ID: tt.wantRecipient}}, se.Node["recipient"])
+		})
+	}
+}
+
+func TestWasmExecuteContractToSub_LookupFailure(t *testing.T) {
+	msg := wasm.NewMsgExecuteContract(sdk.AccAddress("sender______________"), sdk.AccAddress("contract____________"), []byte(`{"transfer":{"recipient":"x","amount":"15"}}`), nil)
+	cr := NewContractRegistry()
+	require.NoError(t, cr.AddCodeID(4, "cw20"))
+	cr.SetCodeIDSource(&codeIDs{ids: map[string]uint64{}})
+
+	se, err := WasmExecuteContractToSub(msg, types.LogFormat{}, Options{Contracts: cr})
+	var lookupErr *ContractLookupError
+	require.True(t, errors.As(err, &lookupErr))
+	require.Equal(t, mustAccAddress(t, msg.Contract), lookupErr.Contract)
+	// call is kept undecoded
+	require.Equal(t, []string{"execute_contract"}, se.Type)
+	require.Len(t, se.Additional["execute_message"], 1)
+	require.NotContains(t, se.Additional, "cw20_action")
+}
+
+func TestProduceCW20Transfers(t *testing.T) {
+	const (
+		token = "terra15gwkyepfc6xgca5t5zefzwy42uts8l2m4g40k6"
+		other = "terra1tndcaqxkpc5ce9qee5ggqf430mr2z3pefe5wj6"
+		alice = "terra190vqdjtlpcq27xslcveglfmr4ynfwg7gxlzhjn"
+		bob   = "terra1sxmr0k8u6trd5c6eu6trzyapzux7090yhcwdln"
+	)
+	amount := func(n int64) []structs.TransactionAmount {
+		am, err := ParseAmount(token, strconv.FormatInt(n, 10))
+		require.NoError(t, err)
+		return []structs.TransactionAmount{am}
+	}
+	transfer := func(acc string, n int64) structs.EventTransfer {
+		return structs.EventTransfer{Account: structs.Account{ID: acc}, Amounts: amount(n)}
+	}
+	logf := fromContractLog(
+		"contract_address", token, "action", "transfer", "from", alice, "to", bob, "amount", "10",
+		"contract_address", token, "action", "mint", "to", bob, "amount", "20",
+		"contract_address", token, "action", "burn_from", "from", alice, "by", bob, "amount", "5",
+		// contract not registered as token reports an action of the same name
+		"contract_address", other, "action", "transfer", "from", bob, "to", alice, "amount", "30",
+	)
+
+	se := structs.SubsetEvent{}
+	produceCW20Transfers(&se, logf, cw20Contracts(t, token))
+	require.Equal(t, []structs.EventTransfer{transfer(alice, 10), transfer(alice, 5)}, se.Sender)
+	require.Equal(t, []structs.EventTransfer{transfer(bob, 10), transfer(bob, 20)}, se.Recipient)
+	require.Equal(t, map[string][]structs.EventTransfer{
+		"send": {transfer(bob, 10)},
+		"mint": {transfer(bob, 20)},
+		"burn": {transfer(alice, 5)},
+	}, se.Transfers)
+
+	// without registry no contract is a token
+	se = structs.SubsetEvent{}
+	produceCW20Transfers(&se, logf, nil)
+	require.Empty(t, se.Transfers)
+	require.Empty(t, se.Sender)
+}
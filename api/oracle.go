@@ -0,0 +1,39 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/figment-networks/indexer-manager/structs"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// GetOracleMissCounter fetches number of missed oracle votes of validator in current slash window
+func (c *Client) GetOracleMissCounter(ctx context.Context, params structs.HeightAccount) (missCount uint64, err error) {
+	var result string
+	endpoint := fmt.Sprintf("/oracle/voters/%s/miss", params.Account)
+	if err = c.getLCD(ctx, endpoint, "/oracle/voters/_/miss", params.Height, &result); err != nil {
+		return 0, err
+	}
+
+	missCount, err = strconv.ParseUint(result, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("could not parse miss counter %q: %w", result, err)
+	}
+	return missCount, nil
+}
+
+// GetOracleFeeder fetches address of account delegated by validator to submit oracle votes
+func (c *Client) GetOracleFeeder(ctx context.Context, params structs.HeightAccount) (feeder string, err error) {
+	endpoint := fmt.Sprintf("/oracle/voters/%s/feeder", params.Account)
+	err = c.getLCD(ctx, endpoint, "/oracle/voters/_/feeder", params.Height, &feeder)
+	return feeder, err
+}
+
+// GetExchangeRates fetches exchange rates of luna in every denomination quoted by oracle at given height
+func (c *Client) GetExchangeRates(ctx context.Context, height uint64) (rates sdk.DecCoins, err error) {
+	err = c.getLCD(ctx, "/oracle/denoms/exchange_rates", "/oracle/denoms/exchange_rates", height, &rates)
+	return rates, err
+}
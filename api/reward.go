@@ -10,9 +10,9 @@ import (
 	"time"
 
 	"github.com/figment-networks/indexer-manager/structs"
+	"github.com/figment-networks/terra-worker/api/mapper"
 	"github.com/figment-networks/terra-worker/api/types"
 
-	sdk "github.com/cosmos/cosmos-sdk/types"
 	"github.com/cosmos/cosmos-sdk/types/rest"
 )
 
@@ -87,16 +87,8 @@ func (c *Client) GetReward(ctx context.Context, params structs.HeightAccount) (r
 
 	for _, valReward := range result.Result.ValidatorRewards {
 		valRewards := make([]structs.TransactionAmount, 0, len(valReward.Rewards))
-
 		for _, reward := range valReward.Rewards {
-			valRewards = append(valRewards,
-				structs.TransactionAmount{
-					Text:     reward.Amount.String(),
-					Numeric:  reward.Amount.BigInt(),
-					Currency: reward.Denom,
-					Exp:      sdk.Precision,
-				},
-			)
+			valRewards = append(valRewards, mapper.DecCoinAmount(reward))
 		}
 		resp.Rewards[structs.Validator(valReward.Validator)] = valRewards
 	}
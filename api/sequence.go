@@ -0,0 +1,232 @@
+package api
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/figment-networks/indexer-manager/structs"
+	cStruct "github.com/figment-networks/indexer-manager/worker/connectivity/structs"
+	"go.uber.org/zap"
+
+	amino "github.com/tendermint/go-amino"
+	"github.com/tendermint/tendermint/crypto"
+	"github.com/terra-project/core/x/auth"
+)
+
+// DefaultSequenceSearchDepth is the number of sequences checked below the account sequence after the transaction block
+const DefaultSequenceSearchDepth = 100
+
+// Account is the account state relevant to transaction signatures
+type Account struct {
+	AccountNumber uint64
+	Sequence      uint64
+	PubKey        crypto.PubKey
+}
+
+// AccountSource provides accounts at given height
+type AccountSource interface {
+	GetAccount(ctx context.Context, address string, height uint64) (Account, error)
+}
+
+// lcdAccount holds fields common to every account type. Amino json of embedded base account is flat,
+// so it's decoded without the codec (and without checking address prefix of the global sdk config)
+type lcdAccount struct {
+	Type  string `json:"type"`
+	Value struct {
+		PubKey        json.RawMessage `json:"public_key"`
+		AccountNumber json.Number     `json:"account_number"`
+		Sequence      json.Number     `json:"sequence"`
+	} `json:"value"`
+}
+
+// GetAccount fetches account number, sequence and public key of account at given height
+func (c *Client) GetAccount(ctx context.Context, address string, height uint64) (acc Account, err error) {
+	var result lcdAccount
+	endpoint := fmt.Sprintf("/auth/accounts/%s", address)
+	if err = c.getLCD(ctx, endpoint, "/auth/accounts/_", height, &result); err != nil {
+		return acc, err
+	}
+
+	if acc.AccountNumber, err = strconv.ParseUint(result.Value.AccountNumber.String(), 10, 64); err != nil {
+		return acc, fmt.Errorf("[TERRA-API] Error parsing account number of %s: %w", address, err)
+	}
+	if acc.Sequence, err = strconv.ParseUint(result.Value.Sequence.String(), 10, 64); err != nil {
+		return acc, fmt.Errorf("[TERRA-API] Error parsing sequence of %s: %w", address, err)
+	}
+	if pk := result.Value.PubKey; len(pk) > 0 && string(pk) != "null" {
+		if err = c.cdc.UnmarshalJSON(pk, &acc.PubKey); err != nil {
+			return acc, fmt.Errorf("[TERRA-API] Error decoding public key of %s: %w", address, err)
+		}
+	}
+	return acc, nil
+}
+
+// SequenceResolver finds account numbers and sequences of transaction signers.
+// They are only a part of signed bytes, so the sequence is searched by verifying signature with sequences
+// preceding the account sequence at the transaction height (that already counts transactions of the block)
+type SequenceResolver struct {
+	source AccountSource
+	cdc    *amino.Codec
+	depth  uint64
+
+	lock      sync.Mutex
+	cache     map[accountKey]Account
+	keys      []accountKey
+	cacheSize int
+}
+
+type accountKey struct {
+	address string
+	height  uint64
+}
+
+// NewSequenceResolver is SequenceResolver constructor. At most depth sequences are checked for every signer,
+// accounts of cacheSize recent (address, height) pairs are kept
+func NewSequenceResolver(source AccountSource, cdc *amino.Codec, depth uint64, cacheSize int) *SequenceResolver {
+	if depth == 0 {
+		depth = DefaultSequenceSearchDepth
+	}
+	if cacheSize < 1 {
+		cacheSize = 1
+	}
+	return &SequenceResolver{
+		source:    source,
+		cdc:       cdc,
+		depth:     depth,
+		cache:     map[accountKey]Account{},
+		cacheSize: cacheSize,
+	}
+}
+
+// ResolveSequencesCh sets account numbers and sequences of signers of transactions passing from in to out,
+// other responses are passed unchanged. It's the enrichment stage following RawToTransactionCh
+func ResolveSequencesCh(ctx context.Context, logger *zap.Logger, r *SequenceResolver, in <-chan cStruct.OutResp, out chan<- cStruct.OutResp) {
+	EnrichCh(ctx, in, out, func(resp cStruct.OutResp) cStruct.OutResp {
+		if tx, ok := resp.Payload.(structs.Transaction); ok {
+			if err := r.ResolveTransaction(ctx, tx); err != nil {
+				logger.Error("[TERRA-API] Problem resolving sequences", zap.Error(err), zap.Uint64("height", tx.Height), zap.String("hash", tx.Hash))
+			}
+		}
+		return resp
+	})
+}
+
+// ResolveTransaction sets `account_number` and `sequence` of signers in `signers` event of transaction.
+// Signers that can't be resolved are left without them and reported in the returned error
+func (r *SequenceResolver) ResolveTransaction(ctx context.Context, tx structs.Transaction) error {
+	var subs []structs.SubsetEvent
+	for _, ev := range tx.Events {
+		if ev.Kind == SignersKind {
+			subs = ev.Sub
+		}
+	}
+	if len(subs) == 0 {
+		return nil
+	}
+	if tx.ChainID == "" {
+		return errors.New("transaction has no chain id")
+	}
+
+	stdTx := &auth.StdTx{}
+	base64Dec := base64.NewDecoder(base64.StdEncoding, strings.NewReader(string(tx.Raw)))
+	if _, err := r.cdc.UnmarshalBinaryLengthPrefixedReader(base64Dec, stdTx, 0); err != nil {
+		return fmt.Errorf("error decoding raw transaction: %w", err)
+	}
+
+	var errs []string
+	for i, sub := range subs {
+		if i >= len(stdTx.Signatures) {
+			break
+		}
+		signer := subSigner(sub)
+		acc, err := r.account(ctx, signer.Address, tx.Height)
+		if err != nil {
+			errs = append(errs, err.Error())
+			continue
+		}
+
+		sig := stdTx.Signatures[i]
+		pk := sig.PubKey
+		if pk == nil {
+			pk = acc.PubKey
+		}
+		if pk == nil {
+			errs = append(errs, fmt.Sprintf("signer %s has no public key", signer.Address))
+			continue
+		}
+
+		seq, ok := r.findSequence(tx.ChainID, stdTx, pk, sig.Signature, acc)
+		if !ok {
+			errs = append(errs, fmt.Sprintf("signature of %s doesn't match sequences %d-%d", signer.Address, sequenceFloor(acc.Sequence, r.depth), acc.Sequence))
+			continue
+		}
+		setSequence(&subs[i], &acc.AccountNumber, &seq)
+		if sig.PubKey == nil {
+			p := newPubKey(pk)
+			subs[i].Additional["pubkey_type"] = []string{p.Type}
+			if len(p.Key) > 0 {
+				subs[i].Additional["pubkey"] = []string{base64.StdEncoding.EncodeToString(p.Key)}
+			}
+		}
+	}
+
+	if len(errs) > 0 {
+		return errors.New(strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// findSequence checks signature against sign bytes with sequences from the account sequence downwards
+func (r *SequenceResolver) findSequence(chainID string, stdTx *auth.StdTx, pk crypto.PubKey, sig []byte, acc Account) (uint64, bool) {
+	floor := sequenceFloor(acc.Sequence, r.depth)
+	for seq := acc.Sequence; ; seq-- {
+		signBytes := auth.StdSignBytes(chainID, acc.AccountNumber, seq, stdTx.Fee, stdTx.Msgs, stdTx.Memo)
+		if pk.VerifyBytes(signBytes, sig) {
+			return seq, true
+		}
+		if seq == floor {
+			return 0, false
+		}
+	}
+}
+
+func sequenceFloor(sequence, depth uint64) uint64 {
+	if sequence < depth {
+		return 0
+	}
+	return sequence - depth
+}
+
+// account returns account at height, fetched accounts are cached
+func (r *SequenceResolver) account(ctx context.Context, address string, height uint64) (Account, error) {
+	key := accountKey{address, height}
+	r.lock.Lock()
+	acc, ok := r.cache[key]
+	r.lock.Unlock()
+	if ok {
+		return acc, nil
+	}
+
+	acc, err := r.source.GetAccount(ctx, address, height)
+	if err != nil {
+		return acc, fmt.Errorf("error fetching account %s at height %d: %w", address, height, err)
+	}
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if _, ok := r.cache[key]; !ok {
+		r.cache[key] = acc
+		r.keys = append(r.keys, key)
+		if len(r.keys) > r.cacheSize {
+			delete(r.cache, r.keys[0])
+			r.keys = r.keys[1:]
+		}
+	}
+	return acc, nil
+}
@@ -0,0 +1,187 @@
+package api
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"sync/atomic"
+	"testing"
+
+	"github.com/figment-networks/indexer-manager/structs"
+	"github.com/stretchr/testify/require"
+
+	"github.com/terra-project/core/app"
+)
+
+type accountSourceMock struct {
+	accounts map[string]Account
+	err      error
+	calls    int32
+}
+
+func (as *accountSourceMock) GetAccount(ctx context.Context, address string, height uint64) (Account, error) {
+	atomic.AddInt32(&as.calls, 1)
+	if as.err != nil {
+		return Account{}, as.err
+	}
+	acc, ok := as.accounts[address]
+	if !ok {
+		return acc, errors.New("account not found")
+	}
+	return acc, nil
+}
+
+func TestSequenceResolver_ResolveTransaction(t *testing.T) {
+	cdc := app.MakeCodec()
+	single := newTestSigner("single", 3, 5)
+	multi := newTestMultisigSigner("multi", 9, 0)
+
+	tests := []struct {
+		name string
+		// accounts after the transaction block
+		accounts    func() map[string]Account
+		omitPubKey  bool
+		chainID     string
+		wantSeqs    [][]string
+		wantPubKeys [][]string
+		wantErr     bool
+	}{
+		{
+			name: "transactions of signers in the same block",
+			accounts: func() map[string]Account {
+				return map[string]Account{
+					single.bech32(t): {AccountNumber: 3, Sequence: 8, PubKey: single.pubKey},
+					multi.bech32(t):  {AccountNumber: 9, Sequence: 1, PubKey: multi.pubKey},
+				}
+			},
+			chainID:  testSignChainID,
+			wantSeqs: [][]string{{"3", "5"}, {"9", "0"}},
+		},
+		{
+			name: "public key stored in account",
+			accounts: func() map[string]Account {
+				return map[string]Account{
+					single.bech32(t): {AccountNumber: 3, Sequence: 6, PubKey: single.pubKey},
+					multi.bech32(t):  {AccountNumber: 9, Sequence: 1, PubKey: multi.pubKey},
+				}
+			},
+			omitPubKey:  true,
+			chainID:     testSignChainID,
+			wantSeqs:    [][]string{{"3", "5"}, {"9", "0"}},
+			wantPubKeys: [][]string{{PubKeySecp256k1}, {PubKeyMultisig}},
+		},
+		{
+			name: "sequence beyond search depth",
+			accounts: func() map[string]Account {
+				return map[string]Account{
+					single.bech32(t): {AccountNumber: 3, Sequence: 20, PubKey: single.pubKey},
+					multi.bech32(t):  {AccountNumber: 9, Sequence: 1, PubKey: multi.pubKey},
+				}
+			},
+			chainID:  testSignChainID,
+			wantSeqs: [][]string{nil, {"9", "0"}},
+			wantErr:  true,
+		},
+		{
+			name: "different account number",
+			accounts: func() map[string]Account {
+				return map[string]Account{
+					single.bech32(t): {AccountNumber: 4, Sequence: 6, PubKey: single.pubKey},
+					multi.bech32(t):  {AccountNumber: 9, Sequence: 1, PubKey: multi.pubKey},
+				}
+			},
+			chainID:  testSignChainID,
+			wantSeqs: [][]string{nil, {"9", "0"}},
+			wantErr:  true,
+		},
+		{
+			name: "missing account",
+			accounts: func() map[string]Account {
+				return map[string]Account{single.bech32(t): {AccountNumber: 3, Sequence: 6, PubKey: single.pubKey}}
+			},
+			chainID:  testSignChainID,
+			wantSeqs: [][]string{{"3", "5"}, nil},
+			wantErr:  true,
+		},
+		{
+			name: "other chain",
+			accounts: func() map[string]Account {
+				return map[string]Account{
+					single.bech32(t): {AccountNumber: 3, Sequence: 6, PubKey: single.pubKey},
+					multi.bech32(t):  {AccountNumber: 9, Sequence: 1, PubKey: multi.pubKey},
+				}
+			},
+			chainID:  "tequila-0004",
+			wantSeqs: [][]string{nil, nil},
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stdTx := testSignedTx(t, single, multi)
+			if tt.omitPubKey {
+				for i := range stdTx.Signatures {
+					stdTx.Signatures[i].PubKey = nil
+				}
+			}
+			raw, err := cdc.MarshalBinaryLengthPrefixed(stdTx)
+			require.NoError(t, err)
+
+			tev, ok := signersEvent(txSigners(cdc, &stdTx))
+			require.True(t, ok)
+			tx := structs.Transaction{
+				Height:  10,
+				ChainID: tt.chainID,
+				Raw:     []byte(base64.StdEncoding.EncodeToString(raw)),
+				Events:  structs.TransactionEvents{tev},
+			}
+
+			r := NewSequenceResolver(&accountSourceMock{accounts: tt.accounts()}, cdc, 10, 10)
+			err = r.ResolveTransaction(context.Background(), tx)
+			if tt.wantErr {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+
+			for i, sub := range tx.Events[0].Sub {
+				var got []string
+				if accNum, ok := sub.Additional["account_number"]; ok {
+					got = append(accNum, sub.Additional["sequence"]...)
+				}
+				require.Equal(t, tt.wantSeqs[i], got)
+				if tt.wantPubKeys != nil {
+					require.Equal(t, tt.wantPubKeys[i], sub.Additional["pubkey_type"])
+				}
+			}
+
+			signers := Signers(tx)
+			require.Len(t, signers, 2)
+			if tt.wantSeqs[0] != nil {
+				require.Equal(t, uint64(5), *signers[0].Sequence)
+			}
+		})
+	}
+}
+
+func TestSequenceResolver_AccountCache(t *testing.T) {
+	single := newTestSigner("single", 3, 5)
+	source := &accountSourceMock{accounts: map[string]Account{single.bech32(t): {AccountNumber: 3, Sequence: 6}}}
+	r := NewSequenceResolver(source, app.MakeCodec(), 0, 2)
+
+	for _, height := range []uint64{10, 10, 11, 10, 12, 10} {
+		_, err := r.account(context.Background(), single.bech32(t), height)
+		require.NoError(t, err)
+	}
+	// height 10 is evicted when 12 is fetched
+	require.Equal(t, int32(4), atomic.LoadInt32(&source.calls))
+
+	// failed fetches are not cached
+	source.err = errors.New("lcd error")
+	_, err := r.account(context.Background(), single.bech32(t), 13)
+	require.Error(t, err)
+	_, err = r.account(context.Background(), single.bech32(t), 13)
+	require.Error(t, err)
+	require.Equal(t, int32(6), atomic.LoadInt32(&source.calls))
+}
@@ -0,0 +1,242 @@
+package api
+
+import (
+	"encoding/base64"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/figment-networks/indexer-manager/structs"
+	"github.com/figment-networks/terra-worker/api/mapper"
+
+	amino "github.com/tendermint/go-amino"
+	"github.com/tendermint/tendermint/crypto"
+	"github.com/tendermint/tendermint/crypto/ed25519"
+	"github.com/tendermint/tendermint/crypto/multisig"
+	"github.com/tendermint/tendermint/crypto/secp256k1"
+	"github.com/terra-project/core/x/auth"
+	"go.uber.org/zap"
+)
+
+// SignersKind is kind of transaction event listing signers of transaction (in `GetSigners` order).
+// Every signer is a subevent of `signer` type with `signer` node and `pubkey_type` (secp256k1, ed25519, multisig),
+// `pubkey` and `signature` (base64) additionals. Multisig signers have `multisig_threshold` and members in
+// `multisig_member` node with parallel `multisig_member_pubkey_type`, `multisig_member_pubkey` and `multisig_member_signed`.
+// `account_number` and `sequence` are set when resolved (see SequenceResolver), as they are not part of the transaction
+const SignersKind = "signers"
+
+// Public key types
+const (
+	PubKeySecp256k1 = "secp256k1"
+	PubKeyEd25519   = "ed25519"
+	PubKeyMultisig  = "multisig"
+)
+
+// Signer is signer of transaction
+type Signer struct {
+	Address   string  `json:"address"`
+	PubKey    *PubKey `json:"pub_key,omitempty"`
+	Signature []byte  `json:"signature,omitempty"`
+	// AccountNumber and Sequence are set only when resolved
+	AccountNumber *uint64 `json:"account_number,omitempty"`
+	Sequence      *uint64 `json:"sequence,omitempty"`
+}
+
+// PubKey is public key of signer, multisig keys have threshold and member keys instead of key bytes
+type PubKey struct {
+	Type      string   `json:"type"`
+	Key       []byte   `json:"key,omitempty"`
+	Address   string   `json:"address"`
+	Threshold uint     `json:"threshold,omitempty"`
+	Members   []PubKey `json:"members,omitempty"`
+	// Signed marks multisig members whose signature is in the multisignature
+	Signed bool `json:"signed,omitempty"`
+}
+
+// txSigners returns signers of transaction with their public keys and signatures.
+// Public key is missing when transaction relies on the key already stored in the account
+func txSigners(cdc *amino.Codec, tx *auth.StdTx) (signers []Signer) {
+	for i, addr := range tx.GetSigners() {
+		s := Signer{}
+		s.Address, _ = mapper.AccAddress(addr)
+		if i < len(tx.Signatures) {
+			sig := tx.Signatures[i]
+			s.Signature = sig.Signature
+			if sig.PubKey != nil {
+				pk := newPubKey(sig.PubKey)
+				if pk.Type == PubKeyMultisig {
+					markMultisigSigners(cdc, &pk, sig.Signature)
+				}
+				s.PubKey = &pk
+			}
+		}
+		signers = append(signers, s)
+	}
+	return signers
+}
+
+func newPubKey(pk crypto.PubKey) PubKey {
+	p := PubKey{}
+	p.Address, _ = mapper.AccAddress(pk.Address())
+	switch k := pk.(type) {
+	case secp256k1.PubKeySecp256k1:
+		p.Type, p.Key = PubKeySecp256k1, k[:]
+	case ed25519.PubKeyEd25519:
+		p.Type, p.Key = PubKeyEd25519, k[:]
+	case multisig.PubKeyMultisigThreshold:
+		p.Type, p.Threshold = PubKeyMultisig, k.K
+		for _, m := range k.PubKeys {
+			p.Members = append(p.Members, newPubKey(m))
+		}
+	default:
+		p.Type, p.Key = fmt.Sprintf("%T", pk), pk.Bytes()
+	}
+	return p
+}
+
+// markMultisigSigners marks members of multisig key present in the multisignature bit array
+func markMultisigSigners(cdc *amino.Codec, pk *PubKey, sig []byte) {
+	ms := multisig.Multisignature{}
+	if err := cdc.UnmarshalBinaryBare(sig, &ms); err != nil || ms.BitArray == nil {
+		return
+	}
+	for i := range pk.Members {
+		pk.Members[i].Signed = ms.BitArray.GetIndex(i)
+	}
+}
+
+// signersEvent lists signers of transaction
+func signersEvent(signers []Signer) (tev structs.TransactionEvent, ok bool) {
+	if len(signers) == 0 {
+		return tev, false
+	}
+
+	tev.Kind = SignersKind
+	for _, s := range signers {
+		sub := structs.SubsetEvent{
+			Type:       []string{"signer"},
+			Module:     "auth",
+			Node:       map[string][]structs.Account{"signer": {{ID: s.Address}}},
+			Additional: map[string][]string{},
+		}
+		if len(s.Signature) > 0 {
+			sub.Additional["signature"] = []string{base64.StdEncoding.EncodeToString(s.Signature)}
+		}
+		if s.PubKey != nil {
+			sub.Additional["pubkey_type"] = []string{s.PubKey.Type}
+			if len(s.PubKey.Key) > 0 {
+				sub.Additional["pubkey"] = []string{base64.StdEncoding.EncodeToString(s.PubKey.Key)}
+			}
+			if s.PubKey.Type == PubKeyMultisig {
+				sub.Additional["multisig_threshold"] = []string{strconv.FormatUint(uint64(s.PubKey.Threshold), 10)}
+				for _, m := range s.PubKey.Members {
+					sub.Node["multisig_member"] = append(sub.Node["multisig_member"], structs.Account{ID: m.Address})
+					sub.Additional["multisig_member_pubkey_type"] = append(sub.Additional["multisig_member_pubkey_type"], m.Type)
+					sub.Additional["multisig_member_pubkey"] = append(sub.Additional["multisig_member_pubkey"], base64.StdEncoding.EncodeToString(m.Key))
+					sub.Additional["multisig_member_signed"] = append(sub.Additional["multisig_member_signed"], strconv.FormatBool(m.Signed))
+				}
+			}
+		}
+		setSequence(&sub, s.AccountNumber, s.Sequence)
+		tev.Sub = append(tev.Sub, sub)
+	}
+	return tev, true
+}
+
+func setSequence(sub *structs.SubsetEvent, accountNumber, sequence *uint64) {
+	if accountNumber != nil {
+		sub.Additional["account_number"] = []string{strconv.FormatUint(*accountNumber, 10)}
+	}
+	if sequence != nil {
+		sub.Additional["sequence"] = []string{strconv.FormatUint(*sequence, 10)}
+	}
+}
+
+// Signers returns signers listed in `signers` event of transaction
+func Signers(tx structs.Transaction) (signers []Signer) {
+	for _, ev := range tx.Events {
+		if ev.Kind != SignersKind {
+			continue
+		}
+		for _, sub := range ev.Sub {
+			signers = append(signers, subSigner(sub))
+		}
+	}
+	return signers
+}
+
+func subSigner(sub structs.SubsetEvent) (s Signer) {
+	if acc := sub.Node["signer"]; len(acc) > 0 {
+		s.Address = acc[0].ID
+	}
+	s.Signature = decodeBase64(first(sub.Additional["signature"]))
+	s.AccountNumber = parseOptionalUint(first(sub.Additional["account_number"]))
+	s.Sequence = parseOptionalUint(first(sub.Additional["sequence"]))
+
+	typ := first(sub.Additional["pubkey_type"])
+	if typ == "" {
+		return s
+	}
+	s.PubKey = &PubKey{Type: typ, Key: decodeBase64(first(sub.Additional["pubkey"])), Address: s.Address}
+	if t := parseOptionalUint(first(sub.Additional["multisig_threshold"])); t != nil {
+		s.PubKey.Threshold = uint(*t)
+	}
+	types := sub.Additional["multisig_member_pubkey_type"]
+	keys := sub.Additional["multisig_member_pubkey"]
+	signed := sub.Additional["multisig_member_signed"]
+	for i, m := range sub.Node["multisig_member"] {
+		member := PubKey{Address: m.ID}
+		if i < len(types) {
+			member.Type = types[i]
+		}
+		if i < len(keys) {
+			member.Key = decodeBase64(keys[i])
+		}
+		if i < len(signed) {
+			member.Signed, _ = strconv.ParseBool(signed[i])
+		}
+		s.PubKey.Members = append(s.PubKey.Members, member)
+	}
+	return s
+}
+
+func first(values []string) string {
+	if len(values) == 0 {
+		return ""
+	}
+	return values[0]
+}
+
+func decodeBase64(s string) []byte {
+	if s == "" {
+		return nil
+	}
+	b, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		return nil
+	}
+	return b
+}
+
+func parseOptionalUint(s string) *uint64 {
+	if s == "" {
+		return nil
+	}
+	u, err := strconv.ParseUint(s, 10, 64)
+	if err != nil {
+		return nil
+	}
+	return &u
+}
+
+// GetSignersFromRaw returns signers of raw transaction for plugin use
+func (c *Client) GetSignersFromRaw(logger *zap.Logger, txReader io.Reader) ([]Signer, error) {
+	tx := &auth.StdTx{}
+	base64Dec := base64.NewDecoder(base64.StdEncoding, txReader)
+	_, err := c.cdc.UnmarshalBinaryLengthPrefixedReader(base64Dec, tx, 0)
+	if err != nil {
+		logger.Error("[TERRA-API] Problem decoding raw transaction (cdc) ", zap.Error(err))
+		return nil, err
+	}
+	return txSigners(c.cdc, tx), nil
+}
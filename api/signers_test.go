@@ -0,0 +1,167 @@
+package api
+
+import (
+	"encoding/base64"
+	"strings"
+	"testing"
+
+	"github.com/figment-networks/indexer-manager/structs"
+	"github.com/figment-networks/terra-worker/api/mapper"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/tendermint/tendermint/crypto"
+	"github.com/tendermint/tendermint/crypto/multisig"
+	"github.com/tendermint/tendermint/crypto/secp256k1"
+	"github.com/terra-project/core/app"
+	"github.com/terra-project/core/x/auth"
+	"github.com/terra-project/core/x/bank"
+)
+
+const testSignChainID = "columbus-4"
+
+// testSigner signs transactions with single key or with the first and the last member of 2 of 3 multisig key
+type testSigner struct {
+	keys          []secp256k1.PrivKeySecp256k1
+	pubKey        crypto.PubKey
+	accountNumber uint64
+	sequence      uint64
+}
+
+func newTestSigner(secret string, accountNumber, sequence uint64) testSigner {
+	key := secp256k1.GenPrivKeySecp256k1([]byte(secret))
+	return testSigner{keys: []secp256k1.PrivKeySecp256k1{key}, pubKey: key.PubKey(), accountNumber: accountNumber, sequence: sequence}
+}
+
+func newTestMultisigSigner(secret string, accountNumber, sequence uint64) testSigner {
+	var (
+		keys    []secp256k1.PrivKeySecp256k1
+		pubKeys []crypto.PubKey
+	)
+	for _, s := range []string{"a", "b", "c"} {
+		key := secp256k1.GenPrivKeySecp256k1([]byte(secret + s))
+		keys = append(keys, key)
+		pubKeys = append(pubKeys, key.PubKey())
+	}
+	return testSigner{keys: keys, pubKey: multisig.NewPubKeyMultisigThreshold(2, pubKeys), accountNumber: accountNumber, sequence: sequence}
+}
+
+func (ts testSigner) address() sdk.AccAddress {
+	return sdk.AccAddress(ts.pubKey.Address())
+}
+
+func (ts testSigner) bech32(t *testing.T) string {
+	addr, err := mapper.AccAddress(ts.address())
+	require.NoError(t, err)
+	return addr
+}
+
+func (ts testSigner) sign(t *testing.T, tx auth.StdTx) auth.StdSignature {
+	signBytes := auth.StdSignBytes(testSignChainID, ts.accountNumber, ts.sequence, tx.Fee, tx.Msgs, tx.Memo)
+	mpk, ok := ts.pubKey.(multisig.PubKeyMultisigThreshold)
+	if !ok {
+		sig, err := ts.keys[0].Sign(signBytes)
+		require.NoError(t, err)
+		return auth.StdSignature{PubKey: ts.pubKey, Signature: sig}
+	}
+
+	ms := multisig.NewMultisig(len(ts.keys))
+	for _, i := range []int{0, 2} {
+		sig, err := ts.keys[i].Sign(signBytes)
+		require.NoError(t, err)
+		require.NoError(t, ms.AddSignatureFromPubKey(sig, ts.keys[i].PubKey(), mpk.PubKeys))
+	}
+	return auth.StdSignature{PubKey: ts.pubKey, Signature: ms.Marshal()}
+}
+
+// testSignedTx returns transaction sending coins from every signer, signed by all of them
+func testSignedTx(t *testing.T, signers ...testSigner) auth.StdTx {
+	to := newTestSigner("recipient", 0, 0).address()
+	tx := auth.StdTx{
+		Fee:  auth.NewStdFee(200000, sdk.NewCoins(sdk.NewInt64Coin("uluna", 3000))),
+		Memo: "signed",
+	}
+	for _, s := range signers {
+		tx.Msgs = append(tx.Msgs, bank.NewMsgSend(s.address(), to, sdk.NewCoins(sdk.NewInt64Coin("uluna", 10))))
+	}
+	for _, s := range signers {
+		tx.Signatures = append(tx.Signatures, s.sign(t, tx))
+	}
+	return tx
+}
+
+func TestSigners(t *testing.T) {
+	cdc := app.MakeCodec()
+	single := newTestSigner("single", 3, 5)
+	multi := newTestMultisigSigner("multi", 9, 0)
+
+	tx := testSignedTx(t, single, multi)
+	mpk := multi.pubKey.(multisig.PubKeyMultisigThreshold)
+	member := func(i int, signed bool) PubKey {
+		addr, err := mapper.AccAddress(mpk.PubKeys[i].Address())
+		require.NoError(t, err)
+		key := mpk.PubKeys[i].(secp256k1.PubKeySecp256k1)
+		return PubKey{Type: PubKeySecp256k1, Key: key[:], Address: addr, Signed: signed}
+	}
+	singleKey := single.pubKey.(secp256k1.PubKeySecp256k1)
+
+	want := []Signer{
+		{
+			Address:   single.bech32(t),
+			PubKey:    &PubKey{Type: PubKeySecp256k1, Key: singleKey[:], Address: single.bech32(t)},
+			Signature: tx.Signatures[0].Signature,
+		},
+		{
+			Address: multi.bech32(t),
+			PubKey: &PubKey{Type: PubKeyMultisig, Address: multi.bech32(t), Threshold: 2, Members: []PubKey{
+				member(0, true), member(1, false), member(2, true),
+			}},
+			Signature: tx.Signatures[1].Signature,
+		},
+	}
+
+	got := txSigners(cdc, &tx)
+	require.Equal(t, want, got)
+
+	tev, ok := signersEvent(got)
+	require.True(t, ok)
+	require.Equal(t, SignersKind, tev.Kind)
+	require.Len(t, tev.Sub, 2)
+	require.Equal(t, []string{"2"}, tev.Sub[1].Additional["multisig_threshold"])
+	require.Equal(t, []string{"true", "false", "true"}, tev.Sub[1].Additional["multisig_member_signed"])
+	require.Len(t, tev.Sub[1].Node["multisig_member"], 3)
+
+	// signers are read back from the event
+	require.Equal(t, want, Signers(structs.Transaction{Events: structs.TransactionEvents{tev}}))
+
+	// signature without public key relies on the key stored in the account
+	tx.Signatures[0].PubKey = nil
+	got = txSigners(cdc, &tx)
+	require.Nil(t, got[0].PubKey)
+	tev, _ = signersEvent(got)
+	require.NotContains(t, tev.Sub[0].Additional, "pubkey_type")
+
+	// unsigned transaction still lists its signers
+	tx.Signatures = nil
+	require.Equal(t, []Signer{{Address: single.bech32(t)}, {Address: multi.bech32(t)}}, txSigners(cdc, &tx))
+}
+
+func TestClient_GetSignersFromRaw(t *testing.T) {
+	cli := NewClient("", "", nil, nil, 0)
+	tx := testSignedTx(t, newTestSigner("single", 3, 5))
+	raw, err := cli.CDC().MarshalBinaryLengthPrefixed(tx)
+	require.NoError(t, err)
+
+	signers, err := cli.GetSignersFromRaw(zaptest.NewLogger(t), base64Reader(raw))
+	require.NoError(t, err)
+	require.Len(t, signers, 1)
+	require.Equal(t, PubKeySecp256k1, signers[0].PubKey.Type)
+
+	_, err = cli.GetSignersFromRaw(zaptest.NewLogger(t), base64Reader([]byte("invalid")))
+	require.Error(t, err)
+}
+
+func base64Reader(b []byte) *strings.Reader {
+	return strings.NewReader(base64.StdEncoding.EncodeToString(b))
+}
@@ -0,0 +1,214 @@
+package api
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	"github.com/figment-networks/indexer-manager/structs"
+	cStruct "github.com/figment-networks/indexer-manager/worker/connectivity/structs"
+	"github.com/figment-networks/terra-worker/api/types"
+	"go.uber.org/zap"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	amino "github.com/tendermint/go-amino"
+	"github.com/terra-project/core/x/auth"
+	"github.com/terra-project/core/x/bank"
+	"github.com/terra-project/core/x/market"
+	"github.com/terra-project/core/x/msgauth"
+	"github.com/terra-project/core/x/wasm"
+)
+
+// TaxRates are treasury tax rate and tax caps (by denomination) at height
+type TaxRates struct {
+	Rate sdk.Dec
+	Caps map[string]sdk.Int
+}
+
+// TaxSource provides treasury tax rates at given height
+type TaxSource interface {
+	GetTaxRates(ctx context.Context, height uint64) (TaxRates, error)
+}
+
+type taxCap struct {
+	Denom  string  `json:"denom"`
+	TaxCap sdk.Int `json:"tax_cap"`
+}
+
+// GetTaxRates fetches treasury tax rate and tax caps at given height
+func (c *Client) GetTaxRates(ctx context.Context, height uint64) (rates TaxRates, err error) {
+	if err = c.getLCD(ctx, "/treasury/tax_rate", "/treasury/tax_rate", height, &rates.Rate); err != nil {
+		return rates, err
+	}
+
+	var caps []taxCap
+	if err = c.getLCD(ctx, "/treasury/tax_caps", "/treasury/tax_caps", height, &caps); err != nil {
+		return rates, err
+	}
+	rates.Caps = make(map[string]sdk.Int, len(caps))
+	for _, tc := range caps {
+		rates.Caps[tc.Denom] = tc.TaxCap
+	}
+	return rates, nil
+}
+
+// taxPrincipals returns amounts the stability tax is charged on, separately for every principal
+// as the tax cap applies to each of them (the same messages as terra ante handler taxes)
+func taxPrincipals(msgs []sdk.Msg) (principals []sdk.Coins) {
+	for _, msg := range msgs {
+		switch m := msg.(type) {
+		case bank.MsgSend:
+			principals = append(principals, m.Amount)
+		case bank.MsgMultiSend:
+			for _, in := range m.Inputs {
+				principals = append(principals, in.Coins)
+			}
+		case market.MsgSwapSend:
+			principals = append(principals, sdk.NewCoins(m.OfferCoin))
+		case wasm.MsgInstantiateContract:
+			principals = append(principals, m.InitCoins)
+		case wasm.MsgExecuteContract:
+			principals = append(principals, m.Coins)
+		case msgauth.MsgExecAuthorized:
+			principals = append(principals, taxPrincipals(m.Msgs)...)
+		}
+	}
+	return principals
+}
+
+// computeTax returns stability tax of principals: rate of every coin but luna, capped by the tax cap of its denomination
+func computeTax(rates TaxRates, principals []sdk.Coins) sdk.Coins {
+	tax := sdk.NewCoins()
+	if rates.Rate.IsNil() || rates.Rate.IsZero() {
+		return tax
+	}
+	for _, principal := range principals {
+		for _, coin := range principal {
+			if coin.Denom == "uluna" {
+				continue
+			}
+			due := sdk.NewDecFromInt(coin.Amount).Mul(rates.Rate).TruncateInt()
+			if taxCap, ok := rates.Caps[coin.Denom]; ok && due.GT(taxCap) {
+				due = taxCap
+			}
+			if due.IsPositive() {
+				tax = tax.Add(sdk.NewCoin(coin.Denom, due))
+			}
+		}
+	}
+	return tax
+}
+
+// logsHaveTax reports whether message logs contain the tax charged (columbus-3 logs do, later ones don't)
+func logsHaveTax(txLog []types.LogFormat) bool {
+	for _, lf := range txLog {
+		if lf.Log.Tax != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// taxUnresolved reports whether transaction is charged stability tax its logs don't report,
+// so its fee can't be split into gas fee and tax without TaxResolver
+func taxUnresolved(tx *auth.StdTx, txLog []types.LogFormat) bool {
+	if logsHaveTax(txLog) || tx.Fee.Amount.Empty() {
+		return false
+	}
+	for _, principal := range taxPrincipals(tx.Msgs) {
+		for _, coin := range principal {
+			if coin.Denom != "uluna" && coin.IsPositive() {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// TaxResolver computes stability tax of transactions whose logs don't report it,
+// from the treasury tax rate and caps at the transaction height
+type TaxResolver struct {
+	source TaxSource
+	cdc    *amino.Codec
+	rates  *lruCache
+}
+
+// NewTaxResolver is TaxResolver constructor, rates of cacheSize recent heights are kept
+func NewTaxResolver(source TaxSource, cdc *amino.Codec, cacheSize int) *TaxResolver {
+	return &TaxResolver{source: source, cdc: cdc, rates: newLRUCache(cacheSize)}
+}
+
+// ResolveTaxesCh splits fee of transactions passing from in to out into gas fee and computed tax,
+// other responses are passed unchanged. It's the enrichment stage following RawToTransactionCh
+func ResolveTaxesCh(ctx context.Context, logger *zap.Logger, r *TaxResolver, in <-chan cStruct.OutResp, out chan<- cStruct.OutResp) {
+	EnrichCh(ctx, in, out, func(resp cStruct.OutResp) cStruct.OutResp {
+		if tx, ok := resp.Payload.(structs.Transaction); ok {
+			tx, err := r.ResolveTransaction(ctx, logger, tx)
+			if err != nil {
+				logger.Error("[TERRA-API] Problem resolving tax", zap.Error(err), zap.Uint64("height", tx.Height), zap.String("hash", tx.Hash))
+			}
+			resp.Payload = tx
+		}
+		return resp
+	})
+}
+
+// ResolveTransaction returns transaction with `fee` and `balance_changes` events rebuilt with the computed tax
+// (so `fee` is no longer marked with `tax_unresolved`). Transactions with tax in logs or without taxed coins are returned unchanged
+func (r *TaxResolver) ResolveTransaction(ctx context.Context, logger *zap.Logger, tx structs.Transaction) (structs.Transaction, error) {
+	txLog, _ := decodeTxLog(string(tx.RawLog))
+	if logsHaveTax(txLog) {
+		return tx, nil
+	}
+
+	stdTx := &auth.StdTx{}
+	base64Dec := base64.NewDecoder(base64.StdEncoding, strings.NewReader(string(tx.Raw)))
+	if _, err := r.cdc.UnmarshalBinaryLengthPrefixedReader(base64Dec, stdTx, 0); err != nil {
+		return tx, fmt.Errorf("error decoding raw transaction: %w", err)
+	}
+	if !taxUnresolved(stdTx, txLog) {
+		return tx, nil
+	}
+	principals := taxPrincipals(stdTx.Msgs)
+
+	rates, err := r.taxRates(ctx, tx.Height)
+	if err != nil {
+		return tx, err
+	}
+	tax := computeTax(rates, principals)
+	gasFee, negative := stdTx.Fee.Amount.SafeSub(tax)
+	if negative {
+		return tx, fmt.Errorf("computed tax %s is higher than fee %s", tax, stdTx.Fee.Amount)
+	}
+
+	events := make(structs.TransactionEvents, len(tx.Events))
+	copy(events, tx.Events)
+	for i, ev := range events {
+		switch ev.Kind {
+		case "fee":
+			if tev, ok := feeBreakdownEvent(stdTx, gasFee, tax, tx.GasWanted); ok {
+				events[i] = tev
+			}
+		case BalanceChangesKind:
+			if tev, ok := balanceChangesEvent(logger, stdTx, txLog, gasFee, tax); ok {
+				events[i] = tev
+			}
+		}
+	}
+	tx.Events = events
+	return tx, nil
+}
+
+// taxRates returns rates at height, fetched rates are cached
+func (r *TaxResolver) taxRates(ctx context.Context, height uint64) (TaxRates, error) {
+	if rates, ok := r.rates.Get(height); ok {
+		return rates.(TaxRates), nil
+	}
+	rates, err := r.source.GetTaxRates(ctx, height)
+	if err != nil {
+		return rates, fmt.Errorf("error fetching tax rates at height %d: %w", height, err)
+	}
+	r.rates.Add(height, rates)
+	return rates, nil
+}
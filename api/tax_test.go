@@ -0,0 +1,208 @@
+package api
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/figment-networks/indexer-manager/structs"
+	cStruct "github.com/figment-networks/indexer-manager/worker/connectivity/structs"
+	"github.com/figment-networks/terra-worker/api/mapper"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/terra-project/core/app"
+	"github.com/terra-project/core/x/auth"
+	"github.com/terra-project/core/x/bank"
+)
+
+type taxSourceMock struct {
+	rates TaxRates
+	err   error
+	calls int
+}
+
+func (ts *taxSourceMock) GetTaxRates(ctx context.Context, height uint64) (TaxRates, error) {
+	ts.calls++
+	return ts.rates, ts.err
+}
+
+func testTaxTx(msgs []sdk.Msg, fee sdk.Coins) *auth.StdTx {
+	return &auth.StdTx{Msgs: msgs, Fee: auth.NewStdFee(200000, fee)}
+}
+
+func testSend(from, to sdk.AccAddress, amount sdk.Coins) bank.MsgSend {
+	return bank.NewMsgSend(from, to, amount)
+}
+
+func coins(s string) sdk.Coins {
+	c, err := sdk.ParseCoins(s)
+	if err != nil {
+		panic(err)
+	}
+	return c
+}
+
+func TestSplitFee(t *testing.T) {
+	from, to := newTestSigner("from", 1, 0).address(), newTestSigner("to", 2, 0).address()
+	send := testTaxTx([]sdk.Msg{testSend(from, to, coins("1000000uusd"))}, coins("8000uusd"))
+
+	tests := []struct {
+		name       string
+		log        string
+		wantGasFee sdk.Coins
+		wantTax    sdk.Coins
+	}{
+		{"columbus-3 log with tax", `[{"msg_index":0,"success":true,"log":"{\"tax\":\"5000uusd\"}"}]`, coins("3000uusd"), coins("5000uusd")},
+		{"columbus-4 log without tax", `[{"msg_index":0,"log":""}]`, coins("8000uusd"), sdk.NewCoins()},
+		{"tax higher than fee", `[{"msg_index":0,"log":"{\"tax\":\"9000uusd\"}"}]`, coins("8000uusd"), sdk.NewCoins()},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			txLog, _ := decodeTxLog(tt.log)
+			gasFee, tax := splitFee(zaptest.NewLogger(t), send, txLog)
+			require.Equal(t, tt.wantGasFee.String(), gasFee.String())
+			require.Equal(t, tt.wantTax.String(), tax.String())
+		})
+	}
+}
+
+func TestTaxResolver_ResolveTransaction(t *testing.T) {
+	cdc := app.MakeCodec()
+	from, to := newTestSigner("from", 1, 0).address(), newTestSigner("to", 2, 0).address()
+	rates := TaxRates{Rate: sdk.NewDecWithPrec(5, 3), Caps: map[string]sdk.Int{"uusd": sdk.NewInt(1000000), "ukrw": sdk.NewInt(1000000000)}}
+
+	tests := []struct {
+		name       string
+		tx         *auth.StdTx
+		log        string
+		sourceErr  error
+		wantTax    string
+		wantGasFee string
+		wantCalls  int
+		wantErr    bool
+	}{
+		{
+			name:       "columbus-4 send",
+			tx:         testTaxTx([]sdk.Msg{testSend(from, to, coins("1000000uusd"))}, coins("8000uusd")),
+			wantTax:    "5000uusd",
+			wantGasFee: "3000uusd",
+			wantCalls:  1,
+		},
+		{
+			name:       "tax capped",
+			tx:         testTaxTx([]sdk.Msg{testSend(from, to, coins("1000000000000uusd"))}, coins("1003000uusd")),
+			wantTax:    "1000000uusd",
+			wantGasFee: "3000uusd",
+			wantCalls:  1,
+		},
+		{
+			name: "multisend inputs capped separately",
+			tx: testTaxTx([]sdk.Msg{bank.NewMsgMultiSend(
+				[]bank.Input{bank.NewInput(from, coins("1000000000000uusd")), bank.NewInput(to, coins("2000000ukrw"))},
+				[]bank.Output{bank.NewOutput(to, coins("1000000000000uusd")), bank.NewOutput(from, coins("2000000ukrw"))},
+			)}, coins("10000ukrw,1003000uusd")),
+			wantTax:    "10000ukrw,1000000uusd",
+			wantGasFee: "3000uusd",
+			wantCalls:  1,
+		},
+		{
+			name: "luna is exempt",
+			tx:   testTaxTx([]sdk.Msg{testSend(from, to, coins("1000000uluna"))}, coins("3000uluna")),
+		},
+		{
+			name: "columbus-3 log with tax",
+			tx:   testTaxTx([]sdk.Msg{testSend(from, to, coins("1000000uusd"))}, coins("8000uusd")),
+			log:  `[{"msg_index":0,"success":true,"log":"{\"tax\":\"5000uusd\"}"}]`,
+		},
+		{
+			name:      "rates not available",
+			tx:        testTaxTx([]sdk.Msg{testSend(from, to, coins("1000000uusd"))}, coins("8000uusd")),
+			sourceErr: errors.New("lcd unavailable"),
+			wantCalls: 1,
+			wantErr:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			raw, err := cdc.MarshalBinaryLengthPrefixed(*tt.tx)
+			require.NoError(t, err)
+			in := structs.Transaction{
+				Height: 3000001,
+				Raw:    []byte(base64.StdEncoding.EncodeToString(raw)),
+				RawLog: []byte(tt.log),
+				Events: structs.TransactionEvents{{Kind: "fee"}, {Kind: BalanceChangesKind}},
+			}
+
+			source := &taxSourceMock{rates: rates, err: tt.sourceErr}
+			r := NewTaxResolver(source, cdc, 10)
+			got, err := r.ResolveTransaction(context.Background(), zaptest.NewLogger(t), in)
+			require.Equal(t, tt.wantErr, err != nil, err)
+			require.Equal(t, tt.wantCalls, source.calls)
+
+			if tt.wantTax == "" {
+				require.Equal(t, in.Events, got.Events)
+				return
+			}
+			fee := got.Events[0].Sub[0]
+			tax, gasFee := sdk.NewCoins(), sdk.NewCoins()
+			for _, tr := range fee.Transfers["tax"] {
+				for _, am := range tr.Amounts {
+					tax = tax.Add(sdk.NewCoin(am.Currency, sdk.NewIntFromBigInt(am.Numeric)))
+				}
+			}
+			for _, tr := range fee.Transfers["gas_fee"] {
+				for _, am := range tr.Amounts {
+					gasFee = gasFee.Add(sdk.NewCoin(am.Currency, sdk.NewIntFromBigInt(am.Numeric)))
+				}
+			}
+			require.Equal(t, tt.wantTax, tax.String())
+			require.Equal(t, tt.wantGasFee, gasFee.String())
+			require.NotContains(t, fee.Additional, "tax_unresolved")
+
+			reasons := map[string]int{}
+			for _, ch := range BalanceChanges(got) {
+				reasons[ch.Reason]++
+			}
+			require.Equal(t, 2*len(tax), reasons[mapper.ReasonTax])
+			requireBalanceChangesNetZero(t, got)
+		})
+	}
+
+	// rates are fetched once per height
+	source := &taxSourceMock{rates: rates}
+	r := NewTaxResolver(source, cdc, 10)
+	raw, err := cdc.MarshalBinaryLengthPrefixed(*testTaxTx([]sdk.Msg{testSend(from, to, coins("1000000uusd"))}, coins("8000uusd")))
+	require.NoError(t, err)
+	for i := 0; i < 3; i++ {
+		_, err := r.ResolveTransaction(context.Background(), zaptest.NewLogger(t), structs.Transaction{Height: 10, Raw: []byte(base64.StdEncoding.EncodeToString(raw))})
+		require.NoError(t, err)
+	}
+	require.Equal(t, 1, source.calls)
+}
+
+func TestResolveTaxesCh_DrainsOnCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	in := make(chan cStruct.OutResp)
+	sent := make(chan struct{})
+	go func() {
+		defer close(sent)
+		for i := 0; i < 10; i++ {
+			in <- cStruct.OutResp{Type: "Block"}
+		}
+		close(in)
+	}()
+
+	// out is never read, so responses can only be dropped
+	ResolveTaxesCh(ctx, zaptest.NewLogger(t), NewTaxResolver(&taxSourceMock{}, app.MakeCodec(), 10), in, make(chan cStruct.OutResp))
+	select {
+	case <-sent:
+	case <-time.After(time.Second):
+		t.Fatal("sender is blocked after cancel")
+	}
+}
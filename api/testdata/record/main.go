@@ -0,0 +1,131 @@
+// Command record stores `/tx_search` responses of a node as fixtures of golden tests.
+//
+// Transactions are fetched by their hashes or, with `-actions`, the latest transactions of every
+// message action are taken. Responses are merged into a single `/tx_search` response written to
+// testdata/tx_search/<chain_id>/<name>.json. Golden outputs are then regenerated with
+// `go test ./api/ -run Golden -update`.
+//
+//	go run ./api/testdata/record -rpc http://node:26657 -chain columbus-4 -name bank -hashes 1A2B...,3C4D...
+//	go run ./api/testdata/record -rpc http://node:26657 -chain columbus-4 -name bank -actions send,multisend -max-height 4724000
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type txSearchResponse struct {
+	Jsonrpc string `json:"jsonrpc"`
+	ID      int    `json:"id"`
+	Result  struct {
+		Txs        []json.RawMessage `json:"txs"`
+		TotalCount string            `json:"total_count"`
+	} `json:"result"`
+	Error json.RawMessage `json:"error,omitempty"`
+}
+
+func main() {
+	rpc := flag.String("rpc", "", "address of tendermint rpc of archive node")
+	key := flag.String("key", "", "value of Authorization header (eg. datahub key)")
+	chain := flag.String("chain", "", "chain id, directory of the fixture")
+	name := flag.String("name", "", "fixture name, usually the module of recorded messages")
+	hashes := flag.String("hashes", "", "comma-separated hashes of recorded transactions")
+	actions := flag.String("actions", "", "comma-separated message actions, the latest transactions of every action are recorded (instead of -hashes)")
+	perAction := flag.Int("per-action", 2, "number of recorded transactions of every action")
+	maxHeight := flag.Uint64("max-height", 0, "highest height of transactions recorded by action, the last height of the chain is used when 0")
+	dir := flag.String("dir", filepath.Join("api", "testdata", "tx_search"), "fixtures directory")
+	flag.Parse()
+
+	if *rpc == "" || *chain == "" || *name == "" || (*hashes == "") == (*actions == "") {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	c := &http.Client{Timeout: 40 * time.Second}
+	merged := txSearchResponse{Jsonrpc: "2.0", ID: -1}
+	if *hashes != "" {
+		for _, hash := range strings.Split(*hashes, ",") {
+			hash = strings.ToUpper(strings.TrimSpace(hash))
+			resp, err := searchTx(c, *rpc, *key, fmt.Sprintf("tx.hash='%s'", hash), 1)
+			if err != nil {
+				log.Fatalf("error recording %s: %s", hash, err)
+			}
+			if len(resp.Result.Txs) != 1 {
+				log.Fatalf("transaction %s not found", hash)
+			}
+			merged.Result.Txs = append(merged.Result.Txs, resp.Result.Txs...)
+		}
+	}
+	for _, action := range strings.Split(*actions, ",") {
+		if action = strings.TrimSpace(action); action == "" {
+			continue
+		}
+		query := fmt.Sprintf("message.action='%s'", action)
+		if *maxHeight > 0 {
+			query += fmt.Sprintf(" AND tx.height<=%d", *maxHeight)
+		}
+		resp, err := searchTx(c, *rpc, *key, query, *perAction)
+		if err != nil {
+			log.Fatalf("error recording %s: %s", action, err)
+		}
+		if len(resp.Result.Txs) == 0 {
+			log.Fatalf("no transactions of %s found", action)
+		}
+		merged.Result.Txs = append(merged.Result.Txs, resp.Result.Txs...)
+	}
+	merged.Result.TotalCount = strconv.Itoa(len(merged.Result.Txs))
+
+	b, err := json.MarshalIndent(merged, "", "  ")
+	if err != nil {
+		log.Fatal(err)
+	}
+	path := filepath.Join(*dir, *chain, *name+".json")
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		log.Fatal(err)
+	}
+	if err := ioutil.WriteFile(path, append(b, '\n'), 0644); err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("recorded %d transactions into %s\n", len(merged.Result.Txs), path)
+}
+
+// searchTx gets at most perPage transactions of query, the latest first
+func searchTx(c *http.Client, rpc, key, query string, perPage int) (resp txSearchResponse, err error) {
+	q := url.Values{}
+	q.Set("query", "\""+query+"\"")
+	q.Set("per_page", strconv.Itoa(perPage))
+	q.Set("order_by", "\"desc\"")
+	req, err := http.NewRequest(http.MethodGet, strings.TrimSuffix(rpc, "/")+"/tx_search?"+q.Encode(), nil)
+	if err != nil {
+		return resp, err
+	}
+	if key != "" {
+		req.Header.Set("Authorization", key)
+	}
+
+	r, err := c.Do(req)
+	if err != nil {
+		return resp, err
+	}
+	defer r.Body.Close()
+	if r.StatusCode != http.StatusOK {
+		return resp, fmt.Errorf("unexpected status %s", r.Status)
+	}
+	if err := json.NewDecoder(r.Body).Decode(&resp); err != nil {
+		return resp, err
+	}
+	if len(resp.Error) > 0 && string(resp.Error) != "null" {
+		return resp, fmt.Errorf("node error: %s", resp.Error)
+	}
+	return resp, nil
+}
@@ -1,7 +1,9 @@
 package api
 
 import (
+	"bytes"
 	"context"
+	"crypto/sha256"
 	"encoding/base64"
 	"encoding/json"
 	"errors"
@@ -128,7 +130,7 @@ func (c *Client) SearchTx(ctx context.Context, r structs.HeightRange, chain_id s
 	}
 
 	c.logger.Debug("[TERRA-API] Converting requests ", zap.Int("number", len(result.Txs)), zap.Int("blocks", len(blocks)))
-	err = RawToTransaction(c.logger, c.cdc, result.Txs, blocks, out)
+	err = RawToTransaction(c.logger, c.cdc, c.mapperOptions, result.Txs, blocks, out)
 	if err != nil {
 		c.logger.Error("[TERRA-API] Error getting rawToTransaction", zap.Error(err))
 		fin <- err.Error()
@@ -140,7 +142,7 @@ func (c *Client) SearchTx(ctx context.Context, r structs.HeightRange, chain_id s
 	return
 }
 
-func RawToTransaction(logger *zap.Logger, cdc *amino.Codec, in []types.TxResponse, blocks map[uint64]structs.Block, out chan cStruct.OutResp) error {
+func RawToTransaction(logger *zap.Logger, cdc *amino.Codec, opts mapper.Options, in []types.TxResponse, blocks map[uint64]structs.Block, out chan cStruct.OutResp) error {
 	readr := strings.NewReader("")
 	dec := json.NewDecoder(readr)
 	for _, txRaw := range in {
@@ -153,7 +155,7 @@ func RawToTransaction(logger *zap.Logger, cdc *amino.Codec, in []types.TxRespons
 			txErr.Message = txRaw.TxResult.Log
 		}
 
-		tx, err := rawToTransaction(logger, cdc, txRaw, lf, txErr, blocks)
+		tx, err := rawToTransaction(logger, cdc, opts, txRaw, lf, txErr, blocks)
 		if err != nil {
 			return err
 		}
@@ -163,7 +165,7 @@ func RawToTransaction(logger *zap.Logger, cdc *amino.Codec, in []types.TxRespons
 	return nil
 }
 
-func RawToTransactionCh(logger *zap.Logger, cdc *amino.Codec, wg *sync.WaitGroup, in <-chan types.TxResponse, blocks map[uint64]structs.Block, out chan cStruct.OutResp) {
+func RawToTransactionCh(logger *zap.Logger, cdc *amino.Codec, opts mapper.Options, wg *sync.WaitGroup, in <-chan types.TxResponse, blocks map[uint64]structs.Block, out chan cStruct.OutResp) {
 	readr := strings.NewReader("")
 	dec := json.NewDecoder(readr)
 	defer wg.Done()
@@ -180,7 +182,7 @@ func RawToTransactionCh(logger *zap.Logger, cdc *amino.Codec, wg *sync.WaitGroup
 				txErr.Message = txRaw.TxResult.Log
 			}
 		}
-		tx, err := rawToTransaction(logger, cdc, txRaw, lf, txErr, blocks)
+		tx, err := rawToTransaction(logger, cdc, opts, txRaw, lf, txErr, blocks)
 		if err != nil {
 			logger.Error("[TERRA-API] Problem decoding raw transaction", zap.Error(err), zap.String("height", txRaw.Height), zap.String("hash", txRaw.Hash))
 		}
@@ -188,7 +190,7 @@ func RawToTransactionCh(logger *zap.Logger, cdc *amino.Codec, wg *sync.WaitGroup
 	}
 }
 
-func rawToTransaction(logger *zap.Logger, cdc *amino.Codec, txRaw types.TxResponse, txLog []types.LogFormat, txErr TxLogError, blocks map[uint64]structs.Block) (cStruct.OutResp, error) {
+func rawToTransaction(logger *zap.Logger, cdc *amino.Codec, opts mapper.Options, txRaw types.TxResponse, txLog []types.LogFormat, txErr TxLogError, blocks map[uint64]structs.Block) (cStruct.OutResp, error) {
 	timer := metrics.NewTimer(transactionConversionDuration)
 	defer timer.ObserveDuration()
 
@@ -241,15 +243,23 @@ func rawToTransaction(logger *zap.Logger, cdc *amino.Codec, txRaw types.TxRespon
 	trans.RawLog = make([]byte, txLogReader.Len())
 	txLogReader.Read(trans.RawLog)
 
-	for _, coin := range tx.Fee.Amount {
-		trans.Fee = append(trans.Fee, structs.TransactionAmount{
-			Text:     coin.Amount.String(),
-			Numeric:  coin.Amount.BigInt(),
-			Currency: coin.Denom,
-		})
-	}
+	trans.Fee = mapper.CoinsAmounts(tx.Fee.Amount)
+
+	appendEvents(logger, opts, &trans, tx, txLog, txErr)
 
-	appendEvents(logger, &trans, tx, txLog, txErr)
+	gasFee, tax := splitFee(logger, tx, txLog)
+	if tev, ok := feeBreakdownEvent(tx, gasFee, tax, trans.GasWanted); ok {
+		if taxUnresolved(tx, txLog) {
+			tev.Sub[0].Additional["tax_unresolved"] = []string{"true"}
+		}
+		trans.Events = append(trans.Events, tev)
+	}
+	if tev, ok := balanceChangesEvent(logger, tx, txLog, gasFee, tax); ok {
+		trans.Events = append(trans.Events, tev)
+	}
+	if tev, ok := signersEvent(txSigners(cdc, tx)); ok {
+		trans.Events = append(trans.Events, tev)
+	}
 
 	outTX.Payload = trans
 
@@ -258,19 +268,26 @@ func rawToTransaction(logger *zap.Logger, cdc *amino.Codec, txRaw types.TxRespon
 
 // func rawToTransaction(logger *zap.Logger, cdc *amino.Codec, txRaw types.TxResponse, txLog []types.LogFormat, txErr TxLogError, blocks map[uint64]structs.Block) (cStruct.OutResp, error) {
 
-func appendEvents(logger *zap.Logger, trans *structs.Transaction, tx *auth.StdTx, txLog []types.LogFormat, txErr TxLogError) {
+func appendEvents(logger *zap.Logger, opts mapper.Options, trans *structs.Transaction, tx *auth.StdTx, txLog []types.LogFormat, txErr TxLogError) {
 	presentIndexes := map[string]bool{}
 	for index, msg := range tx.Msgs {
 		tev := structs.TransactionEvent{
 			ID: strconv.Itoa(index),
 		}
 		lf := findLog(txLog, index)
-		ev, err := getSubEvent(msg, lf)
+		opts.Contracts.RecordCodeIDs(lf)
+		ev, err := getSubEvent(msg, lf, opts)
 		if len(ev.Type) > 0 {
 			tev.Kind = msg.Type()
 			tev.Sub = append(tev.Sub, ev)
 		}
 
+		var lookupErr *mapper.ContractLookupError
+		if errors.As(err, &lookupErr) {
+			// the call is kept undecoded rather than lost
+			logger.Warn("[TERRA-API] Problem matching contract, call is not decoded", zap.Error(err), zap.Uint64("height", trans.Height), zap.String("contract", lookupErr.Contract))
+			err = nil
+		}
 		if err != nil {
 			if errors.Is(err, errUnknownMessageType) {
 				unknownTransactions.WithLabels(msg.Type() + "/" + msg.Route()).Inc()
@@ -360,7 +377,7 @@ func eventFromLogs(lf types.LogFormat) structs.TransactionEvent {
 	return te
 }
 
-func getSubEvent(msg sdk.Msg, lf types.LogFormat) (se structs.SubsetEvent, err error) {
+func getSubEvent(msg sdk.Msg, lf types.LogFormat, opts mapper.Options) (se structs.SubsetEvent, err error) {
 	switch msg.Route() {
 	case "bank":
 		switch msg.Type() {
@@ -418,7 +435,7 @@ func getSubEvent(msg sdk.Msg, lf types.LogFormat) (se structs.SubsetEvent, err e
 				return se, er
 			}
 			for _, subMsg := range msgs {
-				subEv, subErr := getSubEvent(subMsg, lf)
+				subEv, subErr := getSubEvent(subMsg, lf, opts)
 				if subErr != nil {
 					return se, err
 				}
@@ -461,13 +478,13 @@ func getSubEvent(msg sdk.Msg, lf types.LogFormat) (se structs.SubsetEvent, err e
 	case "wasm":
 		switch msg.Type() {
 		case "execute_contract":
-			return mapper.WasmExecuteContractToSub(msg)
+			return mapper.WasmExecuteContractToSub(msg, lf, opts)
 		case "store_code":
-			return mapper.WasmStoreCodeToSub(msg)
+			return mapper.WasmStoreCodeToSub(msg, lf, opts)
 		case "update_contract_owner":
 			return mapper.WasmMsgUpdateContractOwnerToSub(msg)
 		case "instantiate_contract":
-			return mapper.WasmMsgInstantiateContractToSub(msg)
+			return mapper.WasmMsgInstantiateContractToSub(msg, lf)
 		case "migrate_contract":
 			return mapper.WasmMsgMigrateContractToSub(msg)
 		}
@@ -501,9 +518,34 @@ func (c *Client) SingularHeightWorker(ctx context.Context, wg *sync.WaitGroup, o
 
 // SearchTxSingularHeight is making search api call for
 func (c *Client) SearchTxSingularHeight(ctx context.Context, height uint64, page, perPage int) (txSearch []types.TxResponse, err error) {
+	txSearch, _, err = c.searchTx(ctx, `"tx.height=`+strconv.FormatUint(height, 10)+`"`, page, perPage)
+	return txSearch, err
+}
+
+// SearchTxByQuery is making search api call for transactions matching given event query (eg. `vote.voter='terravaloper1...'`)
+// in given height range. It returns found transactions along with the number of all matching transactions
+func (c *Client) SearchTxByQuery(ctx context.Context, query string, hr structs.HeightRange, page, perPage int) (txSearch []types.TxResponse, totalCount uint64, err error) {
+	s := strings.Builder{}
+	s.WriteString(`"`)
+	s.WriteString(query)
+	if hr.StartHeight > 0 {
+		s.WriteString(" AND tx.height>=")
+		s.WriteString(strconv.FormatUint(hr.StartHeight, 10))
+	}
+	if hr.EndHeight > 0 {
+		s.WriteString(" AND tx.height<=")
+		s.WriteString(strconv.FormatUint(hr.EndHeight, 10))
+	}
+	s.WriteString(`"`)
+
+	return c.searchTx(ctx, s.String(), page, perPage)
+}
+
+// searchTx makes tx_search call with quoted query, returning page of transactions and the number of all matching transactions
+func (c *Client) searchTx(ctx context.Context, query string, page, perPage int) (txSearch []types.TxResponse, totalCount uint64, err error) {
 	req, err := http.NewRequest(http.MethodGet, c.baseURL+"/tx_search", nil)
 	if err != nil {
-		return txSearch, err
+		return txSearch, 0, err
 	}
 
 	req.Header.Add("Content-Type", "application/json")
@@ -512,13 +554,7 @@ func (c *Client) SearchTxSingularHeight(ctx context.Context, height uint64, page
 	}
 
 	q := req.URL.Query()
-
-	s := strings.Builder{}
-	s.WriteString(`"tx.height=`)
-	s.WriteString(strconv.FormatUint(height, 10))
-	s.WriteString(`"`)
-
-	q.Add("query", s.String())
+	q.Add("query", query)
 	q.Add("page", strconv.Itoa(page))
 	q.Add("per_page", strconv.Itoa(perPage))
 	req.URL.RawQuery = q.Encode()
@@ -526,23 +562,22 @@ func (c *Client) SearchTxSingularHeight(ctx context.Context, height uint64, page
 	if c.rateLimiter != nil {
 		err = c.rateLimiter.Wait(ctx)
 		if err != nil {
-			return txSearch, err
+			return txSearch, 0, err
 		}
 	}
 
 	now := time.Now()
 	resp, err := c.httpClient.Do(req)
-
 	if err != nil {
-		return txSearch, err
+		return txSearch, 0, err
 	}
+	defer resp.Body.Close()
 
 	if resp.StatusCode > 399 { // ERROR
 		serverError, _ := ioutil.ReadAll(resp.Body)
 
 		c.logger.Error("[TERRA-API] error getting response from server", zap.Int("code", resp.StatusCode), zap.Any("response", string(serverError)))
-		err := fmt.Errorf("error getting response from server %d %s", resp.StatusCode, string(serverError))
-		return txSearch, err
+		return txSearch, 0, fmt.Errorf("error getting response from server %d %s", resp.StatusCode, string(serverError))
 	}
 
 	rawRequestHTTPDuration.WithLabels("/tx_search", resp.Status).Observe(time.Since(now).Seconds())
@@ -552,23 +587,23 @@ func (c *Client) SearchTxSingularHeight(ctx context.Context, height uint64, page
 	result := &types.GetTxSearchResponse{}
 	if err = decoder.Decode(result); err != nil {
 		c.logger.Error("[TERRA-API] unable to decode result body", zap.Error(err))
-		err = fmt.Errorf("unable to decode result body %w", err)
-		return txSearch, err
+		return txSearch, 0, fmt.Errorf("unable to decode result body %w", err)
 	}
 
 	if result.Error.Message != "" {
 		c.logger.Error("[TERRA-API] Error getting search", zap.Any("result", result.Error.Message))
-		err := fmt.Errorf("Error getting search: %s", result.Error.Message)
-		return txSearch, err
+		return txSearch, 0, fmt.Errorf("Error getting search: %s", result.Error.Message)
 	}
 
 	if result.Result.TotalCount != "" {
+		totalCount, err = strconv.ParseUint(result.Result.TotalCount, 10, 64)
 		if err != nil {
 			c.logger.Error("[TERRA-API] Error getting totalCount", zap.Error(err), zap.Any("result", result), zap.String("query", req.URL.RawQuery))
-			return txSearch, err
+			return txSearch, 0, err
 		}
 	}
-	return result.Result.Txs, err
+
+	return result.Result.Txs, totalCount, nil
 }
 
 // GetFromRaw returns raw data for plugin use;
@@ -609,10 +644,69 @@ func (c *Client) GetEventsFromRaw(logger *zap.Logger, txReader, txLogReader io.R
 	}
 
 	trans := structs.Transaction{}
-	appendEvents(logger, &trans, tx, txLog, TxLogError{})
+	appendEvents(logger, c.mapperOptions, &trans, tx, txLog, TxLogError{})
 	return trans.Events, nil
 }
 
+// GetTransactionFromRaw returns transaction converted from raw transaction and its log for plugin use.
+// Raw data has no gas used, so it's left empty, gas wanted is the gas limit of the fee
+func (c *Client) GetTransactionFromRaw(logger *zap.Logger, txReader, txLogReader io.Reader, height uint64, block structs.Block) (structs.Transaction, error) {
+	txData, err := ioutil.ReadAll(txReader)
+	if err != nil {
+		return structs.Transaction{}, err
+	}
+	txData = bytes.TrimSpace(txData)
+	bz, err := base64.StdEncoding.DecodeString(string(txData))
+	if err != nil {
+		return structs.Transaction{}, fmt.Errorf("[TERRA-API] Error decoding raw transaction: %w", err)
+	}
+	tx := &auth.StdTx{}
+	if err := c.cdc.UnmarshalBinaryLengthPrefixed(bz, tx); err != nil {
+		logger.Error("[TERRA-API] Problem decoding raw transaction (cdc) ", zap.Error(err))
+		return structs.Transaction{}, err
+	}
+
+	txLog, err := ioutil.ReadAll(txLogReader)
+	if err != nil {
+		return structs.Transaction{}, err
+	}
+	lf, txErr := decodeTxLog(string(txLog))
+
+	hash := sha256.Sum256(bz)
+	txRaw := types.TxResponse{
+		Hash:   fmt.Sprintf("%X", hash[:]),
+		Height: strconv.FormatUint(height, 10),
+		TxData: string(txData),
+		TxResult: types.ResponseDeliverTx{
+			Log:       string(txLog),
+			GasWanted: strconv.FormatUint(tx.Fee.Gas, 10),
+			GasUsed:   "0",
+		},
+	}
+
+	out, err := rawToTransaction(logger, c.cdc, c.mapperOptions, txRaw, lf, txErr, map[uint64]structs.Block{height: block})
+	if err != nil {
+		return structs.Transaction{}, err
+	}
+	if out.Error != nil {
+		return structs.Transaction{}, out.Error
+	}
+	return out.Payload.(structs.Transaction), nil
+}
+
+// decodeTxLog decodes log of transaction, log that is not json is the error message of failed transaction
+func decodeTxLog(log string) (lf []types.LogFormat, txErr TxLogError) {
+	lf = []types.LogFormat{}
+	if log == "" {
+		return lf, txErr
+	}
+	if err := json.Unmarshal([]byte(log), &lf); err != nil {
+		lf = []types.LogFormat{}
+		txErr.Message = log
+	}
+	return lf, txErr
+}
+
 func findLog(lf []types.LogFormat, index int) types.LogFormat {
 	if len(lf) <= index {
 		return types.LogFormat{}
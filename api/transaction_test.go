@@ -1,58 +1,181 @@
 package api
 
-/*
-func Test_rawToTransaction(t *testing.T) {
+import (
+	"encoding/json"
+	"flag"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/figment-networks/indexer-manager/structs"
+	cStruct "github.com/figment-networks/indexer-manager/worker/connectivity/structs"
+	"github.com/figment-networks/terra-worker/api/mapper"
+	"github.com/figment-networks/terra-worker/api/types"
+	"github.com/stretchr/testify/require"
+	"github.com/terra-project/core/app"
+	"go.uber.org/zap/zaptest"
+)
+
+var update = flag.Bool("update", false, "update golden files in testdata/golden")
+
+// goldenContracts returns registry with contracts of fixtures
+func goldenContracts(t *testing.T) *mapper.ContractRegistry {
+	cr := mapper.NewContractRegistry()
+	require.NoError(t, cr.AddContract("terra1ejpjr43ht3y56pplm5pxpusmcrk9rkkv09x0fz", "terraswap_pair"))
+	require.NoError(t, cr.AddContract("terra183rfa8tvtp6ax7jr7dfaf7ywv870sykx4zvgjs", "cw20"))
+	return cr
+}
+
+// TestRawToTransactionGolden converts every tx_search fixture from testdata/tx_search/<chain_id>/
+// and compares the result with testdata/golden/<chain_id>/.
+// Run with `-update` flag to regenerate golden files after intended mapper changes.
+func TestRawToTransactionGolden(t *testing.T) {
 	InitMetrics()
-	type args struct {
-		c      *Client
-		txRaw  TxResponse
-		txLog  []LogFormat
-		blocks map[uint64]shared.Block
+	cdc := app.MakeCodec()
+	opts := mapper.Options{Contracts: goldenContracts(t)}
+
+	fixtures, err := filepath.Glob(filepath.Join("testdata", "tx_search", "*", "*.json"))
+	require.NoError(t, err)
+	require.NotEmpty(t, fixtures)
+
+	for _, fixture := range fixtures {
+		chainID := filepath.Base(filepath.Dir(fixture))
+		name := strings.TrimSuffix(filepath.Base(fixture), ".json")
+
+		t.Run(chainID+"/"+name, func(t *testing.T) {
+			b, err := ioutil.ReadFile(fixture)
+			require.NoError(t, err)
+
+			result := &types.GetTxSearchResponse{}
+			require.NoError(t, json.Unmarshal(b, result))
+
+			out := make(chan cStruct.OutResp, len(result.Result.Txs))
+			err = RawToTransaction(zaptest.NewLogger(t), cdc, opts, result.Result.Txs, goldenBlocks(t, chainID, result.Result.Txs), out)
+			require.NoError(t, err)
+			close(out)
+
+			var txs []structs.Transaction
+			for o := range out {
+				require.NoError(t, o.Error)
+				tx := o.Payload.(structs.Transaction)
+				requireAmountsMatchText(t, tx)
+				requireBalanceChangesNetZero(t, tx)
+				txs = append(txs, tx)
+			}
+
+			got, err := json.MarshalIndent(txs, "", "  ")
+			require.NoError(t, err)
+
+			golden := filepath.Join("testdata", "golden", chainID, name+".json")
+			if *update {
+				require.NoError(t, os.MkdirAll(filepath.Dir(golden), 0755))
+				require.NoError(t, ioutil.WriteFile(golden, append(got, '\n'), 0644))
+				return
+			}
+
+			want, err := ioutil.ReadFile(golden)
+			require.NoError(t, err, "golden file missing, run tests with -update flag")
+			require.JSONEq(t, string(want), string(got))
+		})
 	}
-	tests := []struct {
-		name     string
-		filename string
-		args     args
-		want     cStruct.OutResp
-		wantErr  bool
-	}{
-		{
-			name:     "test2",
-			filename: "./test/test2.json",
-			args: args{
-				c:      NewClient("", "", zaptest.NewLogger(t), nil, 0),
-				blocks: map[uint64]shared.Block{340209: {}},
-			},
-		},
+}
+
+// requireAmountsMatchText checks that every amount of transaction holds Numeric / 10^Exp == Text.
+// Amounts without Numeric (eg. denom of oracle prevote) have no Text either
+func requireAmountsMatchText(t *testing.T, tx structs.Transaction) {
+	t.Helper()
+
+	amounts := append([]structs.TransactionAmount{}, tx.Fee...)
+	for _, ev := range tx.Events {
+		for _, sub := range ev.Sub {
+			for _, am := range sub.Amount {
+				amounts = append(amounts, am)
+			}
+			transfers := append(append([]structs.EventTransfer{}, sub.Sender...), sub.Recipient...)
+			for _, tr := range sub.Transfers {
+				transfers = append(transfers, tr...)
+			}
+			for _, tr := range transfers {
+				amounts = append(amounts, tr.Amounts...)
+			}
+		}
+	}
+
+	for _, am := range amounts {
+		if am.Numeric == nil {
+			require.Empty(t, am.Text, "amount %+v without numeric", am)
+			continue
+		}
+		text, ok := new(big.Rat).SetString(am.Text)
+		require.True(t, ok, "amount text %q is not a number", am.Text)
+		numeric := new(big.Rat).SetFrac(am.Numeric, new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(am.Exp)), nil))
+		require.Zero(t, text.Cmp(numeric), "amount %s%s has numeric %s and exp %d", am.Text, am.Currency, am.Numeric, am.Exp)
+	}
+}
+
+// goldenBlocks returns stable block metadata for heights of transactions
+func goldenBlocks(t *testing.T, chainID string, txs []types.TxResponse) map[uint64]structs.Block {
+	blocks := map[uint64]structs.Block{}
+	for _, tx := range txs {
+		h, err := strconv.ParseUint(tx.Height, 10, 64)
+		require.NoError(t, err)
+		blocks[h] = structs.Block{
+			Hash:    "BLOCK" + tx.Height,
+			Height:  h,
+			Time:    time.Date(2021, 6, 1, 0, 0, 0, 0, time.UTC).Add(time.Duration(h) * time.Second),
+			ChainID: chainID,
+		}
 	}
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
+	return blocks
+}
+
+// TestClient_GetTransactionFromRaw checks that transactions decoded from raw data and log
+// are the same as converted from tx_search, apart from the data not present in raw transaction
+func TestClient_GetTransactionFromRaw(t *testing.T) {
+	InitMetrics()
+	cli := NewClient("", "", nil, nil, 0)
+
+	fixtures, err := filepath.Glob(filepath.Join("testdata", "tx_search", "*", "*.json"))
+	require.NoError(t, err)
+	require.NotEmpty(t, fixtures)
+
+	for _, fixture := range fixtures {
+		chainID := filepath.Base(filepath.Dir(fixture))
+		name := strings.TrimSuffix(filepath.Base(fixture), ".json")
 
-			a, err := os.Open(tt.filename)
-			log.Println("err", err)
-			defer a.Close()
-			b := json.NewDecoder(a)
-			result := &GetTxSearchResponse{}
-			err = b.Decode(result)
+		t.Run(chainID+"/"+name, func(t *testing.T) {
+			b, err := ioutil.ReadFile(fixture)
+			require.NoError(t, err)
 
-			readr := strings.NewReader("")
-			dec := json.NewDecoder(readr)
+			result := &types.GetTxSearchResponse{}
+			require.NoError(t, json.Unmarshal(b, result))
+			blocks := goldenBlocks(t, chainID, result.Result.Txs)
+
+			out := make(chan cStruct.OutResp, len(result.Result.Txs))
+			require.NoError(t, RawToTransaction(zaptest.NewLogger(t), cli.CDC(), mapper.Options{}, result.Result.Txs, blocks, out))
+			close(out)
 
 			for _, txRaw := range result.Result.Txs {
-				readr.Reset(txRaw.TxResult.Log)
-				lf := []LogFormat{}
-				err := dec.Decode(&lf)
-
-				got, err := rawToTransaction(tt.args.c, txRaw, lf, tt.args.blocks)
-				if (err != nil) != tt.wantErr {
-					t.Errorf("rawToTransaction() error = %v, wantErr %v", err, tt.wantErr)
-					return
-				}
-				if !reflect.DeepEqual(got, tt.want) {
-					t.Errorf("rawToTransaction() = %v, want %v", got, tt.want)
-				}
+				want := (<-out).Payload.(structs.Transaction)
+				want.GasUsed = 0
+
+				height, err := strconv.ParseUint(txRaw.Height, 10, 64)
+				require.NoError(t, err)
+				got, err := cli.GetTransactionFromRaw(zaptest.NewLogger(t), strings.NewReader(txRaw.TxData), strings.NewReader(txRaw.TxResult.Log), height, blocks[height])
+				require.NoError(t, err)
+
+				// hash of raw transaction is computed the same way as reported by node
+				require.Equal(t, txRaw.Hash, got.Hash)
+				require.Equal(t, want, got)
 			}
 		})
 	}
+
+	_, err = cli.GetTransactionFromRaw(zaptest.NewLogger(t), strings.NewReader("invalid"), strings.NewReader(""), 1, structs.Block{})
+	require.Error(t, err)
 }
-*/
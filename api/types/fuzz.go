@@ -0,0 +1,68 @@
+//go:build gofuzz
+// +build gofuzz
+
+package types
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+)
+
+// Fuzz targets for go-fuzz (https://github.com/dvyukov/go-fuzz), built with:
+//
+//	go-fuzz-build -func FuzzLogFormat ./api/types && go-fuzz -bin types-fuzz.zip -workdir fuzz/logs
+//
+// Targets panic when unmarshalled logs break invariants, inputs found this way are added
+// to the table tests of types_test.go, which stay the regression corpus.
+
+// Fuzz runs all unmarshalling targets of the package
+func Fuzz(data []byte) int {
+	return FuzzAttributes(data) + FuzzLogFormat(data)
+}
+
+// FuzzAttributes checks that every attribute is kept in order and that attributes
+// marshalled back as key value pairs unmarshal into the same value
+func FuzzAttributes(data []byte) int {
+	lea := &TxEventsAttributes{}
+	if err := json.Unmarshal(data, lea); err != nil {
+		return 0
+	}
+
+	kvs := make([]kvHolder, 0, len(lea.Ordered))
+	for _, tag := range lea.Ordered {
+		kvs = append(kvs, kvHolder{Key: tag.Key, Value: tag.Value})
+	}
+	b, err := json.Marshal(kvs)
+	if err != nil {
+		panic(fmt.Sprintf("attributes of %q don't marshal: %s", data, err))
+	}
+
+	lea2 := &TxEventsAttributes{}
+	if err := json.Unmarshal(b, lea2); err != nil {
+		panic(fmt.Sprintf("attributes %s of %q don't unmarshal: %s", b, data, err))
+	}
+	if !reflect.DeepEqual(lea, lea2) {
+		panic(fmt.Sprintf("attributes of %q changed after unmarshalling %s again", data, b))
+	}
+	return 1
+}
+
+// FuzzLogFormat checks that logs of message don't fail on attributes of any shape
+func FuzzLogFormat(data []byte) int {
+	var logs []LogFormat
+	if err := json.Unmarshal(data, &logs); err != nil {
+		return 0
+	}
+	for _, l := range logs {
+		for _, ev := range l.Events {
+			if ev.Attributes == nil {
+				continue
+			}
+			if len(ev.Attributes.Ordered) == 0 && (len(ev.Attributes.Others) > 0 || len(ev.Attributes.Amount) > 0) {
+				panic(fmt.Sprintf("attributes of %q event %q not kept in order", data, ev.Type))
+			}
+		}
+	}
+	return 1
+}
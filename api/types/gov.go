@@ -0,0 +1,47 @@
+package types
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// GovProposal is terra lcd representation of governance proposal
+type GovProposal struct {
+	ID               string     `json:"id"`
+	Content          GovContent `json:"content"`
+	Status           string     `json:"proposal_status"`
+	FinalTallyResult GovTally   `json:"final_tally_result"`
+	SubmitTime       time.Time  `json:"submit_time"`
+	DepositEndTime   time.Time  `json:"deposit_end_time"`
+	TotalDeposit     []Balance  `json:"total_deposit"`
+	VotingStartTime  time.Time  `json:"voting_start_time"`
+	VotingEndTime    time.Time  `json:"voting_end_time"`
+}
+
+// GovContent is amino encoded proposal content
+type GovContent struct {
+	Type  string          `json:"type"`
+	Value json.RawMessage `json:"value"`
+}
+
+// GovTally is result of proposal voting
+type GovTally struct {
+	Yes        string `json:"yes"`
+	Abstain    string `json:"abstain"`
+	No         string `json:"no"`
+	NoWithVeto string `json:"no_with_veto"`
+}
+
+// GovVote is single vote on the proposal
+type GovVote struct {
+	ProposalID string `json:"proposal_id"`
+	Voter      string `json:"voter"`
+	Option     string `json:"option"`
+}
+
+// GovDeposit is single deposit on the proposal
+type GovDeposit struct {
+	ProposalID string    `json:"proposal_id"`
+	Depositor  string    `json:"depositor"`
+	Amount     []Balance `json:"amount"`
+}
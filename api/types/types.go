@@ -3,6 +3,7 @@ package types
 import (
 	"bytes"
 	"encoding/json"
+	"fmt"
 
 	sdk "github.com/cosmos/cosmos-sdk/types"
 )
@@ -125,6 +126,8 @@ type GetBlockchainResponse struct {
 
 type LogFormatLog struct {
 	Error
+	// Tax is stability tax charged for message (columbus-3 bank send and swapsend)
+	Tax string `json:"tax,omitempty"`
 }
 
 type LogFormat struct {
@@ -180,6 +183,10 @@ type TxEventsAttributes struct {
 	Denom []string
 
 	Others map[string][]string
+
+	// Ordered keeps all attributes in the order of appearance,
+	// needed for events that group attributes by repeated keys (eg. `from_contract`)
+	Ordered []TxTags
 }
 
 type kvHolder struct {
@@ -191,20 +198,28 @@ type kvHolder struct {
 // to be able to parse it later more easily
 // thats fulfillment of json.Unmarshaler inferface
 func (lea *TxEventsAttributes) UnmarshalJSON(b []byte) error {
+	if string(b) == "null" {
+		return nil
+	}
 	dec := json.NewDecoder(bytes.NewReader(b))
-	kc := &kvHolder{}
 
 	// read open bracket
-	_, err := dec.Token()
+	t, err := dec.Token()
 	if err != nil {
 		return err
 	}
+	if t != json.Delim('[') {
+		return fmt.Errorf("attributes should be an array, got %v", t)
+	}
 
 	for dec.More() {
+		// new holder for every attribute, so missing value is not taken from the previous one
+		kc := &kvHolder{}
 		err := dec.Decode(kc)
 		if err != nil {
 			return err
 		}
+		lea.Ordered = append(lea.Ordered, TxTags{Key: kc.Key, Value: kc.Value})
 
 		switch kc.Key {
 		case "validator", "destination_validator", "source_validator":
@@ -245,10 +260,13 @@ func (lea *TxEventsAttributes) UnmarshalJSON(b []byte) error {
 		}
 	}
 	// read closing bracket
-	_, err = dec.Token()
+	t, err = dec.Token()
 	if err != nil {
 		return err
 	}
+	if t != json.Delim(']') {
+		return fmt.Errorf("attributes should be an array, got %v", t)
+	}
 
 	return nil
 }
@@ -0,0 +1,218 @@
+package types
+
+import (
+	"encoding/json"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTxEventsAttributes_UnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		in      string
+		want    TxEventsAttributes
+		wantErr bool
+	}{
+		{
+			name: "transfer",
+			in:   `[{"key":"recipient","value":"terra1a"},{"key":"sender","value":"terra1b"},{"key":"amount","value":"5uluna"}]`,
+			want: TxEventsAttributes{
+				Recipient: []string{"terra1a"},
+				Sender:    []string{"terra1b"},
+				Amount:    []string{"5uluna"},
+				Ordered:   []TxTags{{Key: "recipient", Value: "terra1a"}, {Key: "sender", Value: "terra1b"}, {Key: "amount", Value: "5uluna"}},
+			},
+		},
+		{
+			name: "missing value is not taken from previous attribute",
+			in:   `[{"key":"sender","value":"terra1b"},{"key":"recipient"}]`,
+			want: TxEventsAttributes{
+				Sender:    []string{"terra1b"},
+				Recipient: []string{""},
+				Ordered:   []TxTags{{Key: "sender", Value: "terra1b"}, {Key: "recipient"}},
+			},
+		},
+		{
+			name: "empty",
+			in:   `[]`,
+		},
+		{
+			name: "null",
+			in:   `null`,
+		},
+		{
+			name:    "object",
+			in:      `{"key":"sender","value":"terra1b"}`,
+			wantErr: true,
+		},
+		{
+			name:    "string",
+			in:      `"sender"`,
+			wantErr: true,
+		},
+		{
+			name:    "array of strings",
+			in:      `["sender"]`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := TxEventsAttributes{}
+			err := json.Unmarshal([]byte(tt.in), &got)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+// TestTxEventsAttributes_UnmarshalJSONProperties checks that random attribute lists are kept in order
+// and every attribute lands in the bucket of its key
+func TestTxEventsAttributes_UnmarshalJSONProperties(t *testing.T) {
+	keys := []string{"sender", "recipient", "amount", "voter", "feeder", "validator", "source_validator", "destination_validator", "contract_address", "from_contract", ""}
+	r := rand.New(rand.NewSource(1))
+
+	for i := 0; i < 1000; i++ {
+		in := make([]TxTags, r.Intn(20))
+		for j := range in {
+			in[j] = TxTags{Key: keys[r.Intn(len(keys))], Value: randomValue(r)}
+		}
+
+		b, err := json.Marshal(in)
+		require.NoError(t, err)
+
+		got := TxEventsAttributes{}
+		require.NoError(t, json.Unmarshal(b, &got))
+
+		if len(in) == 0 {
+			require.Empty(t, got.Ordered)
+			continue
+		}
+		require.Equal(t, in, got.Ordered)
+
+		require.Equal(t, valuesOf(in, "sender"), got.Sender)
+		require.Equal(t, valuesOf(in, "recipient"), got.Recipient)
+		require.Equal(t, valuesOf(in, "amount"), got.Amount)
+		require.Equal(t, valuesOf(in, "voter"), got.Voter)
+		require.Equal(t, valuesOf(in, "feeder"), got.Feeder)
+		for _, k := range []string{"validator", "source_validator", "destination_validator"} {
+			require.Equal(t, valuesOf(in, k), got.Validator[k])
+		}
+		for _, k := range []string{"contract_address", "from_contract", ""} {
+			require.Equal(t, valuesOf(in, k), got.Others[k])
+		}
+	}
+}
+
+// TestTxEventsAttributes_ReUnmarshal checks that attributes marshalled from Ordered unmarshal into the same buckets
+func TestTxEventsAttributes_ReUnmarshal(t *testing.T) {
+	tests := []struct {
+		name    string
+		in      string
+		wantErr bool
+	}{
+		{name: "transfer", in: `[{"key":"recipient","value":"terra1a"},{"key":"sender","value":"terra1b"},{"key":"amount","value":"5uluna"}]`},
+		{name: "missing value", in: `[{"key":"sender","value":"terra1b"},{"key":"recipient"}]`},
+		{name: "validator and others", in: `[{"key":"validator","value":"terravaloper1a"},{"key":"from_contract","value":"terra1c"}]`},
+		{name: "escaped", in: `[{"key":"action","value":"\"\\/\u00e9\n"},{"key":"","value":""}]`},
+		{name: "empty", in: `[]`},
+		{name: "object", in: `{}`, wantErr: true},
+		{name: "strings", in: `["sender"]`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := TxEventsAttributes{}
+			err := json.Unmarshal([]byte(tt.in), &got)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			b, err := json.Marshal(got.Ordered)
+			require.NoError(t, err)
+			again := TxEventsAttributes{}
+			require.NoError(t, json.Unmarshal(b, &again))
+			require.Equal(t, got, again)
+		})
+	}
+}
+
+func TestLogFormat_UnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		in      string
+		want    []LogFormat
+		wantErr bool
+	}{
+		{
+			name: "events",
+			in:   `[{"msg_index":0,"success":true,"log":"","events":[{"type":"message","attributes":[{"key":"action","value":"send"}]}]}]`,
+		},
+		{
+			name: "tax",
+			in:   `[{"msg_index":1,"log":"{\"tax\":\"1uluna\"}","events":[]}]`,
+			want: []LogFormat{{MsgIndex: 1, Log: LogFormatLog{Tax: "1uluna"}, Events: []TxEvents{}}},
+		},
+		{
+			name: "error",
+			in:   `[{"msg_index":0,"log":"{\"codespace\":\"sdk\",\"code\":5}"}]`,
+			want: []LogFormat{{Log: LogFormatLog{Error: Error{CodeSpace: "sdk", Code: 5}}}},
+		},
+		{name: "not json log", in: `[{"log":"not json"}]`, wantErr: true},
+		{name: "empty", in: `[]`, want: []LogFormat{}},
+		{name: "not a list", in: `{"log":""}`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []LogFormat
+			err := json.Unmarshal([]byte(tt.in), &got)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			if tt.want != nil {
+				require.Equal(t, tt.want, got)
+			}
+
+			var raw []logFormat
+			require.NoError(t, json.Unmarshal([]byte(tt.in), &raw))
+			require.Len(t, got, len(raw))
+			for i := range got {
+				require.Equal(t, raw[i].MsgIndex, got[i].MsgIndex)
+				require.Equal(t, raw[i].Success, got[i].Success)
+				require.Equal(t, raw[i].Events, got[i].Events)
+			}
+		})
+	}
+}
+
+func valuesOf(tags []TxTags, key string) (values []string) {
+	for _, tag := range tags {
+		if tag.Key == key {
+			values = append(values, tag.Value)
+		}
+	}
+	return values
+}
+
+// randomValue returns string with characters that need escaping in json
+func randomValue(r *rand.Rand) string {
+	const alphabet = "abcterra0123456789\"\\/ \né "
+	runes := []rune(alphabet)
+	b := make([]rune, r.Intn(10))
+	for i := range b {
+		b[i] = runes[r.Intn(len(runes))]
+	}
+	return string(b)
+}
@@ -0,0 +1,241 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/figment-networks/indexer-manager/structs"
+	cStruct "github.com/figment-networks/indexer-manager/worker/connectivity/structs"
+	"github.com/figment-networks/terra-worker/api/mapper"
+	"go.uber.org/zap"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// lunaDenom is the base of oracle exchange rates, every rate is a price of one luna
+const lunaDenom = "uluna"
+
+// RatesSource provides oracle exchange rates at given height
+type RatesSource interface {
+	GetExchangeRates(ctx context.Context, height uint64) (sdk.DecCoins, error)
+}
+
+// ValuedTransaction is transaction with fiat values of its transfers and fee.
+// It marshals into transaction json extended with `valuation`
+type ValuedTransaction struct {
+	structs.Transaction
+	Valuation Valuation `json:"valuation"`
+}
+
+// Valuation holds values of transaction amounts in every requested currency,
+// calculated with oracle exchange rates at the transaction height
+type Valuation struct {
+	Currencies []string         `json:"currencies"`
+	Fee        []ValuedAmount   `json:"fee,omitempty"`
+	Transfers  []ValuedTransfer `json:"transfers,omitempty"`
+	// Error is set when exchange rates could not be fetched, values other than in the amount currency are then marked as unavailable
+	Error string `json:"error,omitempty"`
+}
+
+// ValuedTransfer is transfer of subevent (`Events[Event].Sub[Sub].Transfers[Type]`) with values of its amounts
+type ValuedTransfer struct {
+	Event   int            `json:"event"`
+	Sub     int            `json:"sub"`
+	Type    string         `json:"type"`
+	Account string         `json:"account"`
+	Amounts []ValuedAmount `json:"amounts"`
+}
+
+// ValuedAmount is amount with its value in every requested currency (in the same order)
+type ValuedAmount struct {
+	Amount structs.TransactionAmount `json:"amount"`
+	Values []FiatValue               `json:"values"`
+}
+
+// FiatValue is value of amount in single currency.
+// RateUnavailable marks currencies (or amount denominations) not quoted by oracle at the height
+type FiatValue struct {
+	structs.TransactionAmount
+	RateUnavailable bool `json:"rate_unavailable,omitempty"`
+}
+
+// Valuer calculates fiat values of transactions, caching exchange rates per height
+type Valuer struct {
+	source     RatesSource
+	currencies []string
+
+	lock      sync.Mutex
+	cache     map[uint64]*ratesEntry
+	heights   []uint64
+	cacheSize int
+}
+
+type ratesEntry struct {
+	ready chan struct{}
+	rates map[string]sdk.Dec
+	err   error
+}
+
+// NewValuer is Valuer constructor. Currencies are denominations of values (eg. `uusd`, `ukrw`),
+// exchange rates of at most cacheSize recent heights are kept
+func NewValuer(source RatesSource, currencies []string, cacheSize int) *Valuer {
+	if cacheSize < 1 {
+		cacheSize = 1
+	}
+	return &Valuer{
+		source:     source,
+		currencies: currencies,
+		cache:      map[uint64]*ratesEntry{},
+		cacheSize:  cacheSize,
+	}
+}
+
+// ValueTransactionsCh values transactions passing from in to out, other responses are passed unchanged.
+// It's the enrichment stage following RawToTransactionCh
+func ValueTransactionsCh(ctx context.Context, logger *zap.Logger, v *Valuer, in <-chan cStruct.OutResp, out chan<- cStruct.OutResp) {
+	EnrichCh(ctx, in, out, func(resp cStruct.OutResp) cStruct.OutResp {
+		if tx, ok := resp.Payload.(structs.Transaction); ok {
+			vtx, err := v.ValueTransaction(ctx, tx)
+			if err != nil {
+				logger.Error("[TERRA-API] Problem valuing transaction", zap.Error(err), zap.Uint64("height", tx.Height), zap.String("hash", tx.Hash))
+			}
+			resp.Payload = vtx
+		}
+		return resp
+	})
+}
+
+// ValueTransaction values fee and transfers of transaction with exchange rates at its height.
+// When rates can't be fetched, transaction is returned with values marked as unavailable and the error
+func (v *Valuer) ValueTransaction(ctx context.Context, tx structs.Transaction) (vtx ValuedTransaction, err error) {
+	vtx = ValuedTransaction{
+		Transaction: tx,
+		Valuation:   Valuation{Currencies: v.currencies},
+	}
+
+	rates, err := v.rates(ctx, tx.Height)
+	if err != nil {
+		vtx.Valuation.Error = err.Error()
+	}
+
+	for _, am := range tx.Fee {
+		vtx.Valuation.Fee = append(vtx.Valuation.Fee, v.valueAmount(rates, am))
+	}
+
+	for i, ev := range tx.Events {
+		for j, sub := range ev.Sub {
+			for _, typ := range sortedKeys(sub.Transfers) {
+				for _, tr := range sub.Transfers[typ] {
+					vt := ValuedTransfer{Event: i, Sub: j, Type: typ, Account: tr.Account.ID}
+					for _, am := range tr.Amounts {
+						vt.Amounts = append(vt.Amounts, v.valueAmount(rates, am))
+					}
+					vtx.Valuation.Transfers = append(vtx.Valuation.Transfers, vt)
+				}
+			}
+		}
+	}
+
+	return vtx, err
+}
+
+// sortedKeys returns transfer types in stable order
+func sortedKeys(transfers map[string][]structs.EventTransfer) []string {
+	keys := make([]string, 0, len(transfers))
+	for k := range transfers {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func (v *Valuer) valueAmount(rates map[string]sdk.Dec, am structs.TransactionAmount) ValuedAmount {
+	va := ValuedAmount{Amount: am, Values: make([]FiatValue, 0, len(v.currencies))}
+	for _, currency := range v.currencies {
+		value, ok := convertAmount(rates, am, currency)
+		if !ok {
+			va.Values = append(va.Values, FiatValue{
+				TransactionAmount: structs.TransactionAmount{Currency: currency},
+				RateUnavailable:   true,
+			})
+			continue
+		}
+		va.Values = append(va.Values, FiatValue{TransactionAmount: mapper.DecAmount(currency, value)})
+	}
+	return va
+}
+
+// convertAmount converts amount into currency through luna exchange rates, returns false when any rate is missing
+func convertAmount(rates map[string]sdk.Dec, am structs.TransactionAmount, currency string) (value sdk.Dec, ok bool) {
+	if am.Numeric == nil || am.Exp < 0 || am.Exp > sdk.Precision {
+		return value, false
+	}
+	amount := sdk.NewDecFromBigIntWithPrec(am.Numeric, int64(am.Exp))
+	if am.Currency == currency {
+		return amount, true
+	}
+
+	from, ok := lunaRate(rates, am.Currency)
+	if !ok {
+		return value, false
+	}
+	to, ok := lunaRate(rates, currency)
+	if !ok {
+		return value, false
+	}
+	return amount.Mul(to).Quo(from), true
+}
+
+func lunaRate(rates map[string]sdk.Dec, denom string) (sdk.Dec, bool) {
+	if denom == lunaDenom {
+		return sdk.OneDec(), true
+	}
+	rate, ok := rates[denom]
+	if !ok || !rate.IsPositive() {
+		return rate, false
+	}
+	return rate, true
+}
+
+// rates returns exchange rates at height, fetching them only once for concurrent callers.
+// Failed fetches are not cached, heights are counted in the cache size once their rates are fetched
+func (v *Valuer) rates(ctx context.Context, height uint64) (map[string]sdk.Dec, error) {
+	v.lock.Lock()
+	e, ok := v.cache[height]
+	if ok {
+		v.lock.Unlock()
+		select {
+		case <-e.ready:
+			return e.rates, e.err
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	e = &ratesEntry{ready: make(chan struct{})}
+	v.cache[height] = e
+	v.lock.Unlock()
+
+	coins, err := v.source.GetExchangeRates(ctx, height)
+	v.lock.Lock()
+	if err != nil {
+		e.err = fmt.Errorf("error fetching exchange rates at height %d: %w", height, err)
+		delete(v.cache, height)
+	} else {
+		e.rates = make(map[string]sdk.Dec, len(coins))
+		for _, c := range coins {
+			e.rates[c.Denom] = c.Amount
+		}
+		v.heights = append(v.heights, height)
+		if len(v.heights) > v.cacheSize {
+			delete(v.cache, v.heights[0])
+			v.heights = v.heights[1:]
+		}
+	}
+	v.lock.Unlock()
+	close(e.ready)
+
+	return e.rates, e.err
+}
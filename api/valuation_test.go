@@ -0,0 +1,271 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/figment-networks/indexer-manager/structs"
+	cStruct "github.com/figment-networks/indexer-manager/worker/connectivity/structs"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+type ratesSourceMock struct {
+	rates map[uint64]sdk.DecCoins
+	err   error
+	calls int32
+}
+
+func (rs *ratesSourceMock) GetExchangeRates(ctx context.Context, height uint64) (sdk.DecCoins, error) {
+	atomic.AddInt32(&rs.calls, 1)
+	if rs.err != nil {
+		return nil, rs.err
+	}
+	return rs.rates[height], nil
+}
+
+func testRates() map[uint64]sdk.DecCoins {
+	return map[uint64]sdk.DecCoins{
+		10: {
+			sdk.NewDecCoinFromDec("ukrw", sdk.NewDec(1000)),
+			sdk.NewDecCoinFromDec("uusd", sdk.NewDec(4)),
+		},
+		11: {
+			sdk.NewDecCoinFromDec("ukrw", sdk.NewDec(2000)),
+			sdk.NewDecCoinFromDec("uusd", sdk.NewDec(8)),
+		},
+	}
+}
+
+func testAmount(currency string, numeric int64) structs.TransactionAmount {
+	return structs.TransactionAmount{Text: big.NewInt(numeric).String(), Numeric: big.NewInt(numeric), Currency: currency}
+}
+
+func testValue(currency, text string) FiatValue {
+	d, _ := sdk.NewDecFromStr(text)
+	return FiatValue{TransactionAmount: structs.TransactionAmount{Text: d.String(), Numeric: d.BigInt(), Exp: sdk.Precision, Currency: currency}}
+}
+
+func unavailable(currency string) FiatValue {
+	return FiatValue{TransactionAmount: structs.TransactionAmount{Currency: currency}, RateUnavailable: true}
+}
+
+func TestValuer_ValueTransaction(t *testing.T) {
+	const cw20 = "terra14z56l0fp2lsf86zy3hty2z47ezkhnthtr9yq76"
+	tx := structs.Transaction{
+		Height: 10,
+		Fee:    []structs.TransactionAmount{testAmount("ukrw", 5000)},
+		Events: structs.TransactionEvents{{
+			Kind: "send",
+			Sub: []structs.SubsetEvent{{
+				Type: []string{"send"},
+				Transfers: map[string][]structs.EventTransfer{
+					"send": {{
+						Account: structs.Account{ID: "terra1b"},
+						Amounts: []structs.TransactionAmount{testAmount("uluna", 1000000), testAmount(cw20, 5)},
+					}},
+					"fee": {{
+						Account: structs.Account{ID: "terra1a"},
+						Amounts: []structs.TransactionAmount{testAmount("ukrw", 5000)},
+					}},
+				},
+			}},
+		}},
+	}
+
+	tests := []struct {
+		name       string
+		currencies []string
+		height     uint64
+		want       Valuation
+	}{
+		{
+			name:       "luna and cross rates",
+			currencies: []string{"uusd", "ukrw", "umnt"},
+			height:     10,
+			want: Valuation{
+				Currencies: []string{"uusd", "ukrw", "umnt"},
+				Fee: []ValuedAmount{{
+					Amount: testAmount("ukrw", 5000),
+					Values: []FiatValue{testValue("uusd", "20"), testValue("ukrw", "5000"), unavailable("umnt")},
+				}},
+				Transfers: []ValuedTransfer{{
+					Type: "fee", Account: "terra1a",
+					Amounts: []ValuedAmount{{
+						Amount: testAmount("ukrw", 5000),
+						Values: []FiatValue{testValue("uusd", "20"), testValue("ukrw", "5000"), unavailable("umnt")},
+					}},
+				}, {
+					Type: "send", Account: "terra1b",
+					Amounts: []ValuedAmount{{
+						Amount: testAmount("uluna", 1000000),
+						Values: []FiatValue{testValue("uusd", "4000000"), testValue("ukrw", "1000000000"), unavailable("umnt")},
+					}, {
+						Amount: testAmount(cw20, 5),
+						Values: []FiatValue{unavailable("uusd"), unavailable("ukrw"), unavailable("umnt")},
+					}},
+				}},
+			},
+		},
+		{
+			name:       "rates of transaction height",
+			currencies: []string{"uluna"},
+			height:     11,
+			want: Valuation{
+				Currencies: []string{"uluna"},
+				Fee: []ValuedAmount{{
+					Amount: testAmount("ukrw", 5000),
+					Values: []FiatValue{testValue("uluna", "2.5")},
+				}},
+				Transfers: []ValuedTransfer{{
+					Type: "fee", Account: "terra1a",
+					Amounts: []ValuedAmount{{
+						Amount: testAmount("ukrw", 5000),
+						Values: []FiatValue{testValue("uluna", "2.5")},
+					}},
+				}, {
+					Type: "send", Account: "terra1b",
+					Amounts: []ValuedAmount{{
+						Amount: testAmount("uluna", 1000000),
+						Values: []FiatValue{testValue("uluna", "1000000")},
+					}, {
+						Amount: testAmount(cw20, 5),
+						Values: []FiatValue{unavailable("uluna")},
+					}},
+				}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := NewValuer(&ratesSourceMock{rates: testRates()}, tt.currencies, 10)
+			tx := tx
+			tx.Height = tt.height
+
+			got, err := v.ValueTransaction(context.Background(), tx)
+			require.NoError(t, err)
+			require.Equal(t, tx, got.Transaction)
+			require.Equal(t, tt.want, got.Valuation)
+		})
+	}
+}
+
+func TestValuer_RatesCache(t *testing.T) {
+	ctx := context.Background()
+	rs := &ratesSourceMock{rates: testRates()}
+	v := NewValuer(rs, []string{"uusd"}, 1)
+
+	for _, height := range []uint64{10, 10, 11, 11, 10} {
+		_, err := v.ValueTransaction(ctx, structs.Transaction{Height: height, Fee: []structs.TransactionAmount{testAmount("uluna", 1)}})
+		require.NoError(t, err)
+	}
+	// height 10 is fetched again after being evicted by 11
+	require.Equal(t, int32(3), atomic.LoadInt32(&rs.calls))
+
+	rs = &ratesSourceMock{rates: testRates()}
+	v = NewValuer(rs, []string{"uusd"}, 10)
+	wg := &sync.WaitGroup{}
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := v.ValueTransaction(ctx, structs.Transaction{Height: 10})
+			require.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+	require.Equal(t, int32(1), atomic.LoadInt32(&rs.calls))
+}
+
+func TestValuer_RatesError(t *testing.T) {
+	ctx := context.Background()
+	rs := &ratesSourceMock{rates: testRates(), err: errors.New("bad status: 500")}
+	v := NewValuer(rs, []string{"uusd", "uluna"}, 10)
+	tx := structs.Transaction{Height: 10, Fee: []structs.TransactionAmount{testAmount("uluna", 1)}}
+
+	got, err := v.ValueTransaction(ctx, tx)
+	require.Error(t, err)
+	require.Equal(t, tx, got.Transaction)
+	require.Equal(t, Valuation{
+		Currencies: []string{"uusd", "uluna"},
+		Fee: []ValuedAmount{{
+			Amount: testAmount("uluna", 1),
+			Values: []FiatValue{unavailable("uusd"), testValue("uluna", "1")},
+		}},
+		Error: "error fetching exchange rates at height 10: bad status: 500",
+	}, got.Valuation)
+
+	// failures are not cached
+	rs.err = nil
+	got, err = v.ValueTransaction(ctx, tx)
+	require.NoError(t, err)
+	require.Empty(t, got.Valuation.Error)
+	require.Equal(t, []FiatValue{testValue("uusd", "4"), testValue("uluna", "1")}, got.Valuation.Fee[0].Values)
+	require.Equal(t, int32(2), atomic.LoadInt32(&rs.calls))
+}
+
+func TestValuer_RatesFailedFetch(t *testing.T) {
+	ctx := context.Background()
+	rs := &ratesSourceMock{rates: testRates(), err: errors.New("bad status: 500")}
+	v := NewValuer(rs, []string{"uusd"}, 2)
+	tx := func(height uint64) structs.Transaction {
+		return structs.Transaction{Height: height, Fee: []structs.TransactionAmount{testAmount("uluna", 1)}}
+	}
+
+	for i := 0; i < 3; i++ {
+		_, err := v.ValueTransaction(ctx, tx(10))
+		require.Error(t, err)
+	}
+	require.Empty(t, v.heights, "failed fetches take no place in the cache")
+
+	rs.err = nil
+	for _, height := range []uint64{10, 11, 10, 11} {
+		_, err := v.ValueTransaction(ctx, tx(height))
+		require.NoError(t, err)
+	}
+	require.Equal(t, []uint64{10, 11}, v.heights)
+	require.Len(t, v.cache, 2)
+	// 3 failed fetches of height 10, then single fetch of each height
+	require.Equal(t, int32(5), atomic.LoadInt32(&rs.calls))
+}
+
+func TestValueTransactionsCh(t *testing.T) {
+	v := NewValuer(&ratesSourceMock{rates: testRates()}, []string{"uusd"}, 10)
+	tx := structs.Transaction{Height: 10, Hash: "AB", Fee: []structs.TransactionAmount{testAmount("uluna", 1)}}
+	block := structs.Block{Height: 10, Hash: "CD"}
+
+	id := uuid.New()
+	in := make(chan cStruct.OutResp, 2)
+	out := make(chan cStruct.OutResp, 2)
+	in <- cStruct.OutResp{ID: id, Type: "Transaction", Payload: tx}
+	in <- cStruct.OutResp{ID: id, Type: "Block", Payload: block}
+	close(in)
+
+	ValueTransactionsCh(context.Background(), zaptest.NewLogger(t), v, in, out)
+	close(out)
+
+	resp := <-out
+	require.Equal(t, "Transaction", resp.Type)
+	vtx, ok := resp.Payload.(ValuedTransaction)
+	require.True(t, ok)
+	require.Equal(t, []FiatValue{testValue("uusd", "4")}, vtx.Valuation.Fee[0].Values)
+
+	// valued transaction is still read by the manager as the original one
+	b, err := json.Marshal(vtx)
+	require.NoError(t, err)
+	decoded := structs.Transaction{}
+	require.NoError(t, json.Unmarshal(b, &decoded))
+	require.Equal(t, tx, decoded)
+
+	resp = <-out
+	require.Equal(t, cStruct.OutResp{ID: id, Type: "Block", Payload: block}, resp)
+}
@@ -0,0 +1,153 @@
+package api
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/figment-networks/indexer-manager/structs"
+	"github.com/figment-networks/terra-worker/api/mapper"
+	"github.com/figment-networks/terra-worker/api/types"
+
+	"github.com/terra-project/core/x/auth"
+	"github.com/terra-project/core/x/wasm"
+)
+
+// ContractCode is contract code stored on chain
+type ContractCode struct {
+	CodeID uint64 `json:"code_id"`
+	Sender string `json:"sender"`
+	Height uint64 `json:"height"`
+	TxHash string `json:"tx_hash"`
+
+	SHA256       string `json:"sha256"`
+	Size         int    `json:"size"`
+	WASMByteCode []byte `json:"wasm_byte_code,omitempty"`
+}
+
+// GetContractCode fetches code uploaded to the chain, taking it from the `store_code` transaction
+func (c *Client) GetContractCode(ctx context.Context, codeID uint64, withByteCode bool) (cc ContractCode, err error) {
+	txs, _, err := c.SearchTxByQuery(ctx, fmt.Sprintf("store_code.code_id='%d'", codeID), structs.HeightRange{}, 1, 1)
+	if err != nil {
+		return cc, err
+	}
+
+	if len(txs) == 0 {
+		return cc, fmt.Errorf("code %d not found", codeID)
+	}
+	txRaw := txs[0]
+
+	txLog := []types.LogFormat{}
+	if err := json.Unmarshal([]byte(txRaw.TxResult.Log), &txLog); err != nil {
+		return cc, fmt.Errorf("error decoding transaction log: %w", err)
+	}
+
+	tx := &auth.StdTx{}
+	base64Dec := base64.NewDecoder(base64.StdEncoding, strings.NewReader(txRaw.TxData))
+	if _, err := c.cdc.UnmarshalBinaryLengthPrefixedReader(base64Dec, tx, 0); err != nil {
+		return cc, fmt.Errorf("error decoding transaction: %w", err)
+	}
+
+	for index, msg := range tx.Msgs {
+		sc, ok := msg.(wasm.MsgStoreCode)
+		if !ok {
+			continue
+		}
+
+		lf := findLog(txLog, index)
+		if !storesCode(lf, codeID) {
+			continue
+		}
+
+		checksum := sha256.Sum256(sc.WASMByteCode)
+		cc = ContractCode{
+			CodeID: codeID,
+			TxHash: txRaw.Hash,
+			SHA256: hex.EncodeToString(checksum[:]),
+			Size:   len(sc.WASMByteCode),
+		}
+		cc.Height, _ = strconv.ParseUint(txRaw.Height, 10, 64)
+		cc.Sender, _ = mapper.AccAddress(sc.Sender)
+		if withByteCode {
+			cc.WASMByteCode = sc.WASMByteCode
+		}
+		return cc, nil
+	}
+
+	return cc, fmt.Errorf("code %d not found in transaction %s", codeID, txRaw.Hash)
+}
+
+func storesCode(lf types.LogFormat, codeID uint64) bool {
+	id := strconv.FormatUint(codeID, 10)
+	for _, ev := range lf.Events {
+		if ev.Type != "store_code" || ev.Attributes == nil {
+			continue
+		}
+		for _, v := range ev.Attributes.Others["code_id"] {
+			if v == id {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+type contractInfo struct {
+	CodeID json.Number `json:"code_id"`
+}
+
+// GetContractCodeID fetches code id of contract at given height (it changes with contract migration)
+func (c *Client) GetContractCodeID(ctx context.Context, address string, height uint64) (codeID uint64, err error) {
+	var result contractInfo
+	endpoint := fmt.Sprintf("/wasm/contracts/%s", address)
+	if err = c.getLCD(ctx, endpoint, "/wasm/contracts/_", height, &result); err != nil {
+		return 0, err
+	}
+
+	if codeID, err = strconv.ParseUint(result.CodeID.String(), 10, 64); err != nil {
+		return 0, fmt.Errorf("[TERRA-API] Error parsing code id of %s: %w", address, err)
+	}
+	return codeID, nil
+}
+
+// ContractCodeIDs resolves code ids of contracts with lcd for mapper.ContractRegistry, so contracts can be matched by code id.
+// Code id changes only with contract migration, so it is cached by address (cacheSize recent contracts)
+// and replaced by code ids from instantiate and migrate logs of processed transactions
+type ContractCodeIDs struct {
+	client  *Client
+	timeout time.Duration
+	cache   *lruCache
+}
+
+// NewContractCodeIDs is ContractCodeIDs constructor, every lookup is limited by timeout
+func NewContractCodeIDs(c *Client, timeout time.Duration, cacheSize int) *ContractCodeIDs {
+	return &ContractCodeIDs{client: c, timeout: timeout, cache: newLRUCache(cacheSize)}
+}
+
+// ContractCodeID fulfills mapper.CodeIDSource interface, contracts not seen yet are looked up at the latest height
+func (cc *ContractCodeIDs) ContractCodeID(address string) (uint64, error) {
+	if codeID, ok := cc.cache.Get(address); ok {
+		return codeID.(uint64), nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), cc.timeout)
+	defer cancel()
+
+	codeID, err := cc.client.GetContractCodeID(ctx, address, 0)
+	if err != nil {
+		return 0, err
+	}
+	cc.cache.Add(address, codeID)
+	return codeID, nil
+}
+
+// SetContractCodeID fulfills mapper.CodeIDSource interface
+func (cc *ContractCodeIDs) SetContractCodeID(address string, codeID uint64) {
+	cc.cache.Add(address, codeID)
+}
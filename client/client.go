@@ -18,10 +18,11 @@ import (
 	"github.com/figment-networks/indexer-manager/structs"
 	cStructs "github.com/figment-networks/indexer-manager/worker/connectivity/structs"
 	"github.com/figment-networks/terra-worker/api"
+	"github.com/figment-networks/terra-worker/api/mapper"
 	"github.com/figment-networks/terra-worker/api/types"
 )
 
-//go:generate mockgen -destination=./mocks/mock_client.go -package=mocks -imports github.com/tendermint/go-amino github.com/figment-networks/terra-worker/client RPC
+//go:generate mockgen -destination=./mocks/mock_client.go -package=mocks -imports github.com/tendermint/go-amino github.com/figment-networks/terra-worker/client RPC,LCD
 
 const page = 100
 const blockchainEndpointLimit = 20
@@ -32,18 +33,33 @@ var (
 	getBlockDuration              *metrics.GroupObserver
 	getAccountBalanceDuration     *metrics.GroupObserver
 	getAccountDelegationsDuration *metrics.GroupObserver
+	getOracleFeederReportDuration *metrics.GroupObserver
+	getContractCodeDuration       *metrics.GroupObserver
+	getGovProposalsDuration       *metrics.GroupObserver
+	getGovVotesDuration           *metrics.GroupObserver
+	getGovDepositsDuration        *metrics.GroupObserver
+	getGovTallyDuration           *metrics.GroupObserver
 )
 
 type RPC interface {
 	CDC() *amino.Codec
 	SingularHeightWorker(ctx context.Context, wg *sync.WaitGroup, out chan types.TxResponse, in chan api.ToGet)
 	GetBlocksMeta(ctx context.Context, params structs.HeightRange, limit uint64, blocks *api.BlocksMap, end chan<- error)
+	SearchTxByQuery(ctx context.Context, query string, hr structs.HeightRange, page, perPage int) (txSearch []types.TxResponse, totalCount uint64, err error)
+	GetContractCode(ctx context.Context, codeID uint64, withByteCode bool) (cc api.ContractCode, err error)
 }
 
 type LCD interface {
 	GetReward(ctx context.Context, params structs.HeightAccount) (resp structs.GetRewardResponse, err error)
 	GetAccountBalance(ctx context.Context, params structs.HeightAccount) (resp structs.GetAccountBalanceResponse, err error)
 	GetAccountDelegations(ctx context.Context, params structs.HeightAccount) (resp structs.GetAccountDelegationsResponse, err error)
+	GetOracleMissCounter(ctx context.Context, params structs.HeightAccount) (missCount uint64, err error)
+	GetOracleFeeder(ctx context.Context, params structs.HeightAccount) (feeder string, err error)
+	GetGovProposals(ctx context.Context, status string, height uint64) (proposals []api.GovProposal, err error)
+	GetGovProposal(ctx context.Context, proposalID, height uint64) (proposal api.GovProposal, err error)
+	GetGovVotes(ctx context.Context, proposalID, height uint64) (votes []api.GovVote, err error)
+	GetGovDeposits(ctx context.Context, proposalID, height uint64) (deposits []api.GovDeposit, err error)
+	GetGovTally(ctx context.Context, proposalID, height uint64) (tally api.GovTally, err error)
 }
 
 type IndexerClient struct {
@@ -56,6 +72,10 @@ type IndexerClient struct {
 
 	bigPage             uint64
 	maximumHeightsToGet uint64
+
+	wasmByteCode mapper.WasmByteCodeMode
+	contracts    *mapper.ContractRegistry
+	enrichment   Enrichment
 }
 
 func NewIndexerClient(ctx context.Context, logger *zap.Logger, lcdCli LCD, rpcCli RPC, bigPage, maximumHeightsToGet uint64) *IndexerClient {
@@ -64,6 +84,12 @@ func NewIndexerClient(ctx context.Context, logger *zap.Logger, lcdCli LCD, rpcCl
 	getBlockDuration = endpointDuration.WithLabels("getBlock")
 	getAccountBalanceDuration = endpointDuration.WithLabels("getAccountBalance")
 	getAccountDelegationsDuration = endpointDuration.WithLabels("getAccountDelegations")
+	getOracleFeederReportDuration = endpointDuration.WithLabels("getOracleFeederReport")
+	getContractCodeDuration = endpointDuration.WithLabels("getContractCode")
+	getGovProposalsDuration = endpointDuration.WithLabels("getGovProposals")
+	getGovVotesDuration = endpointDuration.WithLabels("getGovVotes")
+	getGovDepositsDuration = endpointDuration.WithLabels("getGovDeposits")
+	getGovTallyDuration = endpointDuration.WithLabels("getGovTally")
 	api.InitMetrics()
 
 	return &IndexerClient{
@@ -129,6 +155,18 @@ func (ic *IndexerClient) Run(ctx context.Context, stream *cStructs.StreamAccess)
 				ic.GetAccountBalance(nCtx, taskRequest, stream, ic.lcd)
 			case structs.ReqIDAccountDelegations:
 				ic.GetAccountDelegations(nCtx, taskRequest, stream, ic.lcd)
+			case ReqIDGetOracleFeederReport:
+				ic.GetOracleFeederReport(nCtx, taskRequest, stream, ic.rpc, ic.lcd)
+			case ReqIDGetContractCode:
+				ic.GetContractCode(nCtx, taskRequest, stream, ic.rpc)
+			case ReqIDGetGovProposals:
+				ic.GetGovProposals(nCtx, taskRequest, stream, ic.lcd)
+			case ReqIDGetGovVotes:
+				ic.GetGovVotes(nCtx, taskRequest, stream, ic.lcd)
+			case ReqIDGetGovDeposits:
+				ic.GetGovDeposits(nCtx, taskRequest, stream, ic.lcd)
+			case ReqIDGetGovTally:
+				ic.GetGovTally(nCtx, taskRequest, stream, ic.lcd)
 			default:
 				stream.Send(cStructs.TaskResponse{
 					Id:    taskRequest.Id,
@@ -161,7 +199,7 @@ func (ic *IndexerClient) GetTransactions(ctx context.Context, tr cStructs.TaskRe
 	if hr.EndHeight == 0 {
 		stream.Send(cStructs.TaskResponse{
 			Id:    tr.Id,
-			Error: cStructs.TaskError{Msg: "end height is zero" + err.Error()},
+			Error: cStructs.TaskError{Msg: "end height is zero"},
 			Final: true,
 		})
 		return
@@ -172,8 +210,9 @@ func (ic *IndexerClient) GetTransactions(ctx context.Context, tr cStructs.TaskRe
 
 	out := make(chan cStructs.OutResp, page*2+1)
 	fin := make(chan bool, 2)
+	failed := make(chan error, 1)
 
-	go sendResp(sCtx, tr.Id, out, ic.logger, stream, fin)
+	go sendRespOrFail(sCtx, tr.Id, ic.enrichment.Chain(sCtx, ic.logger, out), failed, ic.logger, stream, fin)
 
 	var i uint64
 	for {
@@ -187,12 +226,10 @@ func (ic *IndexerClient) GetTransactions(ctx context.Context, tr cStructs.TaskRe
 			hrInner.EndHeight = hr.EndHeight
 		}
 
-		if err := getRangeSingular(sCtx, ic.logger, client, hrInner, out); err != nil {
-			stream.Send(cStructs.TaskResponse{
-				Id:    tr.Id,
-				Error: cStructs.TaskError{Msg: err.Error()},
-				Final: true,
-			})
+		if err := getRangeSingular(sCtx, ic.logger, client, ic.mapperOptions(), hrInner, out); err != nil {
+			// the error is sent as the final response instead of END
+			failed <- err
+			<-fin
 			ic.logger.Error("[TERRA-CLIENT] Error getting range (Get Transactions) ", zap.Error(err), zap.Stringer("taskID", tr.Id))
 			return
 		}
@@ -218,6 +255,11 @@ func (ic *IndexerClient) GetTransactions(ctx context.Context, tr cStructs.TaskRe
 
 // sendResp sends responses to out channel preparing
 func sendResp(ctx context.Context, id uuid.UUID, out chan cStructs.OutResp, logger *zap.Logger, stream *cStructs.StreamAccess, fin chan bool) {
+	sendRespOrFail(ctx, id, out, nil, logger, stream, fin)
+}
+
+// sendRespOrFail is sendResp stopping on error received from failed, which is sent as the final response instead of END
+func sendRespOrFail(ctx context.Context, id uuid.UUID, out chan cStructs.OutResp, failed <-chan error, logger *zap.Logger, stream *cStructs.StreamAccess, fin chan bool) {
 	b := &bytes.Buffer{}
 	enc := json.NewEncoder(b)
 	order := uint64(0)
@@ -230,6 +272,14 @@ SendLoop:
 		case <-ctx.Done():
 			contextDone = true
 			break SendLoop
+		case err := <-failed:
+			if err := stream.Send(cStructs.TaskResponse{Id: id, Error: cStructs.TaskError{Msg: err.Error()}, Final: true}); err != nil {
+				logger.Error("[TERRA-CLIENT] Error sending error", zap.Error(err))
+			}
+			if fin != nil {
+				close(fin)
+			}
+			return
 		case t, ok := <-out:
 			if !ok && t.Type == "" {
 				break SendLoop
@@ -287,7 +337,8 @@ func (ic *IndexerClient) GetLatest(ctx context.Context, tr cStructs.TaskRequest,
 	ldr := &structs.LatestDataRequest{}
 	err := json.Unmarshal(tr.Payload, ldr)
 	if err != nil {
-		stream.Send(cStructs.TaskResponse{Id: tr.Id, Error: cStructs.TaskError{Msg: "Cannot unmarshal payment"}, Final: true})
+		stream.Send(cStructs.TaskResponse{Id: tr.Id, Error: cStructs.TaskError{Msg: "Cannot unmarshal payload"}, Final: true})
+		return
 	}
 
 	sCtx, cancel := context.WithCancel(ctx)
@@ -313,7 +364,9 @@ func (ic *IndexerClient) GetLatest(ctx context.Context, tr cStructs.TaskRequest,
 	}
 
 	startingHeight := getStartingHeight(ldr.LastHeight, ic.maximumHeightsToGet, blocksAll.EndHeight)
-	if startingHeight <= blocksAll.StartHeight {
+	// blocksAll is modified by requests started below, so the range is taken before
+	latestStartHeight := blocksAll.StartHeight
+	if startingHeight <= latestStartHeight {
 		var i, responses uint64
 		for {
 			bhr := structs.HeightRange{
@@ -323,14 +376,14 @@ func (ic *IndexerClient) GetLatest(ctx context.Context, tr cStructs.TaskRequest,
 				ChainID:     ldr.ChainID,
 			}
 
-			if bhr.EndHeight > blocksAll.StartHeight {
-				bhr.EndHeight = blocksAll.StartHeight
+			if bhr.EndHeight > latestStartHeight {
+				bhr.EndHeight = latestStartHeight
 			}
 
 			ic.logger.Debug("[TERRA-CLIENT] Getting blocks for ", zap.Uint64("end", bhr.EndHeight), zap.Uint64("start", bhr.StartHeight))
 			go client.GetBlocksMeta(ctx, bhr, blockchainEndpointLimit, blocksAll, batchesCtrl)
 			i++
-			if bhr.EndHeight == blocksAll.StartHeight {
+			if bhr.EndHeight == latestStartHeight {
 				break
 			}
 		}
@@ -364,12 +417,12 @@ func (ic *IndexerClient) GetLatest(ctx context.Context, tr cStructs.TaskRequest,
 	out := make(chan cStructs.OutResp, page)
 	fin := make(chan bool, 2)
 	// (lukanus): in separate goroutine take transaction format wrap it in transport message and send
-	go sendResp(sCtx, tr.Id, out, ic.logger, stream, fin)
+	go sendResp(sCtx, tr.Id, ic.enrichment.Chain(sCtx, ic.logger, out), ic.logger, stream, fin)
 
 	convertWG := &sync.WaitGroup{}
 	txIn := make(chan types.TxResponse, 20)
 	convertWG.Add(1)
-	go api.RawToTransactionCh(ic.logger, client.CDC(), convertWG, txIn, blocksAll.Blocks, out)
+	go api.RawToTransactionCh(ic.logger, client.CDC(), ic.mapperOptions(), convertWG, txIn, blocksAll.Blocks, out)
 
 	httpReqWG := &sync.WaitGroup{}
 	toGet := make(chan api.ToGet, 10)
@@ -381,7 +434,7 @@ func (ic *IndexerClient) GetLatest(ctx context.Context, tr cStructs.TaskRequest,
 	for h, block := range blocksAll.Blocks {
 		// (lukanus): skip processing blocks before given range
 		// we take blocks by 20
-		if blocksAll.StartHeight < block.Height {
+		if block.Height < startingHeight {
 			continue
 		}
 
@@ -410,6 +463,15 @@ func (ic *IndexerClient) GetLatest(ctx context.Context, tr cStructs.TaskRequest,
 	ic.logger.Debug("[TERRA-CLIENT] Received all", zap.Stringer("taskID", tr.Id))
 	close(out)
 
+	for {
+		select {
+		case <-sCtx.Done():
+			return
+		case <-fin:
+			ic.logger.Debug("[TERRA-CLIENT] Finished sending all", zap.Stringer("taskID", tr.Id))
+			return
+		}
+	}
 }
 
 // GetReward gets reward
@@ -450,7 +512,7 @@ func (ic *IndexerClient) GetReward(ctx context.Context, tr cStructs.TaskRequest,
 	}
 	close(out)
 
-	sendResp(ctx, tr.Id, out, ic.logger, stream, nil)
+	sendResp(ctx, tr.Id, annotateAmounts(ctx, ic.logger, ic.enrichment.Denoms, out), ic.logger, stream, nil)
 }
 
 // GetAccountBalance gets account balance
@@ -491,7 +553,7 @@ func (ic *IndexerClient) GetAccountBalance(ctx context.Context, tr cStructs.Task
 	}
 	close(out)
 
-	sendResp(ctx, tr.Id, out, ic.logger, stream, nil)
+	sendResp(ctx, tr.Id, annotateAmounts(ctx, ic.logger, ic.enrichment.Denoms, out), ic.logger, stream, nil)
 }
 
 // GetAccountDelegations gets account delegations
@@ -515,10 +577,10 @@ func (ic *IndexerClient) GetAccountDelegations(ctx context.Context, tr cStructs.
 
 	blnc, err := client.GetAccountDelegations(sCtx, *ha)
 	if err != nil {
-		ic.logger.Error("Error getting account balance", zap.Error(err))
+		ic.logger.Error("Error getting account delegations", zap.Error(err))
 		stream.Send(cStructs.TaskResponse{
 			Id:    tr.Id,
-			Error: cStructs.TaskError{Msg: "Error getting account balance data " + err.Error()},
+			Error: cStructs.TaskError{Msg: "Error getting account delegations data " + err.Error()},
 			Final: true,
 		})
 		return
@@ -532,11 +594,17 @@ func (ic *IndexerClient) GetAccountDelegations(ctx context.Context, tr cStructs.
 	}
 	close(out)
 
-	sendResp(ctx, tr.Id, out, ic.logger, stream, nil)
+	sendResp(ctx, tr.Id, annotateAmounts(ctx, ic.logger, ic.enrichment.Denoms, out), ic.logger, stream, nil)
+}
+
+// GetRange sends blocks and transactions of height range to out, converted the same way as by GetTransactions.
+// It's meant for tools running without a manager (eg. terra-export), out is not closed
+func GetRange(ctx context.Context, logger *zap.Logger, client RPC, opts mapper.Options, hr structs.HeightRange, out chan cStructs.OutResp) error {
+	return getRangeSingular(ctx, logger, client, opts, hr, out)
 }
 
 // getRange gets given range of blocks and transactions
-func getRangeSingular(ctx context.Context, logger *zap.Logger, client RPC, hr structs.HeightRange, out chan cStructs.OutResp) error {
+func getRangeSingular(ctx context.Context, logger *zap.Logger, client RPC, opts mapper.Options, hr structs.HeightRange, out chan cStructs.OutResp) error {
 	defer logger.Sync()
 
 	batchesCtrl := make(chan error, 2)
@@ -587,7 +655,7 @@ func getRangeSingular(ctx context.Context, logger *zap.Logger, client RPC, hr st
 	convertWG := &sync.WaitGroup{}
 	txIn := make(chan types.TxResponse, 20)
 	convertWG.Add(1)
-	go api.RawToTransactionCh(logger, client.CDC(), convertWG, txIn, blocksAll.Blocks, out)
+	go api.RawToTransactionCh(logger, client.CDC(), opts, convertWG, txIn, blocksAll.Blocks, out)
 
 	httpReqWG := &sync.WaitGroup{}
 	toGet := make(chan api.ToGet, 10)
@@ -1,65 +1,314 @@
 package client
 
-/*
 import (
 	"context"
-	"sync"
+	"encoding/json"
+	"errors"
+	"math/big"
 	"testing"
 
+	"github.com/figment-networks/indexer-manager/structs"
 	cStructs "github.com/figment-networks/indexer-manager/worker/connectivity/structs"
 	"github.com/figment-networks/terra-worker/api"
-	apiMocks "github.com/figment-networks/terra-worker/client/mocks"
-
+	"github.com/figment-networks/terra-worker/client/mocks"
 	"github.com/golang/mock/gomock"
 	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
-	"go.uber.org/zap/zaptest"
+)
+
+const (
+	testChainID    = "columbus-4"
+	testLastHeight = 3000040
 )
 
 func TestIndexerClient_GetTransactions(t *testing.T) {
-	type fields struct {
-		cli                 *api.Client
-		logger              *zap.Logger
-		streams             map[uuid.UUID]*cStructs.StreamAccess
-		sLock               sync.Mutex
-		bigPage             uint64
-		maximumHeightsToGet uint64
+	tests := []struct {
+		name      string
+		payload   interface{}
+		blocksErr error
+		expected  map[string]int
+		wantErr   string
+	}{
+		{
+			name:     "range of many big pages",
+			payload:  structs.HeightRange{StartHeight: 3000001, EndHeight: 3000031, ChainID: testChainID},
+			expected: map[string]int{"Block": 31, "Transaction": 31},
+		},
+		{
+			name:     "blocks without transactions",
+			payload:  structs.HeightRange{StartHeight: 3000032, EndHeight: 3000040, ChainID: testChainID},
+			expected: map[string]int{"Block": 9},
+		},
+		{
+			name:    "end height is zero",
+			payload: structs.HeightRange{StartHeight: 3000001, ChainID: testChainID},
+			wantErr: "end height is zero",
+		},
+		{
+			name:    "wrong payload",
+			payload: []byte(`{"start_height":`),
+			wantErr: "cannot unmarshal payload",
+		},
+		{
+			name:      "error getting blocks",
+			payload:   structs.HeightRange{StartHeight: 3000001, EndHeight: 3000031, ChainID: testChainID},
+			blocksErr: errors.New("Bad Response 502"),
+			wantErr:   "Errors Getting Blocks",
+		},
 	}
-	type args struct {
-		ctx    context.Context
-		tr     cStructs.TaskRequest
-		stream *cStructs.StreamAccess
-		client *api.Client
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			chain := newFakeChain(t, testChainID, testLastHeight)
+			chain.blocksErr = tt.blocksErr
+
+			ic := NewIndexerClient(context.Background(), zap.NewNop(), mocks.NewMockLCD(ctrl), chain.rpc(ctrl), 10, 1000)
+			responses := newFakeManager(t, ic).Do(structs.ReqIDGetTransactions, tt.payload)
+
+			if tt.wantErr != "" {
+				requireTaskError(t, responses, tt.wantErr)
+				return
+			}
+			requireSequence(t, responses, tt.expected)
+		})
 	}
+}
+
+func TestIndexerClient_GetLatest(t *testing.T) {
 	tests := []struct {
-		name   string
-		fields fields
-		args   args
+		name                string
+		payload             interface{}
+		maximumHeightsToGet uint64
+		blocksErr           error
+		startHeight         uint64
+		wantErr             string
 	}{
-		// TODO: Add test cases.
+		{
+			name:                "last height within latest blocks",
+			payload:             structs.LatestDataRequest{LastHeight: 3000030, ChainID: testChainID},
+			maximumHeightsToGet: 1000,
+			startHeight:         3000030,
+		},
+		{
+			name:                "last height before latest blocks",
+			payload:             structs.LatestDataRequest{LastHeight: 3000005, ChainID: testChainID},
+			maximumHeightsToGet: 1000,
+			startHeight:         3000005,
+		},
+		{
+			name:                "nothing scraped yet",
+			payload:             structs.LatestDataRequest{ChainID: testChainID},
+			maximumHeightsToGet: 25,
+			startHeight:         testLastHeight - 25,
+		},
+		{
+			name:                "wrong payload",
+			payload:             []byte(`[]`),
+			maximumHeightsToGet: 1000,
+			wantErr:             "Cannot unmarshal payload",
+		},
+		{
+			name:                "error getting latest blocks",
+			payload:             structs.LatestDataRequest{LastHeight: 3000030, ChainID: testChainID},
+			maximumHeightsToGet: 1000,
+			blocksErr:           errors.New("Bad Response 502"),
+			wantErr:             "Bad Response 502",
+		},
 	}
+
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			chain := newFakeChain(t, testChainID, testLastHeight)
+			chain.blocksErr = tt.blocksErr
+
+			ic := NewIndexerClient(context.Background(), zap.NewNop(), mocks.NewMockLCD(ctrl), chain.rpc(ctrl), 10, tt.maximumHeightsToGet)
+			responses := newFakeManager(t, ic).Do(structs.ReqIDLatestData, tt.payload)
 
-			ctx := context.Background()
+			if tt.wantErr != "" {
+				requireTaskError(t, responses, tt.wantErr)
+				return
+			}
 
-			mockApiCtrl := gomock.NewController(t)
-			apiMock := apiMocks.NewMockApi(mockApiCtrl)
-			defer mockApiCtrl.Finish()
+			requireSequence(t, responses, map[string]int{
+				"Block":       int(testLastHeight - tt.startHeight + 1),
+				"Transaction": chain.numTxs(tt.startHeight, testLastHeight),
+			})
+		})
+	}
+}
+
+// TestIndexerClient_GetLatestWaitsForResponses checks that GetLatest returns only after END is sent,
+// the handler loop cancels context of the task as soon as it returns
+func TestIndexerClient_GetLatestWaitsForResponses(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	chain := newFakeChain(t, testChainID, testLastHeight)
+	rpc := chain.rpc(ctrl)
+	ic := NewIndexerClient(context.Background(), zap.NewNop(), mocks.NewMockLCD(ctrl), rpc, 10, 1000)
+
+	payload, err := json.Marshal(structs.LatestDataRequest{LastHeight: 3000005, ChainID: testChainID})
+	require.NoError(t, err)
+	stream := cStructs.NewStreamAccess()
+
+	// responses are read while GetLatest runs, as they don't fit into the stream buffer
+	var responses []cStructs.TaskResponse
+	returned := make(chan struct{})
+	read := make(chan struct{})
+	go func() {
+		defer close(read)
+		for {
+			select {
+			case resp := <-stream.ResponseListener:
+				responses = append(responses, resp)
+			case <-returned:
+				return
+			}
+		}
+	}()
+
+	ic.GetLatest(context.Background(), cStructs.TaskRequest{Id: uuid.New(), Type: structs.ReqIDLatestData, Payload: payload}, stream, rpc)
+	close(returned)
+	<-read
+	for len(stream.ResponseListener) > 0 {
+		responses = append(responses, <-stream.ResponseListener)
+	}
+	requireSequence(t, responses, map[string]int{
+		"Block":       int(testLastHeight - 3000005 + 1),
+		"Transaction": chain.numTxs(3000005, testLastHeight),
+	})
+}
+
+func TestIndexerClient_LCDHandlers(t *testing.T) {
+	params := structs.HeightAccount{Account: "terra190vqdjtlpcq27xslcveglfmr4ynfwg7gxlzhjn", Height: 3000010, ChainID: testChainID}
+	lcdErr := errors.New("[TERRA-API] Error fetching account balance: 500")
+
+	tests := []struct {
+		name     string
+		reqType  string
+		payload  interface{}
+		expect   func(lcd *mocks.MockLCDMockRecorder)
+		expected map[string]int
+		wantErr  string
+	}{
+		{
+			name:    "reward",
+			reqType: structs.ReqIDGetReward,
+			payload: params,
+			expect: func(lcd *mocks.MockLCDMockRecorder) {
+				lcd.GetReward(gomock.Any(), params).Return(structs.GetRewardResponse{
+					Height: params.Height,
+					Rewards: map[structs.Validator][]structs.TransactionAmount{
+						"terravaloper1lq40xgtqh3f3zt9prz4m74l6dlk506usv9ag54": {{Text: "1", Numeric: big.NewInt(1), Currency: "uluna"}},
+					},
+				}, nil)
+			},
+			expected: map[string]int{"Reward": 1},
+		},
+		{
+			name:    "reward error",
+			reqType: structs.ReqIDGetReward,
+			payload: params,
+			expect: func(lcd *mocks.MockLCDMockRecorder) {
+				lcd.GetReward(gomock.Any(), params).Return(structs.GetRewardResponse{}, lcdErr)
+			},
+			wantErr: "Error getting reward data",
+		},
+		{
+			name:    "account balance",
+			reqType: structs.ReqIDAccountBalance,
+			payload: params,
+			expect: func(lcd *mocks.MockLCDMockRecorder) {
+				lcd.GetAccountBalance(gomock.Any(), params).Return(structs.GetAccountBalanceResponse{
+					Height:   params.Height,
+					Balances: []structs.TransactionAmount{{Text: "1", Numeric: big.NewInt(1), Currency: "uluna"}},
+				}, nil)
+			},
+			expected: map[string]int{"AccountBalance": 1},
+		},
+		{
+			name:    "account balance error",
+			reqType: structs.ReqIDAccountBalance,
+			payload: params,
+			expect: func(lcd *mocks.MockLCDMockRecorder) {
+				lcd.GetAccountBalance(gomock.Any(), params).Return(structs.GetAccountBalanceResponse{}, lcdErr)
+			},
+			wantErr: "Error getting account balance data",
+		},
+		{
+			name:    "account delegations",
+			reqType: structs.ReqIDAccountDelegations,
+			payload: params,
+			expect: func(lcd *mocks.MockLCDMockRecorder) {
+				lcd.GetAccountDelegations(gomock.Any(), params).Return(structs.GetAccountDelegationsResponse{
+					Height: params.Height,
+					Delegations: []structs.Delegation{{
+						Delegator: params.Account,
+						Validator: "terravaloper1lq40xgtqh3f3zt9prz4m74l6dlk506usv9ag54",
+						Shares:    structs.TransactionAmount{Numeric: big.NewInt(1)},
+						Balance:   structs.TransactionAmount{Numeric: big.NewInt(1), Currency: "uluna"},
+					}},
+				}, nil)
+			},
+			expected: map[string]int{"AccountDelegations": 1},
+		},
+		{
+			name:    "account delegations error",
+			reqType: structs.ReqIDAccountDelegations,
+			payload: params,
+			expect: func(lcd *mocks.MockLCDMockRecorder) {
+				lcd.GetAccountDelegations(gomock.Any(), params).Return(structs.GetAccountDelegationsResponse{}, lcdErr)
+			},
+			wantErr: "Error getting account delegations data",
+		},
+		{
+			name:    "gov proposals",
+			reqType: ReqIDGetGovProposals,
+			payload: GovRequest{Status: "voting_period", Height: params.Height},
+			expect: func(lcd *mocks.MockLCDMockRecorder) {
+				lcd.GetGovProposals(gomock.Any(), "voting_period", params.Height).Return([]api.GovProposal{{ID: 1}, {ID: 2}}, nil)
+			},
+			expected: map[string]int{"GovProposal": 2},
+		},
+		{
+			name:    "wrong payload",
+			reqType: structs.ReqIDAccountBalance,
+			payload: []byte(`{"account":`),
+			expect:  func(lcd *mocks.MockLCDMockRecorder) {},
+			wantErr: "Cannot unmarshal payload",
+		},
+		{
+			name:    "unknown task",
+			reqType: "GetSomethingElse",
+			payload: params,
+			expect:  func(lcd *mocks.MockLCDMockRecorder) {},
+			wantErr: "There is no such handler GetSomethingElse",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
 
-			ic := NewIndexerClient(ctx, zaptest.NewLogger(t), apiMock, tt.fields.bigPage, tt.fields.maximumHeightsToGet)
-			ic.GetTransactions(ctx, tt.args.tr, tt.args.stream, tt.args.client)
+			lcd := mocks.NewMockLCD(ctrl)
+			tt.expect(lcd.EXPECT())
 
-			ic := &IndexerClient{
-							cli:                 tt.fields.cli,
-							logger:              tt.fields.logger,
-							streams:             tt.fields.streams,
-							sLock:               tt.fields.sLock,
-							bigPage:             tt.fields.bigPage,
-							maximumHeightsToGet: tt.fields.maximumHeightsToGet,
-						}
+			ic := NewIndexerClient(context.Background(), zap.NewNop(), lcd, mocks.NewMockRPC(ctrl), 10, 1000)
+			responses := newFakeManager(t, ic).Do(tt.reqType, tt.payload)
 
+			if tt.wantErr != "" {
+				requireTaskError(t, responses, tt.wantErr)
+				return
+			}
+			requireSequence(t, responses, tt.expected)
 		})
 	}
 }
-*/
@@ -0,0 +1,179 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/figment-networks/indexer-manager/structs"
+	cStructs "github.com/figment-networks/indexer-manager/worker/connectivity/structs"
+	"github.com/figment-networks/terra-worker/api"
+	"github.com/figment-networks/terra-worker/api/mapper"
+	"go.uber.org/zap"
+)
+
+// SetDenomAnnotation enables annotation of amounts in transactions, balances, rewards and delegations.
+// Every amount of known currency gets `display` object with display denom, decimals and human readable value,
+// fields of amounts stay untouched. Annotated payloads are sent as AnnotatedPayload. Nil registry disables annotation
+func (ic *IndexerClient) SetDenomAnnotation(dr *mapper.DenomRegistry) {
+	ic.enrichment.Denoms = dr
+}
+
+// annotateAmounts passes responses from in channel to the returned one, annotating amounts of payloads.
+// When annotation is disabled in channel is returned as is
+func annotateAmounts(ctx context.Context, logger *zap.Logger, dr *mapper.DenomRegistry, in chan cStructs.OutResp) chan cStructs.OutResp {
+	if dr == nil {
+		return in
+	}
+	return enrichmentStage(in, func(out chan<- cStructs.OutResp) {
+		api.EnrichCh(ctx, in, out, func(resp cStructs.OutResp) cStructs.OutResp {
+			if resp.Payload != nil && resp.Error == nil {
+				resp.Payload = annotatePayload(dr, resp.Payload)
+			}
+			return resp
+		})
+	})
+}
+
+// AnnotatedPayload is payload with display amounts of its amount fields. It's encoded as json of the payload
+// with `display` object in every annotated amount, Payload keeps its value for consumers of responses in process
+type AnnotatedPayload struct {
+	Payload interface{}
+	Display []AmountDisplay
+}
+
+// AmountDisplay is display amount of the amount at json path in payload
+type AmountDisplay struct {
+	Path    []interface{}
+	Display mapper.DisplayAmount
+}
+
+// MarshalJSON encodes payload, adding display objects to its amounts
+func (ap AnnotatedPayload) MarshalJSON() ([]byte, error) {
+	b, err := json.Marshal(ap.Payload)
+	if err != nil {
+		return nil, err
+	}
+
+	// numbers are kept as json.Number, so big amounts are not rounded to float64
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	var v interface{}
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+
+	for _, ad := range ap.Display {
+		am, err := jsonPath(v, ad.Path)
+		if err != nil {
+			return nil, err
+		}
+		am["display"] = ad.Display
+	}
+	return json.Marshal(v)
+}
+
+// jsonPath returns object at path of map keys and slice indexes in decoded json
+func jsonPath(v interface{}, path []interface{}) (map[string]interface{}, error) {
+	for _, p := range path {
+		switch k := p.(type) {
+		case string:
+			m, ok := v.(map[string]interface{})
+			if !ok {
+				return nil, fmt.Errorf("no object at %v", path)
+			}
+			v = m[k]
+		case int:
+			l, ok := v.([]interface{})
+			if !ok || k >= len(l) {
+				return nil, fmt.Errorf("no array item at %v", path)
+			}
+			v = l[k]
+		}
+	}
+	m, ok := v.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("no amount at %v", path)
+	}
+	return m, nil
+}
+
+// annotatePayload returns payload with display amounts of known amount fields: fee and event amounts of transactions
+// (valued ones included), balances, rewards and delegation balances. Payloads without any amount of known currency are returned as they are
+func annotatePayload(dr *mapper.DenomRegistry, payload interface{}) interface{} {
+	a := &annotator{dr: dr}
+	switch p := payload.(type) {
+	case structs.Transaction:
+		a.transaction(p)
+	case api.ValuedTransaction:
+		// transaction is embedded, so its fields are at the top level
+		a.transaction(p.Transaction)
+	case structs.GetAccountBalanceResponse:
+		a.amounts(p.Balances, "balances")
+	case structs.GetRewardResponse:
+		for v, rewards := range p.Rewards {
+			a.amounts(rewards, "rewards", string(v))
+		}
+	case structs.GetAccountDelegationsResponse:
+		for i, d := range p.Delegations {
+			a.amount(d.Balance, "delegations", i, "balance")
+		}
+	}
+
+	if len(a.display) == 0 {
+		return payload
+	}
+	return AnnotatedPayload{Payload: payload, Display: a.display}
+}
+
+// annotator collects display amounts of known currencies
+type annotator struct {
+	dr      *mapper.DenomRegistry
+	display []AmountDisplay
+}
+
+func (a *annotator) amount(am structs.TransactionAmount, path ...interface{}) {
+	if da, ok := a.dr.Display(am); ok {
+		a.display = append(a.display, AmountDisplay{Path: path, Display: da})
+	}
+}
+
+func (a *annotator) amounts(ams []structs.TransactionAmount, path ...interface{}) {
+	for i, am := range ams {
+		a.amount(am, append(path[:len(path):len(path)], i)...)
+	}
+}
+
+func (a *annotator) transaction(tx structs.Transaction) {
+	a.amounts(tx.Fee, "transaction_fee")
+	for i, ev := range tx.Events {
+		for j, sub := range ev.Sub {
+			a.subEvent(sub, "events", i, "sub", j)
+		}
+	}
+}
+
+func (a *annotator) subEvent(sub structs.SubsetEvent, path ...interface{}) {
+	at := func(p ...interface{}) []interface{} {
+		return append(path[:len(path):len(path)], p...)
+	}
+
+	for k, am := range sub.Amount {
+		a.amount(am, at("amount", k)...)
+	}
+	for i, tr := range sub.Sender {
+		a.amounts(tr.Amounts, at("sender", i, "amounts")...)
+	}
+	for i, tr := range sub.Recipient {
+		a.amounts(tr.Amounts, at("recipient", i, "amounts")...)
+	}
+	for k, trs := range sub.Transfers {
+		for i, tr := range trs {
+			a.amounts(tr.Amounts, at("transfers", k, i, "amounts")...)
+		}
+	}
+	for i, s := range sub.Sub {
+		a.subEvent(s, at("sub", i)...)
+	}
+}
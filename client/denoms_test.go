@@ -0,0 +1,173 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/figment-networks/indexer-manager/structs"
+	"github.com/figment-networks/terra-worker/api"
+	"github.com/figment-networks/terra-worker/api/mapper"
+	"github.com/figment-networks/terra-worker/client/mocks"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestAnnotatePayload(t *testing.T) {
+	huge, _ := new(big.Int).SetString("123456789012345678901234567", 10)
+
+	tests := []struct {
+		name    string
+		payload interface{}
+		want    string
+	}{
+		{
+			name: "balances",
+			payload: structs.GetAccountBalanceResponse{
+				Height: 10,
+				Balances: []structs.TransactionAmount{
+					{Text: "123456789012345678901234567", Numeric: huge, Currency: "uluna"},
+					{Text: "1", Numeric: big.NewInt(1), Currency: "uatom"},
+				},
+			},
+			want: `{"height": 10, "balances": [
+				{"text": "123456789012345678901234567", "numeric": 123456789012345678901234567, "currency": "uluna",
+				 "display": {"currency": "LUNA", "decimals": 6, "text": "123456789012345678901.234567"}},
+				{"text": "1", "numeric": 1, "currency": "uatom"}
+			]}`,
+		},
+		{
+			name: "rewards",
+			payload: structs.GetRewardResponse{
+				Height: 10,
+				Rewards: map[structs.Validator][]structs.TransactionAmount{
+					"terravaloper1lq40xgtqh3f3zt9prz4m74l6dlk506usv9ag54": {{Text: "1.500000000000000000", Numeric: big.NewInt(1500000000000000000), Exp: 18, Currency: "uusd"}},
+				},
+			},
+			want: `{"height": 10, "rewards": {"terravaloper1lq40xgtqh3f3zt9prz4m74l6dlk506usv9ag54": [
+				{"text": "1.500000000000000000", "numeric": 1500000000000000000, "exp": 18, "currency": "uusd",
+				 "display": {"currency": "UST", "decimals": 6, "text": "0.0000015"}}
+			]}}`,
+		},
+		{
+			name: "transaction",
+			payload: structs.Transaction{
+				Hash: "AB",
+				Fee:  []structs.TransactionAmount{{Text: "5000", Numeric: big.NewInt(5000), Currency: "ukrw"}},
+				Events: structs.TransactionEvents{{
+					Kind: "send",
+					Sub: []structs.SubsetEvent{{
+						Type:   []string{"send"},
+						Amount: map[string]structs.TransactionAmount{"rate": {Text: "0.5", Numeric: big.NewInt(5), Exp: 1}},
+						Sender: []structs.EventTransfer{{
+							Account: structs.Account{ID: "terra1a"},
+							Amounts: []structs.TransactionAmount{{Text: "2000000", Numeric: big.NewInt(2000000), Currency: "uluna"}},
+						}},
+					}},
+				}},
+			},
+			want: `{"id": "00000000-0000-0000-0000-000000000000", "hash": "AB", "time": "0001-01-01T00:00:00Z", "version": "", "has_errors": false,
+				"transaction_fee": [{"text": "5000", "numeric": 5000, "currency": "ukrw",
+					"display": {"currency": "KRT", "decimals": 6, "text": "0.005"}}],
+				"events": [{"kind": "send", "sub": [{"type": ["send"],
+					"amount": {"rate": {"text": "0.5", "numeric": 5, "exp": 1}},
+					"sender": [{"account": {"id": "terra1a"}, "amounts": [{"text": "2000000", "numeric": 2000000, "currency": "uluna",
+						"display": {"currency": "LUNA", "decimals": 6, "text": "2"}}]}]
+				}]}]}`,
+		},
+		{
+			name: "valued transaction",
+			payload: api.ValuedTransaction{
+				Transaction: structs.Transaction{
+					Hash: "AB",
+					Fee:  []structs.TransactionAmount{{Text: "5000", Numeric: big.NewInt(5000), Currency: "uluna"}},
+				},
+				Valuation: api.Valuation{
+					Currencies: []string{"uusd"},
+					Fee: []api.ValuedAmount{{
+						Amount: structs.TransactionAmount{Text: "5000", Numeric: big.NewInt(5000), Currency: "uluna"},
+						Values: []api.FiatValue{{TransactionAmount: structs.TransactionAmount{Text: "20", Numeric: big.NewInt(20), Currency: "uusd"}}},
+					}},
+				},
+			},
+			// only amounts of the transaction are annotated
+			want: `{"id": "00000000-0000-0000-0000-000000000000", "hash": "AB", "time": "0001-01-01T00:00:00Z", "version": "", "has_errors": false,
+				"transaction_fee": [{"text": "5000", "numeric": 5000, "currency": "uluna",
+					"display": {"currency": "LUNA", "decimals": 6, "text": "0.005"}}],
+				"valuation": {"currencies": ["uusd"], "fee": [{
+					"amount": {"text": "5000", "numeric": 5000, "currency": "uluna"},
+					"values": [{"text": "20", "numeric": 20, "currency": "uusd"}]}]}}`,
+		},
+		{
+			name:    "unknown payload",
+			payload: structs.Block{Hash: "AB", Height: 10},
+			want:    `{"id": "00000000-0000-0000-0000-000000000000", "hash": "AB", "height": 10, "time": "0001-01-01T00:00:00Z"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := annotatePayload(mapper.NewDenomRegistry(), tt.payload)
+			b, err := json.Marshal(got)
+			require.NoError(t, err)
+			require.JSONEq(t, tt.want, string(b))
+
+			// payload keeps its value for consumers in process
+			if ap, ok := got.(AnnotatedPayload); ok {
+				got = ap.Payload
+			}
+			require.Equal(t, tt.payload, got)
+
+			// annotated payload is still read by the manager as the original one
+			unmarshaled := reflect.New(reflect.TypeOf(tt.payload))
+			require.NoError(t, json.Unmarshal(b, unmarshaled.Interface()))
+			require.Equal(t, tt.payload, unmarshaled.Elem().Interface())
+		})
+	}
+}
+
+func TestIndexerClient_DenomAnnotation(t *testing.T) {
+	params := structs.HeightAccount{Account: "terra190vqdjtlpcq27xslcveglfmr4ynfwg7gxlzhjn", Height: 3000010, ChainID: testChainID}
+	balance := structs.GetAccountBalanceResponse{
+		Height:   params.Height,
+		Balances: []structs.TransactionAmount{{Text: "1500000", Numeric: big.NewInt(1500000), Currency: "uluna"}},
+	}
+
+	for _, annotate := range []bool{false, true} {
+		t.Run(fmt.Sprintf("annotate %t", annotate), func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			lcd := mocks.NewMockLCD(ctrl)
+			lcd.EXPECT().GetAccountBalance(gomock.Any(), params).Return(balance, nil)
+
+			ic := NewIndexerClient(context.Background(), zap.NewNop(), lcd, mocks.NewMockRPC(ctrl), 10, 1000)
+			if annotate {
+				ic.SetDenomAnnotation(mapper.NewDenomRegistry())
+			}
+			responses := newFakeManager(t, ic).Do(structs.ReqIDAccountBalance, params)
+			requireSequence(t, responses, map[string]int{"AccountBalance": 1})
+
+			got := structs.GetAccountBalanceResponse{}
+			require.NoError(t, json.Unmarshal(responses[0].Payload, &got))
+			require.Equal(t, balance, got)
+
+			display := struct {
+				Balances []struct {
+					Display *mapper.DisplayAmount `json:"display"`
+				} `json:"balances"`
+			}{}
+			require.NoError(t, json.Unmarshal(responses[0].Payload, &display))
+			require.Len(t, display.Balances, 1)
+			if !annotate {
+				require.Nil(t, display.Balances[0].Display)
+				return
+			}
+			require.Equal(t, &mapper.DisplayAmount{Currency: "LUNA", Decimals: 6, Text: "1.5"}, display.Balances[0].Display)
+		})
+	}
+}
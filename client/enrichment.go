@@ -0,0 +1,62 @@
+package client
+
+import (
+	"context"
+
+	cStructs "github.com/figment-networks/indexer-manager/worker/connectivity/structs"
+	"github.com/figment-networks/terra-worker/api"
+	"github.com/figment-networks/terra-worker/api/mapper"
+	"go.uber.org/zap"
+)
+
+// Enrichment holds stages applied to responses following RawToTransactionCh, in the order of its fields.
+// The same chain is used by GetTransactions and GetLatest and by tools converting transactions
+// without the worker (terra-export, terra-reprocess), so their output is the same. Nil stage is skipped
+type Enrichment struct {
+	// Taxes computes stability tax of transactions whose logs don't report it
+	Taxes *api.TaxResolver
+	// Sequences resolves account numbers and sequences of transaction signers
+	Sequences *api.SequenceResolver
+	// Valuer values transfers and fees, transactions are sent as api.ValuedTransaction
+	Valuer *api.Valuer
+	// Denoms annotates amounts of known denominations
+	Denoms *mapper.DenomRegistry
+}
+
+// SetEnrichment sets all stages applied to transactions sent by GetTransactions and GetLatest
+func (ic *IndexerClient) SetEnrichment(e Enrichment) {
+	ic.enrichment = e
+}
+
+// Chain passes responses from in channel to the returned one through enabled stages.
+// When every stage is disabled in channel is returned as is
+func (e Enrichment) Chain(ctx context.Context, logger *zap.Logger, in chan cStructs.OutResp) chan cStructs.OutResp {
+	out := resolveTaxes(ctx, logger, e.Taxes, in)
+	out = resolveSequences(ctx, logger, e.Sequences, out)
+	out = valueTransactions(ctx, logger, e.Valuer, out)
+	return annotateAmounts(ctx, logger, e.Denoms, out)
+}
+
+// enrichmentStage runs stage reading from in in its own goroutine, returning channel it writes to.
+// The returned channel is closed when stage returns
+func enrichmentStage(in chan cStructs.OutResp, stage func(out chan<- cStructs.OutResp)) chan cStructs.OutResp {
+	out := make(chan cStructs.OutResp, cap(in))
+	go func() {
+		defer close(out)
+		stage(out)
+	}()
+	return out
+}
+
+// Apply passes single response through enabled stages, for tools converting transactions one by one
+func (e Enrichment) Apply(ctx context.Context, logger *zap.Logger, resp cStructs.OutResp) (cStructs.OutResp, error) {
+	in := make(chan cStructs.OutResp, 1)
+	in <- resp
+	close(in)
+
+	enriched, ok := <-e.Chain(ctx, logger, in)
+	if !ok {
+		return resp, ctx.Err()
+	}
+	return enriched, nil
+}
@@ -0,0 +1,196 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	cStructs "github.com/figment-networks/indexer-manager/worker/connectivity/structs"
+	"github.com/figment-networks/indexing-engine/metrics"
+	"github.com/figment-networks/terra-worker/api"
+	"go.uber.org/zap"
+)
+
+// Task types of governance queries
+const (
+	ReqIDGetGovProposals = "GetGovProposals"
+	ReqIDGetGovVotes     = "GetGovVotes"
+	ReqIDGetGovDeposits  = "GetGovDeposits"
+	ReqIDGetGovTally     = "GetGovTally"
+)
+
+// GovRequest is payload of governance tasks.
+// Status filters proposals in GetGovProposals (eg. `voting_period`, `passed`), ProposalID is required by other tasks.
+type GovRequest struct {
+	ProposalID uint64 `json:"proposal_id"`
+	Status     string `json:"status"`
+	Height     uint64 `json:"height"`
+
+	ChainID string `json:"chain_id"`
+	Network string `json:"network"`
+}
+
+// GovTallyReport is tally of the proposal at given height
+type GovTallyReport struct {
+	ProposalID uint64 `json:"proposal_id"`
+	Height     uint64 `json:"height"`
+	Status     string `json:"status"`
+	// Final is set when voting has ended and the tally would not change anymore
+	Final bool         `json:"final"`
+	Tally api.GovTally `json:"tally"`
+}
+
+// GetGovProposals gets proposals with their status
+func (ic *IndexerClient) GetGovProposals(ctx context.Context, tr cStructs.TaskRequest, stream *cStructs.StreamAccess, client LCD) {
+	timer := metrics.NewTimer(getGovProposalsDuration)
+	defer timer.ObserveDuration()
+
+	gr, ok := ic.govRequest(tr, stream, false)
+	if !ok {
+		return
+	}
+
+	sCtx, cancel := context.WithTimeout(ctx, time.Second*20)
+	defer cancel()
+
+	proposals, err := client.GetGovProposals(sCtx, gr.Status, gr.Height)
+	if err != nil {
+		ic.govError(tr, stream, "Error getting proposals", err)
+		return
+	}
+
+	out := make(chan cStructs.OutResp, len(proposals))
+	for _, p := range proposals {
+		out <- cStructs.OutResp{ID: tr.Id, Type: "GovProposal", Payload: p}
+	}
+	close(out)
+
+	sendResp(ctx, tr.Id, out, ic.logger, stream, nil)
+}
+
+// GetGovVotes gets votes of the proposal
+func (ic *IndexerClient) GetGovVotes(ctx context.Context, tr cStructs.TaskRequest, stream *cStructs.StreamAccess, client LCD) {
+	timer := metrics.NewTimer(getGovVotesDuration)
+	defer timer.ObserveDuration()
+
+	gr, ok := ic.govRequest(tr, stream, true)
+	if !ok {
+		return
+	}
+
+	sCtx, cancel := context.WithTimeout(ctx, time.Second*20)
+	defer cancel()
+
+	votes, err := client.GetGovVotes(sCtx, gr.ProposalID, gr.Height)
+	if err != nil {
+		ic.govError(tr, stream, "Error getting votes", err)
+		return
+	}
+
+	out := make(chan cStructs.OutResp, len(votes))
+	for _, v := range votes {
+		out <- cStructs.OutResp{ID: tr.Id, Type: "GovVote", Payload: v}
+	}
+	close(out)
+
+	sendResp(ctx, tr.Id, out, ic.logger, stream, nil)
+}
+
+// GetGovDeposits gets deposits of the proposal
+func (ic *IndexerClient) GetGovDeposits(ctx context.Context, tr cStructs.TaskRequest, stream *cStructs.StreamAccess, client LCD) {
+	timer := metrics.NewTimer(getGovDepositsDuration)
+	defer timer.ObserveDuration()
+
+	gr, ok := ic.govRequest(tr, stream, true)
+	if !ok {
+		return
+	}
+
+	sCtx, cancel := context.WithTimeout(ctx, time.Second*20)
+	defer cancel()
+
+	deposits, err := client.GetGovDeposits(sCtx, gr.ProposalID, gr.Height)
+	if err != nil {
+		ic.govError(tr, stream, "Error getting deposits", err)
+		return
+	}
+
+	out := make(chan cStructs.OutResp, len(deposits))
+	for _, d := range deposits {
+		out <- cStructs.OutResp{ID: tr.Id, Type: "GovDeposit", Payload: d}
+	}
+	close(out)
+
+	sendResp(ctx, tr.Id, out, ic.logger, stream, nil)
+}
+
+// GetGovTally gets current tally of proposal in voting period, or the final one of finished proposal
+func (ic *IndexerClient) GetGovTally(ctx context.Context, tr cStructs.TaskRequest, stream *cStructs.StreamAccess, client LCD) {
+	timer := metrics.NewTimer(getGovTallyDuration)
+	defer timer.ObserveDuration()
+
+	gr, ok := ic.govRequest(tr, stream, true)
+	if !ok {
+		return
+	}
+
+	sCtx, cancel := context.WithTimeout(ctx, time.Second*20)
+	defer cancel()
+
+	proposal, err := client.GetGovProposal(sCtx, gr.ProposalID, gr.Height)
+	if err != nil {
+		ic.govError(tr, stream, "Error getting proposal", err)
+		return
+	}
+
+	report := GovTallyReport{
+		ProposalID: gr.ProposalID,
+		Height:     gr.Height,
+		Status:     proposal.Status,
+		Final:      proposal.Status != "DepositPeriod" && proposal.Status != "VotingPeriod",
+	}
+
+	if report.Final {
+		report.Tally = proposal.FinalTallyResult
+	} else if report.Tally, err = client.GetGovTally(sCtx, gr.ProposalID, gr.Height); err != nil {
+		ic.govError(tr, stream, "Error getting tally", err)
+		return
+	}
+
+	out := make(chan cStructs.OutResp, 1)
+	out <- cStructs.OutResp{ID: tr.Id, Type: "GovTally", Payload: report}
+	close(out)
+
+	sendResp(ctx, tr.Id, out, ic.logger, stream, nil)
+}
+
+func (ic *IndexerClient) govRequest(tr cStructs.TaskRequest, stream *cStructs.StreamAccess, needsProposal bool) (gr GovRequest, ok bool) {
+	if err := json.Unmarshal(tr.Payload, &gr); err != nil {
+		stream.Send(cStructs.TaskResponse{
+			Id:    tr.Id,
+			Error: cStructs.TaskError{Msg: "Cannot unmarshal payload"},
+			Final: true,
+		})
+		return gr, false
+	}
+
+	if needsProposal && gr.ProposalID == 0 {
+		stream.Send(cStructs.TaskResponse{
+			Id:    tr.Id,
+			Error: cStructs.TaskError{Msg: "proposal id has to be set"},
+			Final: true,
+		})
+		return gr, false
+	}
+
+	return gr, true
+}
+
+func (ic *IndexerClient) govError(tr cStructs.TaskRequest, stream *cStructs.StreamAccess, msg string, err error) {
+	ic.logger.Error("[TERRA-CLIENT] "+msg, zap.Error(err), zap.Stringer("taskID", tr.Id))
+	stream.Send(cStructs.TaskResponse{
+		Id:    tr.Id,
+		Error: cStructs.TaskError{Msg: msg + " " + err.Error()},
+		Final: true,
+	})
+}
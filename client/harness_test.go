@@ -0,0 +1,239 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/figment-networks/indexer-manager/structs"
+	cStructs "github.com/figment-networks/indexer-manager/worker/connectivity/structs"
+	"github.com/figment-networks/terra-worker/api"
+	"github.com/figment-networks/terra-worker/api/types"
+	"github.com/figment-networks/terra-worker/client/mocks"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"github.com/terra-project/core/app"
+)
+
+const harnessTimeout = 10 * time.Second
+
+// fakeManager is an in-process counterpart of indexer-manager.
+// It registers stream in IndexerClient, sends task requests and collects responses of every task.
+// Handlers might log after sending the final response, so IndexerClient should not use the test logger
+type fakeManager struct {
+	t      *testing.T
+	stream *cStructs.StreamAccess
+
+	lock  sync.Mutex
+	tasks map[uuid.UUID]chan cStructs.TaskResponse
+}
+
+func newFakeManager(t *testing.T, ic *IndexerClient) *fakeManager {
+	ctx, cancel := context.WithCancel(context.Background())
+	// stream is not closed, Close returns response channel to the shared pool
+	// while handlers might still send trailing responses. Cancelling context stops the workers
+	t.Cleanup(cancel)
+
+	fm := &fakeManager{
+		t:      t,
+		stream: cStructs.NewStreamAccess(),
+		tasks:  map[uuid.UUID]chan cStructs.TaskResponse{},
+	}
+	require.NoError(t, ic.RegisterStream(ctx, fm.stream))
+
+	go fm.dispatch(ctx)
+	return fm
+}
+
+func (fm *fakeManager) dispatch(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case resp := <-fm.stream.ResponseListener:
+			fm.lock.Lock()
+			ch, ok := fm.tasks[resp.Id]
+			fm.lock.Unlock()
+			if ok {
+				ch <- resp
+			}
+		}
+	}
+}
+
+// Do sends task request with given payload (raw []byte or value marshaled to json)
+// and returns all responses received until the first final one
+func (fm *fakeManager) Do(reqType string, payload interface{}) (responses []cStructs.TaskResponse) {
+	fm.t.Helper()
+
+	raw, ok := payload.([]byte)
+	if !ok {
+		var err error
+		raw, err = json.Marshal(payload)
+		require.NoError(fm.t, err)
+	}
+
+	id := uuid.New()
+	ch := make(chan cStructs.TaskResponse, 1000)
+	fm.lock.Lock()
+	fm.tasks[id] = ch
+	fm.lock.Unlock()
+
+	require.NoError(fm.t, fm.stream.Req(cStructs.TaskRequest{Id: id, Type: reqType, Payload: raw}))
+
+	timeout := time.After(harnessTimeout)
+	for {
+		select {
+		case resp := <-ch:
+			require.Equal(fm.t, id, resp.Id)
+			responses = append(responses, resp)
+			if resp.Final {
+				return responses
+			}
+		case <-timeout:
+			fm.t.Fatalf("timeout waiting for final response of %s, got %d responses", reqType, len(responses))
+			return responses
+		}
+	}
+}
+
+// requireSequence checks that responses are ordered, without errors and terminated with the final END.
+// Responses are counted by type, as handlers send them concurrently
+func requireSequence(t *testing.T, responses []cStructs.TaskResponse, expected map[string]int) {
+	t.Helper()
+
+	require.NotEmpty(t, responses)
+	got := map[string]int{}
+	for i, resp := range responses {
+		require.Empty(t, resp.Error.Msg)
+		require.Equal(t, uint64(i), resp.Order, "wrong order of response %d", i)
+
+		if i == len(responses)-1 {
+			require.Equal(t, "END", resp.Type)
+			require.True(t, resp.Final)
+			continue
+		}
+		require.False(t, resp.Final, "non final response %d marked as final", i)
+		require.NotEmpty(t, resp.Payload)
+		got[resp.Type]++
+	}
+	require.Equal(t, expected, got)
+}
+
+// requireTaskError checks that the task ended with the single final error response
+func requireTaskError(t *testing.T, responses []cStructs.TaskResponse, msg string) {
+	t.Helper()
+
+	require.Len(t, responses, 1)
+	require.True(t, responses[0].Final)
+	require.Contains(t, responses[0].Error.Msg, msg)
+}
+
+// fakeChain serves blocks and transactions from tx_search fixtures through RPC mock
+type fakeChain struct {
+	chainID    string
+	lastHeight uint64
+	txs        map[uint64][]types.TxResponse
+
+	// blocksErr is returned from every GetBlocksMeta call
+	blocksErr error
+}
+
+func newFakeChain(t *testing.T, chainID string, lastHeight uint64) *fakeChain {
+	fc := &fakeChain{chainID: chainID, lastHeight: lastHeight, txs: map[uint64][]types.TxResponse{}}
+
+	fixtures, err := filepath.Glob(filepath.Join("..", "api", "testdata", "tx_search", chainID, "*.json"))
+	require.NoError(t, err)
+	require.NotEmpty(t, fixtures)
+
+	for _, fixture := range fixtures {
+		b, err := ioutil.ReadFile(fixture)
+		require.NoError(t, err)
+
+		result := &types.GetTxSearchResponse{}
+		require.NoError(t, json.Unmarshal(b, result))
+		for _, tx := range result.Result.Txs {
+			h, err := strconv.ParseUint(tx.Height, 10, 64)
+			require.NoError(t, err)
+			require.LessOrEqual(t, h, lastHeight)
+			fc.txs[h] = append(fc.txs[h], tx)
+		}
+	}
+	return fc
+}
+
+// numTxs returns number of transactions in given (inclusive) height range
+func (fc *fakeChain) numTxs(startHeight, endHeight uint64) (n int) {
+	for h, txs := range fc.txs {
+		if h >= startHeight && h <= endHeight {
+			n += len(txs)
+		}
+	}
+	return n
+}
+
+// rpc returns RPC mock backed by the chain
+func (fc *fakeChain) rpc(ctrl *gomock.Controller) *mocks.MockRPC {
+	cdc := app.MakeCodec()
+
+	rpc := mocks.NewMockRPC(ctrl)
+	rpc.EXPECT().CDC().Return(cdc).AnyTimes()
+	rpc.EXPECT().GetBlocksMeta(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(fc.getBlocksMeta).AnyTimes()
+	rpc.EXPECT().SingularHeightWorker(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(fc.singularHeightWorker).AnyTimes()
+	return rpc
+}
+
+func (fc *fakeChain) getBlocksMeta(ctx context.Context, params structs.HeightRange, limit uint64, blocks *api.BlocksMap, end chan<- error) {
+	if fc.blocksErr != nil {
+		end <- fc.blocksErr
+		return
+	}
+
+	if limit == 0 {
+		limit = blockchainEndpointLimit
+	}
+	endHeight := params.EndHeight
+	if endHeight == 0 || endHeight > fc.lastHeight {
+		endHeight = fc.lastHeight
+	}
+	startHeight := params.StartHeight
+	if startHeight == 0 || endHeight-startHeight+1 > limit {
+		startHeight = endHeight - limit + 1
+	}
+
+	blocks.Lock()
+	defer blocks.Unlock()
+	for h := startHeight; h <= endHeight; h++ {
+		numTxs := uint64(len(fc.txs[h]))
+		blocks.Blocks[h] = structs.Block{
+			Hash:                 "BLOCK" + strconv.FormatUint(h, 10),
+			Height:               h,
+			ChainID:              fc.chainID,
+			Time:                 time.Date(2021, 6, 1, 0, 0, 0, 0, time.UTC).Add(time.Duration(h) * time.Second),
+			NumberOfTransactions: numTxs,
+		}
+		blocks.NumTxs += numTxs
+		if blocks.StartHeight == 0 || blocks.StartHeight > h {
+			blocks.StartHeight = h
+		}
+		if blocks.EndHeight == 0 || blocks.EndHeight < h {
+			blocks.EndHeight = h
+		}
+	}
+	end <- nil
+}
+
+func (fc *fakeChain) singularHeightWorker(ctx context.Context, wg *sync.WaitGroup, out chan types.TxResponse, in chan api.ToGet) {
+	defer wg.Done()
+	for current := range in {
+		for _, tx := range fc.txs[current.Height] {
+			out <- tx
+		}
+	}
+}
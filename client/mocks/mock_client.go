@@ -1,44 +1,45 @@
 // Code generated by MockGen. DO NOT EDIT.
-// Source: github.com/figment-networks/terra-worker/client (interfaces: RPC)
+// Source: github.com/figment-networks/terra-worker/client (interfaces: RPC,LCD)
 
 // Package mocks is a generated GoMock package.
 package mocks
 
 import (
 	context "context"
+	reflect "reflect"
+	sync "sync"
+
 	structs "github.com/figment-networks/indexer-manager/structs"
 	api "github.com/figment-networks/terra-worker/api"
 	types "github.com/figment-networks/terra-worker/api/types"
 	gomock "github.com/golang/mock/gomock"
 	amino "github.com/tendermint/go-amino"
-	reflect "reflect"
-	sync "sync"
 )
 
-// MockRPC is a mock of RPC interface
+// MockRPC is a mock of RPC interface.
 type MockRPC struct {
 	ctrl     *gomock.Controller
 	recorder *MockRPCMockRecorder
 }
 
-// MockRPCMockRecorder is the mock recorder for MockRPC
+// MockRPCMockRecorder is the mock recorder for MockRPC.
 type MockRPCMockRecorder struct {
 	mock *MockRPC
 }
 
-// NewMockRPC creates a new mock instance
+// NewMockRPC creates a new mock instance.
 func NewMockRPC(ctrl *gomock.Controller) *MockRPC {
 	mock := &MockRPC{ctrl: ctrl}
 	mock.recorder = &MockRPCMockRecorder{mock}
 	return mock
 }
 
-// EXPECT returns an object that allows the caller to indicate expected use
+// EXPECT returns an object that allows the caller to indicate expected use.
 func (m *MockRPC) EXPECT() *MockRPCMockRecorder {
 	return m.recorder
 }
 
-// CDC mocks base method
+// CDC mocks base method.
 func (m *MockRPC) CDC() *amino.Codec {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "CDC")
@@ -46,32 +47,236 @@ func (m *MockRPC) CDC() *amino.Codec {
 	return ret0
 }
 
-// CDC indicates an expected call of CDC
+// CDC indicates an expected call of CDC.
 func (mr *MockRPCMockRecorder) CDC() *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CDC", reflect.TypeOf((*MockRPC)(nil).CDC))
 }
 
-// GetBlocksMeta mocks base method
+// GetBlocksMeta mocks base method.
 func (m *MockRPC) GetBlocksMeta(arg0 context.Context, arg1 structs.HeightRange, arg2 uint64, arg3 *api.BlocksMap, arg4 chan<- error) {
 	m.ctrl.T.Helper()
 	m.ctrl.Call(m, "GetBlocksMeta", arg0, arg1, arg2, arg3, arg4)
 }
 
-// GetBlocksMeta indicates an expected call of GetBlocksMeta
+// GetBlocksMeta indicates an expected call of GetBlocksMeta.
 func (mr *MockRPCMockRecorder) GetBlocksMeta(arg0, arg1, arg2, arg3, arg4 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBlocksMeta", reflect.TypeOf((*MockRPC)(nil).GetBlocksMeta), arg0, arg1, arg2, arg3, arg4)
 }
 
-// SingularHeightWorker mocks base method
+// GetContractCode mocks base method.
+func (m *MockRPC) GetContractCode(arg0 context.Context, arg1 uint64, arg2 bool) (api.ContractCode, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetContractCode", arg0, arg1, arg2)
+	ret0, _ := ret[0].(api.ContractCode)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetContractCode indicates an expected call of GetContractCode.
+func (mr *MockRPCMockRecorder) GetContractCode(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetContractCode", reflect.TypeOf((*MockRPC)(nil).GetContractCode), arg0, arg1, arg2)
+}
+
+// SearchTxByQuery mocks base method.
+func (m *MockRPC) SearchTxByQuery(arg0 context.Context, arg1 string, arg2 structs.HeightRange, arg3, arg4 int) ([]types.TxResponse, uint64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SearchTxByQuery", arg0, arg1, arg2, arg3, arg4)
+	ret0, _ := ret[0].([]types.TxResponse)
+	ret1, _ := ret[1].(uint64)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// SearchTxByQuery indicates an expected call of SearchTxByQuery.
+func (mr *MockRPCMockRecorder) SearchTxByQuery(arg0, arg1, arg2, arg3, arg4 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SearchTxByQuery", reflect.TypeOf((*MockRPC)(nil).SearchTxByQuery), arg0, arg1, arg2, arg3, arg4)
+}
+
+// SingularHeightWorker mocks base method.
 func (m *MockRPC) SingularHeightWorker(arg0 context.Context, arg1 *sync.WaitGroup, arg2 chan types.TxResponse, arg3 chan api.ToGet) {
 	m.ctrl.T.Helper()
 	m.ctrl.Call(m, "SingularHeightWorker", arg0, arg1, arg2, arg3)
 }
 
-// SingularHeightWorker indicates an expected call of SingularHeightWorker
+// SingularHeightWorker indicates an expected call of SingularHeightWorker.
 func (mr *MockRPCMockRecorder) SingularHeightWorker(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SingularHeightWorker", reflect.TypeOf((*MockRPC)(nil).SingularHeightWorker), arg0, arg1, arg2, arg3)
 }
+
+// MockLCD is a mock of LCD interface.
+type MockLCD struct {
+	ctrl     *gomock.Controller
+	recorder *MockLCDMockRecorder
+}
+
+// MockLCDMockRecorder is the mock recorder for MockLCD.
+type MockLCDMockRecorder struct {
+	mock *MockLCD
+}
+
+// NewMockLCD creates a new mock instance.
+func NewMockLCD(ctrl *gomock.Controller) *MockLCD {
+	mock := &MockLCD{ctrl: ctrl}
+	mock.recorder = &MockLCDMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockLCD) EXPECT() *MockLCDMockRecorder {
+	return m.recorder
+}
+
+// GetAccountBalance mocks base method.
+func (m *MockLCD) GetAccountBalance(arg0 context.Context, arg1 structs.HeightAccount) (structs.GetAccountBalanceResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAccountBalance", arg0, arg1)
+	ret0, _ := ret[0].(structs.GetAccountBalanceResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAccountBalance indicates an expected call of GetAccountBalance.
+func (mr *MockLCDMockRecorder) GetAccountBalance(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAccountBalance", reflect.TypeOf((*MockLCD)(nil).GetAccountBalance), arg0, arg1)
+}
+
+// GetAccountDelegations mocks base method.
+func (m *MockLCD) GetAccountDelegations(arg0 context.Context, arg1 structs.HeightAccount) (structs.GetAccountDelegationsResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAccountDelegations", arg0, arg1)
+	ret0, _ := ret[0].(structs.GetAccountDelegationsResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAccountDelegations indicates an expected call of GetAccountDelegations.
+func (mr *MockLCDMockRecorder) GetAccountDelegations(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAccountDelegations", reflect.TypeOf((*MockLCD)(nil).GetAccountDelegations), arg0, arg1)
+}
+
+// GetGovDeposits mocks base method.
+func (m *MockLCD) GetGovDeposits(arg0 context.Context, arg1, arg2 uint64) ([]api.GovDeposit, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetGovDeposits", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]api.GovDeposit)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetGovDeposits indicates an expected call of GetGovDeposits.
+func (mr *MockLCDMockRecorder) GetGovDeposits(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetGovDeposits", reflect.TypeOf((*MockLCD)(nil).GetGovDeposits), arg0, arg1, arg2)
+}
+
+// GetGovProposal mocks base method.
+func (m *MockLCD) GetGovProposal(arg0 context.Context, arg1, arg2 uint64) (api.GovProposal, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetGovProposal", arg0, arg1, arg2)
+	ret0, _ := ret[0].(api.GovProposal)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetGovProposal indicates an expected call of GetGovProposal.
+func (mr *MockLCDMockRecorder) GetGovProposal(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetGovProposal", reflect.TypeOf((*MockLCD)(nil).GetGovProposal), arg0, arg1, arg2)
+}
+
+// GetGovProposals mocks base method.
+func (m *MockLCD) GetGovProposals(arg0 context.Context, arg1 string, arg2 uint64) ([]api.GovProposal, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetGovProposals", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]api.GovProposal)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetGovProposals indicates an expected call of GetGovProposals.
+func (mr *MockLCDMockRecorder) GetGovProposals(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetGovProposals", reflect.TypeOf((*MockLCD)(nil).GetGovProposals), arg0, arg1, arg2)
+}
+
+// GetGovTally mocks base method.
+func (m *MockLCD) GetGovTally(arg0 context.Context, arg1, arg2 uint64) (api.GovTally, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetGovTally", arg0, arg1, arg2)
+	ret0, _ := ret[0].(api.GovTally)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetGovTally indicates an expected call of GetGovTally.
+func (mr *MockLCDMockRecorder) GetGovTally(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetGovTally", reflect.TypeOf((*MockLCD)(nil).GetGovTally), arg0, arg1, arg2)
+}
+
+// GetGovVotes mocks base method.
+func (m *MockLCD) GetGovVotes(arg0 context.Context, arg1, arg2 uint64) ([]api.GovVote, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetGovVotes", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]api.GovVote)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetGovVotes indicates an expected call of GetGovVotes.
+func (mr *MockLCDMockRecorder) GetGovVotes(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetGovVotes", reflect.TypeOf((*MockLCD)(nil).GetGovVotes), arg0, arg1, arg2)
+}
+
+// GetOracleFeeder mocks base method.
+func (m *MockLCD) GetOracleFeeder(arg0 context.Context, arg1 structs.HeightAccount) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOracleFeeder", arg0, arg1)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOracleFeeder indicates an expected call of GetOracleFeeder.
+func (mr *MockLCDMockRecorder) GetOracleFeeder(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOracleFeeder", reflect.TypeOf((*MockLCD)(nil).GetOracleFeeder), arg0, arg1)
+}
+
+// GetOracleMissCounter mocks base method.
+func (m *MockLCD) GetOracleMissCounter(arg0 context.Context, arg1 structs.HeightAccount) (uint64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOracleMissCounter", arg0, arg1)
+	ret0, _ := ret[0].(uint64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOracleMissCounter indicates an expected call of GetOracleMissCounter.
+func (mr *MockLCDMockRecorder) GetOracleMissCounter(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOracleMissCounter", reflect.TypeOf((*MockLCD)(nil).GetOracleMissCounter), arg0, arg1)
+}
+
+// GetReward mocks base method.
+func (m *MockLCD) GetReward(arg0 context.Context, arg1 structs.HeightAccount) (structs.GetRewardResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetReward", arg0, arg1)
+	ret0, _ := ret[0].(structs.GetRewardResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetReward indicates an expected call of GetReward.
+func (mr *MockLCDMockRecorder) GetReward(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetReward", reflect.TypeOf((*MockLCD)(nil).GetReward), arg0, arg1)
+}
@@ -0,0 +1,217 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/figment-networks/indexer-manager/structs"
+	cStructs "github.com/figment-networks/indexer-manager/worker/connectivity/structs"
+	"github.com/figment-networks/indexing-engine/metrics"
+	"github.com/figment-networks/terra-worker/api"
+	"github.com/figment-networks/terra-worker/api/mapper"
+	"github.com/figment-networks/terra-worker/api/types"
+	"go.uber.org/zap"
+)
+
+// ReqIDGetOracleFeederReport is the task type of oracle feeder performance report
+const ReqIDGetOracleFeederReport = "GetOracleFeederReport"
+
+// OracleFeederReportRequest is payload of GetOracleFeederReport task
+type OracleFeederReportRequest struct {
+	Validator   string `json:"validator"`
+	StartHeight uint64 `json:"start_height"`
+	EndHeight   uint64 `json:"end_height"`
+
+	ChainID string `json:"chain_id"`
+	Network string `json:"network"`
+}
+
+// OracleVote is single exchange rate vote submitted for validator
+type OracleVote struct {
+	Height uint64                `json:"height"`
+	Hash   string                `json:"hash"`
+	Type   string                `json:"type"`
+	Sub    []structs.SubsetEvent `json:"sub,omitempty"`
+}
+
+// OracleFeederReport summarizes validator's oracle activity in given height range.
+// GetOracleFeederReport sends the requested range as consecutive reports of at most bigPage heights
+type OracleFeederReport struct {
+	Validator   string `json:"validator"`
+	Feeder      string `json:"feeder"`
+	StartHeight uint64 `json:"start_height"`
+	EndHeight   uint64 `json:"end_height"`
+
+	// MissCounterStart and MissCounterEnd are the values of validator's miss counter
+	// at the boundaries of range. Counter is reset at the end of every slash window.
+	MissCounterStart uint64 `json:"miss_counter_start"`
+	MissCounterEnd   uint64 `json:"miss_counter_end"`
+
+	Votes []OracleVote `json:"votes"`
+}
+
+// oracleVoteQueries maps message types to the event queries that find them
+var oracleVoteQueries = map[string]string{
+	"aggregateexchangeratevote": "aggregate_vote.voter='%s'",
+	"exchangeratevote":          "vote.voter='%s'",
+}
+
+// GetOracleFeederReport gets oracle votes, miss counters and feeder of validator, streamed as reports of consecutive height windows
+func (ic *IndexerClient) GetOracleFeederReport(ctx context.Context, tr cStructs.TaskRequest, stream *cStructs.StreamAccess, rpc RPC, lcd LCD) {
+	timer := metrics.NewTimer(getOracleFeederReportDuration)
+	defer timer.ObserveDuration()
+
+	ofr := &OracleFeederReportRequest{}
+	err := json.Unmarshal(tr.Payload, ofr)
+	if err != nil {
+		stream.Send(cStructs.TaskResponse{
+			Id:    tr.Id,
+			Error: cStructs.TaskError{Msg: "Cannot unmarshal payload"},
+			Final: true,
+		})
+		return
+	}
+
+	if ofr.Validator == "" || ofr.EndHeight < ofr.StartHeight {
+		stream.Send(cStructs.TaskResponse{
+			Id:    tr.Id,
+			Error: cStructs.TaskError{Msg: "validator has to be set and end height cannot be lower than start height"},
+			Final: true,
+		})
+		return
+	}
+
+	sCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	out := make(chan cStructs.OutResp, 1)
+	fin := make(chan bool, 2)
+	failed := make(chan error, 1)
+
+	go sendRespOrFail(sCtx, tr.Id, out, failed, ic.logger, stream, fin)
+
+	// range is reported in windows of bigPage heights, each sent as soon as it's ready
+	for start := ofr.StartHeight; ; start += ic.bigPage {
+		window := *ofr
+		window.StartHeight = start
+		if window.EndHeight = start + ic.bigPage - 1; window.EndHeight > ofr.EndHeight {
+			window.EndHeight = ofr.EndHeight
+		}
+
+		wCtx, wCancel := context.WithTimeout(sCtx, time.Minute*2)
+		report, err := getOracleFeederReport(wCtx, ic.logger, rpc, lcd, ic.mapperOptions(), window)
+		wCancel()
+		if err != nil {
+			ic.logger.Error("Error getting oracle feeder report", zap.Error(err))
+			failed <- errors.New("Error getting oracle feeder report " + err.Error())
+			<-fin
+			return
+		}
+
+		out <- cStructs.OutResp{
+			ID:      tr.Id,
+			Type:    "OracleFeederReport",
+			Payload: report,
+		}
+		if window.EndHeight == ofr.EndHeight {
+			break
+		}
+	}
+	close(out)
+
+	select {
+	case <-sCtx.Done():
+	case <-fin:
+	}
+}
+
+func getOracleFeederReport(ctx context.Context, logger *zap.Logger, rpc RPC, lcd LCD, opts mapper.Options, ofr OracleFeederReportRequest) (report OracleFeederReport, err error) {
+	report = OracleFeederReport{
+		Validator:   ofr.Validator,
+		StartHeight: ofr.StartHeight,
+		EndHeight:   ofr.EndHeight,
+		Votes:       []OracleVote{},
+	}
+
+	if report.Feeder, err = lcd.GetOracleFeeder(ctx, structs.HeightAccount{Account: ofr.Validator, Height: ofr.EndHeight}); err != nil {
+		return report, fmt.Errorf("error getting feeder: %w", err)
+	}
+
+	if report.MissCounterStart, err = lcd.GetOracleMissCounter(ctx, structs.HeightAccount{Account: ofr.Validator, Height: ofr.StartHeight}); err != nil {
+		return report, fmt.Errorf("error getting miss counter: %w", err)
+	}
+
+	if report.MissCounterEnd, err = lcd.GetOracleMissCounter(ctx, structs.HeightAccount{Account: ofr.Validator, Height: ofr.EndHeight}); err != nil {
+		return report, fmt.Errorf("error getting miss counter: %w", err)
+	}
+
+	hr := structs.HeightRange{StartHeight: ofr.StartHeight, EndHeight: ofr.EndHeight, ChainID: ofr.ChainID, Network: ofr.Network}
+	for kind, query := range oracleVoteQueries {
+		txs, err := searchAllTxs(ctx, rpc, fmt.Sprintf(query, ofr.Validator), hr)
+		if err != nil {
+			return report, fmt.Errorf("error searching %s: %w", kind, err)
+		}
+
+		out := make(chan cStructs.OutResp, len(txs))
+		if err := api.RawToTransaction(logger, rpc.CDC(), opts, txs, map[uint64]structs.Block{}, out); err != nil {
+			return report, fmt.Errorf("error converting %s: %w", kind, err)
+		}
+		close(out)
+
+		for o := range out {
+			t, ok := o.Payload.(structs.Transaction)
+			if !ok {
+				continue
+			}
+			for _, ev := range t.Events {
+				if ev.Kind != kind || !votedFor(ev.Sub, ofr.Validator) {
+					continue
+				}
+				report.Votes = append(report.Votes, OracleVote{
+					Height: t.Height,
+					Hash:   t.Hash,
+					Type:   kind,
+					Sub:    ev.Sub,
+				})
+			}
+		}
+	}
+
+	sort.Slice(report.Votes, func(i, j int) bool {
+		if report.Votes[i].Height == report.Votes[j].Height {
+			return report.Votes[i].Hash < report.Votes[j].Hash
+		}
+		return report.Votes[i].Height < report.Votes[j].Height
+	})
+
+	return report, nil
+}
+
+// searchAllTxs pages through all transactions matching query
+func searchAllTxs(ctx context.Context, rpc RPC, query string, hr structs.HeightRange) (txs []types.TxResponse, err error) {
+	for p := 1; ; p++ {
+		resp, total, err := rpc.SearchTxByQuery(ctx, query, hr, p, page)
+		if err != nil {
+			return txs, err
+		}
+		txs = append(txs, resp...)
+		if len(resp) == 0 || uint64(len(txs)) >= total {
+			return txs, nil
+		}
+	}
+}
+
+func votedFor(subs []structs.SubsetEvent, validator string) bool {
+	for _, s := range subs {
+		for _, v := range s.Node["validator"] {
+			if v.ID == validator {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,26 @@
+package client
+
+import (
+	"context"
+
+	cStructs "github.com/figment-networks/indexer-manager/worker/connectivity/structs"
+	"github.com/figment-networks/terra-worker/api"
+	"go.uber.org/zap"
+)
+
+// SetSequenceResolution enables resolution of account numbers and sequences of signers of transactions
+// sent by GetTransactions and GetLatest. Nil resolver disables resolution
+func (ic *IndexerClient) SetSequenceResolution(r *api.SequenceResolver) {
+	ic.enrichment.Sequences = r
+}
+
+// resolveSequences passes responses from in channel to the returned one, resolving sequences of transaction signers.
+// When resolution is disabled in channel is returned as is
+func resolveSequences(ctx context.Context, logger *zap.Logger, r *api.SequenceResolver, in chan cStructs.OutResp) chan cStructs.OutResp {
+	if r == nil {
+		return in
+	}
+	return enrichmentStage(in, func(out chan<- cStructs.OutResp) {
+		api.ResolveSequencesCh(ctx, logger, r, in, out)
+	})
+}
@@ -0,0 +1,26 @@
+package client
+
+import (
+	"context"
+
+	cStructs "github.com/figment-networks/indexer-manager/worker/connectivity/structs"
+	"github.com/figment-networks/terra-worker/api"
+	"go.uber.org/zap"
+)
+
+// SetTaxResolution enables computation of stability tax of transactions sent by GetTransactions and GetLatest,
+// whose logs don't report the tax charged (columbus-4 onwards). Nil resolver disables it
+func (ic *IndexerClient) SetTaxResolution(r *api.TaxResolver) {
+	ic.enrichment.Taxes = r
+}
+
+// resolveTaxes passes responses from in channel to the returned one, splitting fees of transactions into gas fee and tax.
+// When resolution is disabled in channel is returned as is
+func resolveTaxes(ctx context.Context, logger *zap.Logger, r *api.TaxResolver, in chan cStructs.OutResp) chan cStructs.OutResp {
+	if r == nil {
+		return in
+	}
+	return enrichmentStage(in, func(out chan<- cStructs.OutResp) {
+		api.ResolveTaxesCh(ctx, logger, r, in, out)
+	})
+}
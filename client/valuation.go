@@ -0,0 +1,26 @@
+package client
+
+import (
+	"context"
+
+	cStructs "github.com/figment-networks/indexer-manager/worker/connectivity/structs"
+	"github.com/figment-networks/terra-worker/api"
+	"go.uber.org/zap"
+)
+
+// SetFiatValuation enables valuation of transfers and fees of transactions sent by GetTransactions and GetLatest.
+// Transactions are sent as api.ValuedTransaction. Nil valuer disables valuation
+func (ic *IndexerClient) SetFiatValuation(v *api.Valuer) {
+	ic.enrichment.Valuer = v
+}
+
+// valueTransactions passes responses from in channel to the returned one, valuing transactions.
+// When valuation is disabled in channel is returned as is
+func valueTransactions(ctx context.Context, logger *zap.Logger, v *api.Valuer, in chan cStructs.OutResp) chan cStructs.OutResp {
+	if v == nil {
+		return in
+	}
+	return enrichmentStage(in, func(out chan<- cStructs.OutResp) {
+		api.ValueTransactionsCh(ctx, logger, v, in, out)
+	})
+}
@@ -0,0 +1,83 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	cStructs "github.com/figment-networks/indexer-manager/worker/connectivity/structs"
+	"github.com/figment-networks/indexing-engine/metrics"
+	"github.com/figment-networks/terra-worker/api/mapper"
+	"go.uber.org/zap"
+)
+
+// ReqIDGetContractCode is the task type of fetching contract code
+const ReqIDGetContractCode = "GetContractCode"
+
+// ContractCodeRequest is payload of GetContractCode task
+type ContractCodeRequest struct {
+	CodeID uint64 `json:"code_id"`
+
+	ChainID string `json:"chain_id"`
+	Network string `json:"network"`
+}
+
+// SetWasmByteCode sets how contract code is returned in `store_code` subevents and by GetContractCode task
+// (mapper.WasmByteCodeHash by default)
+func (ic *IndexerClient) SetWasmByteCode(mode mapper.WasmByteCodeMode) {
+	ic.wasmByteCode = mode
+}
+
+// SetContractRegistry sets registry decoding calls of well known contracts, nil disables decoding
+func (ic *IndexerClient) SetContractRegistry(cr *mapper.ContractRegistry) {
+	ic.contracts = cr
+}
+
+// mapperOptions returns options of transactions conversion
+func (ic *IndexerClient) mapperOptions() mapper.Options {
+	return mapper.Options{
+		EmbedWasmByteCode: ic.wasmByteCode == mapper.WasmByteCodeEmbed,
+		Contracts:         ic.contracts,
+	}
+}
+
+// GetContractCode gets contract code stored on chain
+func (ic *IndexerClient) GetContractCode(ctx context.Context, tr cStructs.TaskRequest, stream *cStructs.StreamAccess, client RPC) {
+	timer := metrics.NewTimer(getContractCodeDuration)
+	defer timer.ObserveDuration()
+
+	ccr := &ContractCodeRequest{}
+	err := json.Unmarshal(tr.Payload, ccr)
+	if err != nil {
+		stream.Send(cStructs.TaskResponse{
+			Id:    tr.Id,
+			Error: cStructs.TaskError{Msg: "Cannot unmarshal payload"},
+			Final: true,
+		})
+		return
+	}
+
+	sCtx, cancel := context.WithTimeout(ctx, time.Second*20)
+	defer cancel()
+
+	code, err := client.GetContractCode(sCtx, ccr.CodeID, ic.wasmByteCode != mapper.WasmByteCodeOmit)
+	if err != nil {
+		ic.logger.Error("Error getting contract code", zap.Error(err))
+		stream.Send(cStructs.TaskResponse{
+			Id:    tr.Id,
+			Error: cStructs.TaskError{Msg: "Error getting contract code " + err.Error()},
+			Final: true,
+		})
+		return
+	}
+
+	out := make(chan cStructs.OutResp, 1)
+	out <- cStructs.OutResp{
+		ID:      tr.Id,
+		Type:    "ContractCode",
+		Payload: code,
+	}
+	close(out)
+
+	sendResp(ctx, tr.Id, out, ic.logger, stream, nil)
+}
@@ -0,0 +1,162 @@
+// Package config reads configs of commands from a json file or environment variables. It's the loader of terra-worker,
+// shared by terra-export and terra-reprocess, so settings they have in common are declared and defaulted once (Shared)
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"reflect"
+	"strconv"
+	"time"
+
+	"github.com/kelseyhightower/envconfig"
+
+	"github.com/figment-networks/terra-worker/cmd/common/enrichment"
+)
+
+// Shared holds settings of node access and transactions conversion, embedded in configs of commands
+// (the same json keys and environment variables)
+type Shared struct {
+	TerraRPCAddr string `json:"terra_rpc_addr" envconfig:"TERRA_RPC_ADDR" required:"true"`
+	DatahubKey   string `json:"datahub_key" envconfig:"DATAHUB_KEY"`
+	ChainID      string `json:"chain_id" envconfig:"CHAIN_ID"`
+
+	ContractsConfigPath string `json:"contracts_config" envconfig:"CONTRACTS_CONFIG"`
+	// ContractCodeIDsCacheSize is the number of contracts with cached code id,
+	// code ids are only looked up when contracts config lists `code_ids`
+	ContractCodeIDsCacheSize int `json:"contract_code_ids_cache_size" envconfig:"CONTRACT_CODE_IDS_CACHE_SIZE" default:"10000"`
+	// Enrichment enables stages applied to converted transactions (taxes, sequences, fiat valuation, amount annotation)
+	enrichment.Config
+	// WasmByteCode sets how contract code of `store_code` is returned:
+	// "embed" - whole code embedded in transaction events (legacy)
+	// "hash" - only checksum and size in events, code available with GetContractCode task
+	// "omit" - only checksum and size, code is never returned
+	WasmByteCode string `json:"wasm_byte_code" envconfig:"WASM_BYTE_CODE" default:"hash"`
+	// ArchiveDir is the directory of raw node responses archive, used in ArchiveMode
+	ArchiveDir string `json:"archive_dir" envconfig:"ARCHIVE_DIR"`
+	// ArchiveMode sets how the archive is used:
+	// "record" - responses fetched from rpc and lcd are stored in the archive
+	// "replay" - requests are served from the archive instead of the network
+	// empty disables the archive
+	ArchiveMode string `json:"archive_mode" envconfig:"ARCHIVE_MODE"`
+
+	BigPage           float64 `json:"big_page" envconfig:"BIG_PAGE" default:"1000"`
+	RequestsPerSecond int64   `json:"requests_per_second" envconfig:"REQUESTS_PER_SECOND" default:"33"`
+}
+
+// Load reads config from json file at path. When path is empty or the file doesn't set `terra_rpc_addr`,
+// environment variables are read on top of it
+func Load(path string, cfg interface{}) error {
+	if path != "" {
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		if err := json.Unmarshal(data, cfg); err != nil {
+			return err
+		}
+		node := struct {
+			TerraRPCAddr string `json:"terra_rpc_addr"`
+		}{}
+		if err := json.Unmarshal(data, &node); err != nil {
+			return err
+		}
+		if node.TerraRPCAddr != "" {
+			return setDefaults(reflect.ValueOf(cfg).Elem())
+		}
+	}
+
+	return FromEnv(cfg)
+}
+
+// FromFile reads the config from a json file, fields the file doesn't set get values of their `default` tags
+// (the same as from environment variables)
+func FromFile(path string, cfg interface{}) error {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	return fromJSON(data, cfg)
+}
+
+// FromEnv reads the config from environment variables
+func FromEnv(cfg interface{}) error {
+	return envconfig.Process("", cfg)
+}
+
+func fromJSON(data []byte, cfg interface{}) error {
+	if err := json.Unmarshal(data, cfg); err != nil {
+		return err
+	}
+	return setDefaults(reflect.ValueOf(cfg).Elem())
+}
+
+// setDefaults sets zero fields of struct v (and of its embedded structs) to values of their `default` tags
+func setDefaults(v reflect.Value) error {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f, fv := t.Field(i), v.Field(i)
+		if !fv.CanSet() {
+			continue
+		}
+		if f.Anonymous && fv.Kind() == reflect.Struct {
+			if err := setDefaults(fv); err != nil {
+				return err
+			}
+			continue
+		}
+
+		def, ok := f.Tag.Lookup("default")
+		if !ok || !fv.IsZero() {
+			continue
+		}
+		if err := setValue(fv, def); err != nil {
+			return fmt.Errorf("invalid default of %s: %w", f.Name, err)
+		}
+	}
+	return nil
+}
+
+func setValue(fv reflect.Value, s string) error {
+	if fv.Type() == reflect.TypeOf(time.Duration(0)) {
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return err
+		}
+		fv.SetInt(int64(d))
+		return nil
+	}
+
+	switch fv.Kind() {
+	case reflect.String:
+		fv.SetString(s)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return err
+		}
+		fv.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(s, 10, fv.Type().Bits())
+		if err != nil {
+			return err
+		}
+		fv.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(s, 10, fv.Type().Bits())
+		if err != nil {
+			return err
+		}
+		fv.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		n, err := strconv.ParseFloat(s, fv.Type().Bits())
+		if err != nil {
+			return err
+		}
+		fv.SetFloat(n)
+	default:
+		return fmt.Errorf("unsupported type %s", fv.Type())
+	}
+	return nil
+}
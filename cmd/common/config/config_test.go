@@ -0,0 +1,69 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type testConfig struct {
+	Interval time.Duration `json:"interval" envconfig:"INTERVAL" default:"10s"`
+	Shared
+}
+
+func writeFile(t *testing.T, content string) string {
+	path := filepath.Join(t.TempDir(), "config.json")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func setenv(t *testing.T, key, value string) {
+	prev, ok := os.LookupEnv(key)
+	require.NoError(t, os.Setenv(key, value))
+	t.Cleanup(func() {
+		if ok {
+			os.Setenv(key, prev)
+		} else {
+			os.Unsetenv(key)
+		}
+	})
+}
+
+func TestLoad_FileDefaults(t *testing.T) {
+	path := writeFile(t, `{"terra_rpc_addr": "http://localhost:26657", "big_page": 50, "resolve_taxes": true}`)
+
+	cfg := &testConfig{}
+	require.NoError(t, Load(path, cfg))
+
+	require.Equal(t, "http://localhost:26657", cfg.TerraRPCAddr)
+	require.Equal(t, float64(50), cfg.BigPage)
+	require.True(t, cfg.ResolveTaxes)
+	require.Equal(t, 10*time.Second, cfg.Interval)
+	require.Equal(t, int64(33), cfg.RequestsPerSecond)
+	require.Equal(t, 10000, cfg.ContractCodeIDsCacheSize)
+	require.Equal(t, "hash", cfg.WasmByteCode)
+	require.Equal(t, uint64(100), cfg.SequenceSearchDepth)
+	require.Equal(t, 10000, cfg.TaxRatesCacheSize)
+}
+
+func TestLoad_Env(t *testing.T) {
+	setenv(t, "TERRA_RPC_ADDR", "http://node:26657")
+	setenv(t, "BIG_PAGE", "20")
+
+	cfg := &testConfig{}
+	require.NoError(t, Load(writeFile(t, `{"chain_id": "columbus-5"}`), cfg))
+
+	require.Equal(t, "http://node:26657", cfg.TerraRPCAddr)
+	require.Equal(t, float64(20), cfg.BigPage)
+	require.Equal(t, int64(33), cfg.RequestsPerSecond)
+	require.Equal(t, 10*time.Second, cfg.Interval)
+}
+
+func TestLoad_Required(t *testing.T) {
+	setenv(t, "TERRA_RPC_ADDR", "")
+	os.Unsetenv("TERRA_RPC_ADDR")
+	require.Error(t, Load("", &testConfig{}))
+}
@@ -0,0 +1,76 @@
+// Package enrichment builds stages applied to converted transactions from the config shared by terra-worker,
+// terra-export and terra-reprocess, so every command returns transactions enriched the same way
+package enrichment
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/figment-networks/terra-worker/api"
+	"github.com/figment-networks/terra-worker/api/mapper"
+	"github.com/figment-networks/terra-worker/client"
+)
+
+// Config holds enrichment settings, embedded in configs of commands (the same json keys and environment variables)
+type Config struct {
+	// DenomsConfigPath is json file with cw20 and ibc denominations, in addition to built-in terra natives
+	DenomsConfigPath string `json:"denoms_config" envconfig:"DENOMS_CONFIG"`
+	// AnnotateAmounts adds display denom, decimals and human readable value to amounts of known denominations
+	AnnotateAmounts bool `json:"annotate_amounts" envconfig:"ANNOTATE_AMOUNTS" default:"false"`
+	// FiatCurrencies is a comma-separated list of denominations (eg. `uusd,ukrw`) in which transfers and fees
+	// are valued with oracle exchange rates at transaction height, empty disables valuation
+	FiatCurrencies string `json:"fiat_currencies" envconfig:"FIAT_CURRENCIES"`
+	// FiatRatesCacheSize is the number of recent heights with cached exchange rates
+	FiatRatesCacheSize int `json:"fiat_rates_cache_size" envconfig:"FIAT_RATES_CACHE_SIZE" default:"10000"`
+	// ResolveTaxes enables computation of stability tax from treasury tax rate and caps at transaction height,
+	// for transactions whose logs don't report it (columbus-4 onwards)
+	ResolveTaxes bool `json:"resolve_taxes" envconfig:"RESOLVE_TAXES" default:"false"`
+	// TaxRatesCacheSize is the number of recent heights with cached tax rate and caps
+	TaxRatesCacheSize int `json:"tax_rates_cache_size" envconfig:"TAX_RATES_CACHE_SIZE" default:"10000"`
+	// ResolveSequences enables lookup of account number and sequence of every transaction signer (one lcd call per signer)
+	ResolveSequences bool `json:"resolve_sequences" envconfig:"RESOLVE_SEQUENCES" default:"false"`
+	// SequenceSearchDepth is the number of sequences checked below the account sequence at transaction height
+	SequenceSearchDepth uint64 `json:"sequence_search_depth" envconfig:"SEQUENCE_SEARCH_DEPTH" default:"100"`
+	// SequencesCacheSize is the number of recent (account, height) pairs with cached account number and sequence
+	SequencesCacheSize int `json:"sequences_cache_size" envconfig:"SEQUENCES_CACHE_SIZE" default:"10000"`
+}
+
+// NeedsLCD reports whether any enabled stage fetches data from lcd
+func (cfg Config) NeedsLCD() bool {
+	return cfg.ResolveTaxes || cfg.ResolveSequences || cfg.FiatCurrencies != ""
+}
+
+// New builds stages enabled in cfg, lcd is the client of terra lcd used by the resolvers
+func New(cfg Config, chainID string, lcd *api.Client) (client.Enrichment, error) {
+	e := client.Enrichment{}
+	if cfg.AnnotateAmounts {
+		denoms := mapper.NewDenomRegistry()
+		if cfg.DenomsConfigPath != "" {
+			if err := loadDenoms(denoms, cfg.DenomsConfigPath, chainID); err != nil {
+				return e, fmt.Errorf("error loading denoms config: %w", err)
+			}
+		}
+		e.Denoms = denoms
+	}
+	if cfg.ResolveTaxes {
+		e.Taxes = api.NewTaxResolver(lcd, lcd.CDC(), cfg.TaxRatesCacheSize)
+	}
+	if cfg.ResolveSequences {
+		e.Sequences = api.NewSequenceResolver(lcd, lcd.CDC(), cfg.SequenceSearchDepth, cfg.SequencesCacheSize)
+	}
+	if cfg.FiatCurrencies != "" {
+		e.Valuer = api.NewValuer(lcd, strings.Split(cfg.FiatCurrencies, ","), cfg.FiatRatesCacheSize)
+	}
+	return e, nil
+}
+
+func loadDenoms(dr *mapper.DenomRegistry, path, chainID string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	return dr.LoadConfig(f, chainID)
+}
@@ -3,10 +3,16 @@ package main
 import (
 	"io"
 
+	"github.com/figment-networks/indexer-manager/structs"
 	"github.com/figment-networks/terra-worker/api"
 	"go.uber.org/zap"
 )
 
+// APIVersion is version of functions exported by the plugin, the manager checks it before calling them.
+// It's increased on every change of their signatures or results.
+// Version 1 exports DecodeFee, DecodeEvents, DecodeSigners and DecodeTransaction
+var APIVersion = 1
+
 var cli *api.Client
 
 func init() {
@@ -29,3 +35,28 @@ func DecodeEvents(logger *zap.Logger, txReader, txLogReader io.Reader) ([]interf
 	}
 	return slice, nil
 }
+
+func DecodeSigners(logger *zap.Logger, reader io.Reader) ([]interface{}, error) {
+	signers, err := cli.GetSignersFromRaw(logger, reader)
+	if err != nil {
+		return nil, err
+	}
+
+	slice := []interface{}{}
+	for _, s := range signers {
+		slice = append(slice, s)
+	}
+	return slice, nil
+}
+
+// DecodeTransaction converts raw transaction and its log into transaction with fee, memo, gas wanted, events and signers.
+// Hash is the hash of raw bytes, block hash, time and chain id are taken from blockMeta. Gas used is not part of raw data.
+// The plugin has no lcd, so unlike the worker with RESOLVE_TAXES it doesn't compute stability tax missing in logs
+// (fee subevent is marked with `tax_unresolved`), nor resolves sequences, values or annotates amounts
+func DecodeTransaction(logger *zap.Logger, txReader, txLogReader io.Reader, height uint64, blockMeta structs.Block) (interface{}, error) {
+	tx, err := cli.GetTransactionFromRaw(logger, txReader, txLogReader, height, blockMeta)
+	if err != nil {
+		return nil, err
+	}
+	return tx, nil
+}
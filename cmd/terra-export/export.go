@@ -0,0 +1,345 @@
+package main
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/figment-networks/indexer-manager/structs"
+	cStructs "github.com/figment-networks/indexer-manager/worker/connectivity/structs"
+	"github.com/figment-networks/terra-worker/api"
+	"github.com/figment-networks/terra-worker/api/mapper"
+	"github.com/figment-networks/terra-worker/client"
+	"github.com/figment-networks/terra-worker/export/parquet"
+	"go.uber.org/zap"
+)
+
+// checkpointFile is the name of the file in output directory holding the export progress
+const checkpointFile = "checkpoint.json"
+
+// Output formats
+const (
+	formatJSONL   = "jsonl"
+	formatParquet = "parquet"
+)
+
+// checkpoint is the progress of export. JSONL files are truncated to the recorded sizes on resume,
+// so data of the chunk interrupted in the middle is written again (parquet files of the chunk are replaced)
+type checkpoint struct {
+	StartHeight uint64           `json:"start_height"`
+	EndHeight   uint64           `json:"end_height"`
+	Format      string           `json:"format,omitempty"`
+	Gzip        bool             `json:"gzip"`
+	NextHeight  uint64           `json:"next_height"`
+	Sizes       map[string]int64 `json:"sizes"`
+}
+
+// exporter writes blocks and transactions of height range into JSONL files of output directory, chunk by chunk.
+// In parquet format every chunk is written as separate file of every table
+type exporter struct {
+	logger *zap.Logger
+	rpc    client.RPC
+	opts   mapper.Options
+	// enrichment is applied to converted transactions, the same stages as the worker applies
+	enrichment client.Enrichment
+	// chainID selects format of node responses (columbus-4 or older)
+	chainID string
+	dir     string
+	format  string
+	gzip    bool
+	chunk   uint64
+}
+
+// exportedTx is converted transaction with its payload after enrichment, which is written into JSONL files
+type exportedTx struct {
+	structs.Transaction
+	payload interface{}
+}
+
+// exportStats are totals of export
+type exportStats struct {
+	blocks       int
+	transactions int
+	failed       int
+}
+
+func (e *exporter) fileNames() (blocks, transactions string) {
+	ext := ".jsonl"
+	if e.gzip {
+		ext += ".gz"
+	}
+	return "blocks" + ext, "transactions" + ext
+}
+
+// run exports heights from start to end (inclusive), continuing from the checkpoint of the same export
+func (e *exporter) run(ctx context.Context, start, end uint64) (st exportStats, err error) {
+	if end < start {
+		return st, fmt.Errorf("end height %d is lower than start height %d", end, start)
+	}
+	if e.chunk == 0 {
+		e.chunk = 1
+	}
+	switch e.format {
+	case "":
+		e.format = formatJSONL
+	case formatJSONL:
+	case formatParquet:
+		if e.gzip {
+			return st, errors.New("gzip is not supported by parquet format, its files are compressed with snappy")
+		}
+	default:
+		return st, fmt.Errorf("unknown format %q", e.format)
+	}
+
+	cp, err := e.resume(start, end)
+	if err != nil {
+		return st, err
+	}
+
+	began := time.Now()
+	for cp.NextHeight <= end {
+		hr := structs.HeightRange{StartHeight: cp.NextHeight, EndHeight: cp.NextHeight + e.chunk - 1, Network: "terra", ChainID: e.chainID}
+		if hr.EndHeight > end || hr.EndHeight < hr.StartHeight {
+			hr.EndHeight = end
+		}
+
+		blocks, txs, failed, err := e.fetch(ctx, hr)
+		if err != nil {
+			return st, fmt.Errorf("error getting heights %d-%d: %w", hr.StartHeight, hr.EndHeight, err)
+		}
+
+		if e.format == formatParquet {
+			flat := make([]structs.Transaction, 0, len(txs))
+			for _, t := range txs {
+				flat = append(flat, t.Transaction)
+			}
+			err = parquet.WriteChunk(e.dir, hr.StartHeight, hr.EndHeight, parquet.Flatten(blocks, flat))
+		} else {
+			err = e.appendChunk(&cp, blocks, txs)
+		}
+		if err != nil {
+			return st, err
+		}
+		cp.NextHeight = hr.EndHeight + 1
+		if err := e.saveCheckpoint(cp); err != nil {
+			return st, err
+		}
+
+		st.blocks += len(blocks)
+		st.transactions += len(txs)
+		st.failed += failed
+		e.logger.Info("Exported heights",
+			zap.Uint64("start", hr.StartHeight),
+			zap.Uint64("end", hr.EndHeight),
+			zap.Int("blocks", len(blocks)),
+			zap.Int("transactions", len(txs)),
+			zap.Float64("percent", 100*float64(hr.EndHeight-start+1)/float64(end-start+1)),
+			zap.Float64("heights_per_second", float64(hr.EndHeight-hr.StartHeight+1)/time.Since(began).Seconds()),
+		)
+		began = time.Now()
+
+		if hr.EndHeight == end {
+			break
+		}
+	}
+	return st, nil
+}
+
+// resume returns checkpoint of the export, truncating files to the sizes recorded in it.
+// Without checkpoint export starts over with empty files
+func (e *exporter) resume(start, end uint64) (cp checkpoint, err error) {
+	blocksFile, txsFile := e.fileNames()
+
+	b, err := ioutil.ReadFile(filepath.Join(e.dir, checkpointFile))
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		cp = checkpoint{StartHeight: start, EndHeight: end, Format: e.format, Gzip: e.gzip, NextHeight: start, Sizes: map[string]int64{}}
+		if e.format == formatJSONL {
+			cp.Sizes[blocksFile], cp.Sizes[txsFile] = 0, 0
+		}
+	case err != nil:
+		return cp, err
+	default:
+		if err := json.Unmarshal(b, &cp); err != nil {
+			return cp, fmt.Errorf("error reading checkpoint: %w", err)
+		}
+		if cp.Format == "" {
+			cp.Format = formatJSONL
+		}
+		if cp.StartHeight != start || cp.EndHeight != end || cp.Format != e.format || cp.Gzip != e.gzip {
+			return cp, fmt.Errorf("checkpoint in %s is of other export (heights %d-%d, format %s, gzip %t), remove it or use other directory", e.dir, cp.StartHeight, cp.EndHeight, cp.Format, cp.Gzip)
+		}
+		e.logger.Info("Resuming export", zap.Uint64("height", cp.NextHeight))
+	}
+
+	if e.format != formatJSONL {
+		return cp, nil
+	}
+	for _, name := range []string{blocksFile, txsFile} {
+		if err := truncate(filepath.Join(e.dir, name), cp.Sizes[name]); err != nil {
+			return cp, err
+		}
+	}
+	return cp, nil
+}
+
+func truncate(path string, size int64) error {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	fi, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	if fi.Size() < size {
+		return fmt.Errorf("%s is shorter (%d bytes) than recorded in checkpoint (%d bytes)", path, fi.Size(), size)
+	}
+	return f.Truncate(size)
+}
+
+func (e *exporter) saveCheckpoint(cp checkpoint) error {
+	b, err := json.Marshal(cp)
+	if err != nil {
+		return err
+	}
+	tmp := filepath.Join(e.dir, checkpointFile+".tmp")
+	if err := ioutil.WriteFile(tmp, b, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, filepath.Join(e.dir, checkpointFile))
+}
+
+// fetch returns blocks and enriched transactions of height range sorted by height (transactions of the same height by hash).
+// Failed is the number of transactions converted with errors
+func (e *exporter) fetch(ctx context.Context, hr structs.HeightRange) (bs []structs.Block, ts []exportedTx, failed int, err error) {
+	out := make(chan cStructs.OutResp, 100)
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+		for o := range e.enrichment.Chain(ctx, e.logger, out) {
+			if p, ok := o.Payload.(structs.Block); ok {
+				bs = append(bs, p)
+				continue
+			}
+			tx, ok := enrichedTransaction(o.Payload)
+			if !ok {
+				continue
+			}
+			if o.Error != nil {
+				failed++
+				e.logger.Error("Problem converting transaction", zap.Error(o.Error), zap.Uint64("height", tx.Height), zap.String("hash", tx.Hash))
+			}
+			ts = append(ts, exportedTx{Transaction: tx, payload: o.Payload})
+		}
+	}()
+
+	err = client.GetRange(ctx, e.logger, e.rpc, e.opts, hr, out)
+	close(out)
+	<-done
+	if err != nil {
+		return nil, nil, 0, err
+	}
+
+	sort.Slice(bs, func(i, j int) bool { return bs[i].Height < bs[j].Height })
+	sort.Slice(ts, func(i, j int) bool {
+		if ts[i].Height != ts[j].Height {
+			return ts[i].Height < ts[j].Height
+		}
+		return ts[i].Hash < ts[j].Hash
+	})
+	return bs, ts, failed, nil
+}
+
+// enrichedTransaction returns transaction of payload passed through enrichment stages
+func enrichedTransaction(payload interface{}) (structs.Transaction, bool) {
+	switch p := payload.(type) {
+	case structs.Transaction:
+		return p, true
+	case api.ValuedTransaction:
+		return p.Transaction, true
+	case client.AnnotatedPayload:
+		return enrichedTransaction(p.Payload)
+	}
+	return structs.Transaction{}, false
+}
+
+// appendChunk appends blocks and transactions to JSONL files, recording their sizes in checkpoint
+func (e *exporter) appendChunk(cp *checkpoint, blocks []structs.Block, txs []exportedTx) (err error) {
+	records := make([]interface{}, 0, len(blocks))
+	for _, b := range blocks {
+		records = append(records, b)
+	}
+	blocksFile, txsFile := e.fileNames()
+	if cp.Sizes[blocksFile], err = e.appendJSONL(blocksFile, records); err != nil {
+		return err
+	}
+
+	records = make([]interface{}, 0, len(txs))
+	for _, t := range txs {
+		records = append(records, t.payload)
+	}
+	cp.Sizes[txsFile], err = e.appendJSONL(txsFile, records)
+	return err
+}
+
+// appendJSONL appends records to the file, as separate gzip member when compressed, and returns the file size
+func (e *exporter) appendJSONL(name string, records []interface{}) (size int64, err error) {
+	f, err := os.OpenFile(filepath.Join(e.dir, name), os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	if len(records) > 0 {
+		if err := writeJSONL(f, records, e.gzip); err != nil {
+			return 0, fmt.Errorf("error writing %s: %w", name, err)
+		}
+		if err := f.Sync(); err != nil {
+			return 0, err
+		}
+	}
+
+	fi, err := f.Stat()
+	if err != nil {
+		return 0, err
+	}
+	return fi.Size(), nil
+}
+
+func writeJSONL(w io.Writer, records []interface{}, compress bool) error {
+	bw := bufio.NewWriter(w)
+	var (
+		dst io.Writer = bw
+		gz  *gzip.Writer
+	)
+	if compress {
+		gz = gzip.NewWriter(bw)
+		dst = gz
+	}
+
+	enc := json.NewEncoder(dst)
+	for _, r := range records {
+		if err := enc.Encode(r); err != nil {
+			return err
+		}
+	}
+	if gz != nil {
+		if err := gz.Close(); err != nil {
+			return err
+		}
+	}
+	return bw.Flush()
+}
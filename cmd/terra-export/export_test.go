@@ -0,0 +1,212 @@
+package main
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/figment-networks/indexer-manager/structs"
+	"github.com/figment-networks/terra-worker/api"
+	"github.com/figment-networks/terra-worker/api/mapper"
+	"github.com/figment-networks/terra-worker/client"
+	"github.com/figment-networks/terra-worker/export/parquet"
+	"github.com/figment-networks/terra-worker/test/fakeserver"
+	"github.com/stretchr/testify/require"
+	"github.com/xitongsys/parquet-go-source/local"
+	"github.com/xitongsys/parquet-go/reader"
+	"go.uber.org/zap/zaptest"
+)
+
+const chainID = "columbus-4"
+
+// failingRPC fails getting blocks from the given height, as if export was interrupted there
+type failingRPC struct {
+	client.RPC
+	failFrom uint64
+}
+
+func (f failingRPC) GetBlocksMeta(ctx context.Context, params structs.HeightRange, limit uint64, blocks *api.BlocksMap, end chan<- error) {
+	if params.EndHeight >= f.failFrom {
+		end <- errors.New("connection reset")
+		return
+	}
+	f.RPC.GetBlocksMeta(ctx, params, limit, blocks, end)
+}
+
+func newServer(t *testing.T) *fakeserver.Server {
+	fixtures, err := filepath.Glob(filepath.Join("..", "..", "api", "testdata", "tx_search", chainID, "*.json"))
+	require.NoError(t, err)
+	require.NotEmpty(t, fixtures)
+
+	s := fakeserver.New(chainID)
+	t.Cleanup(s.Close)
+	require.NoError(t, s.LoadTxSearch(fixtures...))
+	return s
+}
+
+// readJSONL returns lines of (possibly gzipped) JSONL file
+func readJSONL(t *testing.T, path string, compressed bool) (lines []string) {
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	defer f.Close()
+
+	var r io.Reader = f
+	if compressed {
+		gz, err := gzip.NewReader(f)
+		require.NoError(t, err)
+		defer gz.Close()
+		r = gz
+	}
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 16*1024*1024)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	require.NoError(t, scanner.Err())
+	return lines
+}
+
+func TestExporter(t *testing.T) {
+	api.InitMetrics()
+	ctx := context.Background()
+
+	for _, compressed := range []bool{false, true} {
+		t.Run(fmt.Sprintf("gzip %t", compressed), func(t *testing.T) {
+			s := newServer(t)
+			rpc := api.NewClient(s.URL, "", zaptest.NewLogger(t), nil, 100)
+			start, end := uint64(3000001), s.LastHeight()
+
+			full := &exporter{logger: zaptest.NewLogger(t), rpc: rpc, chainID: chainID, dir: t.TempDir(), gzip: compressed, chunk: 10}
+			st, err := full.run(ctx, start, end)
+			require.NoError(t, err)
+			require.Equal(t, int(end-start+1), st.blocks)
+			require.Equal(t, s.NumTxs(start, end), st.transactions)
+
+			blocksFile, txsFile := full.fileNames()
+			blocks := readJSONL(t, filepath.Join(full.dir, blocksFile), compressed)
+			txs := readJSONL(t, filepath.Join(full.dir, txsFile), compressed)
+			require.Len(t, blocks, st.blocks)
+			require.Len(t, txs, st.transactions)
+
+			var prev uint64
+			for _, line := range txs {
+				tx := structs.Transaction{}
+				require.NoError(t, json.Unmarshal([]byte(line), &tx))
+				require.True(t, tx.Height >= prev, "transactions are sorted by height")
+				require.Equal(t, chainID, tx.ChainID)
+				prev = tx.Height
+			}
+
+			// export interrupted in the third chunk, with part of the chunk written
+			resumed := &exporter{logger: zaptest.NewLogger(t), rpc: failingRPC{RPC: rpc, failFrom: start + 20}, chainID: chainID, dir: t.TempDir(), gzip: compressed, chunk: 10}
+			_, err = resumed.run(ctx, start, end)
+			require.Error(t, err)
+			f, err := os.OpenFile(filepath.Join(resumed.dir, txsFile), os.O_WRONLY|os.O_APPEND, 0644)
+			require.NoError(t, err)
+			_, err = f.WriteString("partial")
+			require.NoError(t, err)
+			require.NoError(t, f.Close())
+
+			// other export can't continue from the checkpoint
+			_, err = (&exporter{logger: zaptest.NewLogger(t), rpc: rpc, chainID: chainID, dir: resumed.dir, gzip: compressed, chunk: 10}).run(ctx, start, end-1)
+			require.Error(t, err)
+
+			resumed.rpc = rpc
+			st, err = resumed.run(ctx, start, end)
+			require.NoError(t, err)
+			require.Equal(t, int(end-start-19), st.blocks)
+
+			require.Equal(t, blocks, readJSONL(t, filepath.Join(resumed.dir, blocksFile), compressed))
+			require.Equal(t, txs, readJSONL(t, filepath.Join(resumed.dir, txsFile), compressed))
+
+			// finished export is not repeated
+			st, err = resumed.run(ctx, start, end)
+			require.NoError(t, err)
+			require.Zero(t, st.blocks)
+		})
+	}
+}
+
+// countRows returns number of rows in all files of parquet table
+func countRows(t *testing.T, dir, table string) (n int) {
+	files, err := filepath.Glob(filepath.Join(dir, table, "*.parquet"))
+	require.NoError(t, err)
+	for _, path := range files {
+		fr, err := local.NewLocalFileReader(path)
+		require.NoError(t, err)
+		pr, err := reader.NewParquetReader(fr, nil, 1)
+		require.NoError(t, err)
+		n += int(pr.GetNumRows())
+		pr.ReadStop()
+		require.NoError(t, fr.Close())
+	}
+	return n
+}
+
+func TestExporter_Parquet(t *testing.T) {
+	api.InitMetrics()
+	ctx := context.Background()
+	s := newServer(t)
+	rpc := api.NewClient(s.URL, "", zaptest.NewLogger(t), nil, 100)
+	start, end := uint64(3000001), s.LastHeight()
+
+	_, err := (&exporter{logger: zaptest.NewLogger(t), rpc: rpc, chainID: chainID, dir: t.TempDir(), format: formatParquet, gzip: true}).run(ctx, start, end)
+	require.Error(t, err)
+
+	// export interrupted in the third chunk
+	e := &exporter{logger: zaptest.NewLogger(t), rpc: failingRPC{RPC: rpc, failFrom: start + 20}, chainID: chainID, dir: t.TempDir(), format: formatParquet, chunk: 10}
+	_, err = e.run(ctx, start, end)
+	require.Error(t, err)
+
+	// jsonl export can't continue from the checkpoint
+	_, err = (&exporter{logger: zaptest.NewLogger(t), rpc: rpc, chainID: chainID, dir: e.dir, chunk: 10}).run(ctx, start, end)
+	require.Error(t, err)
+
+	e.rpc = rpc
+	st, err := e.run(ctx, start, end)
+	require.NoError(t, err)
+	require.Equal(t, int(end-start-19), st.blocks)
+
+	for _, table := range parquet.Tables {
+		files, err := filepath.Glob(filepath.Join(e.dir, table, "*.parquet"))
+		require.NoError(t, err)
+		require.Len(t, files, 4, "file of every chunk")
+	}
+	require.Equal(t, int(end-start+1), countRows(t, e.dir, parquet.TableBlocks))
+	require.Equal(t, s.NumTxs(start, end), countRows(t, e.dir, parquet.TableTransactions))
+	require.NotZero(t, countRows(t, e.dir, parquet.TableEvents))
+	require.NotZero(t, countRows(t, e.dir, parquet.TableTransfers))
+	require.NotZero(t, countRows(t, e.dir, parquet.TableBalanceChanges))
+}
+
+func TestExporter_Enrichment(t *testing.T) {
+	api.InitMetrics()
+	ctx := context.Background()
+	s := newServer(t)
+	rpc := api.NewClient(s.URL, "", zaptest.NewLogger(t), nil, 100)
+	start, end := uint64(3000001), s.LastHeight()
+	annotate := client.Enrichment{Denoms: mapper.NewDenomRegistry()}
+
+	e := &exporter{logger: zaptest.NewLogger(t), rpc: rpc, enrichment: annotate, chainID: chainID, dir: t.TempDir(), chunk: 10}
+	st, err := e.run(ctx, start, end)
+	require.NoError(t, err)
+	require.Equal(t, s.NumTxs(start, end), st.transactions)
+
+	_, txsFile := e.fileNames()
+	for _, line := range readJSONL(t, filepath.Join(e.dir, txsFile), false) {
+		require.Contains(t, line, `"display":{`, "transactions are written as the worker sends them")
+	}
+
+	// flat tables are written from transactions
+	pe := &exporter{logger: zaptest.NewLogger(t), rpc: rpc, enrichment: annotate, chainID: chainID, dir: t.TempDir(), format: formatParquet, chunk: 10}
+	_, err = pe.run(ctx, start, end)
+	require.NoError(t, err)
+	require.Equal(t, s.NumTxs(start, end), countRows(t, pe.dir, parquet.TableTransactions))
+}
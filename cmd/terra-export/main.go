@@ -0,0 +1,165 @@
+// Command terra-export writes blocks and transactions of height range into JSONL files, without a manager.
+// With `-format parquet` they're written as flat tables (see export/parquet) instead.
+//
+// Node address, datahub key, requests per second and chunk size (BIG_PAGE) are read from the same config file
+// or environment variables as terra-worker uses, so are WASM_BYTE_CODE and enrichment stages (RESOLVE_TAXES, RESOLVE_SEQUENCES,
+// FIAT_CURRENCIES, ANNOTATE_AMOUNTS, with TERRA_LCD_ADDR), transactions are exported the same as the worker sends them:
+//
+//	TERRA_RPC_ADDR=http://localhost:26657 terra-export -start 3000000 -end 3100000 -out ./export -gzip
+//
+// Interrupted export continues from the last written chunk when run again with the same parameters.
+// With ARCHIVE_MODE=replay responses are read from ARCHIVE_DIR (recorded by the worker or earlier export) instead of the node.
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/figment-networks/terra-worker/api"
+	"github.com/figment-networks/terra-worker/api/mapper"
+	common "github.com/figment-networks/terra-worker/cmd/common/config"
+	"github.com/figment-networks/terra-worker/cmd/common/enrichment"
+)
+
+// Config holds settings shared with terra-worker (the same json keys, environment variables and defaults),
+// lcd address is only needed by enrichment stages
+type Config struct {
+	TerraLCDAddr string `json:"terra_lcd_addr" envconfig:"TERRA_LCD_ADDR"`
+	common.Shared
+}
+
+type flags struct {
+	configPath string
+	start      uint64
+	end        uint64
+	out        string
+	format     string
+	gzip       bool
+}
+
+func main() {
+	os.Exit(run())
+}
+
+// run exports the range and returns exit code: 1 when export failed, 2 when any transaction was converted with errors
+func run() int {
+	f := flags{}
+	flag.StringVar(&f.configPath, "config", "", "Path to config (the same as terra-worker), environment variables are used when empty")
+	flag.Uint64Var(&f.start, "start", 0, "First height to export")
+	flag.Uint64Var(&f.end, "end", 0, "Last height to export")
+	flag.StringVar(&f.out, "out", ".", "Output directory")
+	flag.StringVar(&f.format, "format", formatJSONL, "Output format: jsonl (blocks and transactions) or parquet (flat tables partitioned by chunks of heights)")
+	flag.BoolVar(&f.gzip, "gzip", false, "Compress jsonl output files with gzip")
+	flag.Parse()
+
+	cfg, err := initConfig(f.configPath)
+	if err != nil {
+		log.Fatalf("error initializing config [ERR: %v]", err.Error())
+	}
+	if f.end == 0 {
+		log.Fatal("end height is zero")
+	}
+
+	logger, err := zap.NewDevelopment()
+	if err != nil {
+		log.Fatalf("error initializing logger [ERR: %v]", err)
+	}
+	defer logger.Sync()
+
+	contracts := mapper.NewContractRegistry()
+	if cfg.ContractsConfigPath != "" {
+		cf, err := os.Open(cfg.ContractsConfigPath)
+		if err != nil {
+			logger.Error("Error opening contracts config", zap.Error(err))
+			return 1
+		}
+		err = contracts.LoadConfig(cf, cfg.ChainID)
+		cf.Close()
+		if err != nil {
+			logger.Error("Error loading contracts config", zap.Error(err))
+			return 1
+		}
+	}
+
+	if err := os.MkdirAll(f.out, 0755); err != nil {
+		logger.Error("Error creating output directory", zap.Error(err))
+		return 1
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	wasmByteCode, err := mapper.ParseWasmByteCodeMode(cfg.WasmByteCode)
+	if err != nil {
+		logger.Error("Error reading config", zap.Error(err))
+		return 1
+	}
+	withLCD := cfg.TerraLCDAddr != "" || api.ArchiveMode(cfg.ArchiveMode) == api.ArchiveReplay
+	if cfg.NeedsLCD() && !withLCD {
+		logger.Error("TERRA_LCD_ADDR is required by enabled enrichment stages")
+		return 1
+	}
+
+	api.InitMetrics()
+	rpc := api.NewClient(cfg.TerraRPCAddr, cfg.DatahubKey, logger, nil, int(cfg.RequestsPerSecond))
+	lcd := api.NewClient(cfg.TerraLCDAddr, cfg.DatahubKey, logger, nil, int(cfg.RequestsPerSecond))
+	if cfg.ArchiveMode != "" {
+		archive, err := api.NewArchive(cfg.ArchiveDir)
+		for _, c := range []*api.Client{rpc, lcd} {
+			if err == nil {
+				err = c.SetArchive(archive, api.ArchiveMode(cfg.ArchiveMode))
+			}
+		}
+		if err != nil {
+			logger.Error("Error setting archive", zap.Error(err))
+			return 1
+		}
+	}
+	if withLCD {
+		// without lcd contracts are matched only by address
+		contracts.SetCodeIDSource(api.NewContractCodeIDs(lcd, 20*time.Second, cfg.ContractCodeIDsCacheSize))
+	}
+
+	stages, err := enrichment.New(cfg.Config, cfg.ChainID, lcd)
+	if err != nil {
+		logger.Error("Error setting enrichment", zap.Error(err))
+		return 1
+	}
+
+	e := &exporter{
+		logger:     logger,
+		rpc:        rpc,
+		opts:       mapper.Options{EmbedWasmByteCode: wasmByteCode == mapper.WasmByteCodeEmbed, Contracts: contracts},
+		enrichment: stages,
+		chainID:    cfg.ChainID,
+		dir:        f.out,
+		format:     f.format,
+		gzip:       f.gzip,
+		chunk:      uint64(cfg.BigPage),
+	}
+	st, err := e.run(ctx, f.start, f.end)
+	logger.Info("Export finished", zap.Int("blocks", st.blocks), zap.Int("transactions", st.transactions), zap.Int("failed", st.failed))
+	if err != nil {
+		logger.Error("Error exporting", zap.Error(err))
+		return 1
+	}
+	if st.failed > 0 {
+		return 2
+	}
+	return 0
+}
+
+func initConfig(path string) (*Config, error) {
+	cfg := &Config{}
+	if err := common.Load(path, cfg); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
@@ -0,0 +1,142 @@
+// Command terra-reprocess converts stored raw transactions again with the current mappers, without fetching them from nodes.
+//
+// It reads JSONL of records with `raw`, `raw_log`, `height` and `block` (transactions stored by the manager are accepted as well)
+// and writes JSONL of converted transactions:
+//
+//	terra-reprocess -in transactions.jsonl -out reprocessed.jsonl -workers 8 -diff
+//
+// With `-config` (the worker config file) converted transactions go through the enrichment stages enabled in it
+// (RESOLVE_TAXES, RESOLVE_SEQUENCES, FIAT_CURRENCIES, ANNOTATE_AMOUNTS) before they're written and compared, like in the worker.
+// The stages query TERRA_LCD_ADDR, or the archive with ARCHIVE_MODE=replay
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"runtime"
+	"syscall"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/figment-networks/terra-worker/api"
+	"github.com/figment-networks/terra-worker/api/mapper"
+	"github.com/figment-networks/terra-worker/client"
+	common "github.com/figment-networks/terra-worker/cmd/common/config"
+	"github.com/figment-networks/terra-worker/cmd/common/enrichment"
+	"github.com/figment-networks/terra-worker/cmd/terra-worker/config"
+)
+
+type flags struct {
+	in            string
+	out           string
+	workers       int
+	diff          bool
+	progress      time.Duration
+	contractsPath string
+	chainID       string
+	configPath    string
+}
+
+func main() {
+	os.Exit(run())
+}
+
+// run reprocesses records and returns exit code: 1 when reading or writing failed, 2 when any record could not be converted
+func run() int {
+	f := flags{}
+	flag.StringVar(&f.in, "in", "-", "Input JSONL file of records, - for stdin")
+	flag.StringVar(&f.out, "out", "-", "Output JSONL file of transactions, - for stdout")
+	flag.IntVar(&f.workers, "workers", runtime.NumCPU(), "Number of parallel conversions")
+	flag.BoolVar(&f.diff, "diff", false, "Write only transactions with events different from the record events")
+	flag.DurationVar(&f.progress, "progress", 10*time.Second, "Progress reporting interval, 0 disables it")
+	flag.StringVar(&f.contractsPath, "contracts", "", "Path to contracts config (the same as worker CONTRACTS_CONFIG)")
+	flag.StringVar(&f.chainID, "chain-id", "columbus-4", "Chain id of contracts config")
+	flag.StringVar(&f.configPath, "config", "", "Path to worker config, its enrichment stages are applied to converted transactions")
+	flag.Parse()
+
+	logger, err := zap.NewDevelopment()
+	if err != nil {
+		log.Fatalf("error initializing logger [ERR: %v]", err)
+	}
+	defer logger.Sync()
+
+	// records are converted offline, so contracts are matched only by address
+	contracts := mapper.NewContractRegistry()
+	if f.contractsPath != "" {
+		cf, err := os.Open(f.contractsPath)
+		if err != nil {
+			logger.Fatal("Error opening contracts config", zap.Error(err))
+		}
+		err = contracts.LoadConfig(cf, f.chainID)
+		cf.Close()
+		if err != nil {
+			logger.Fatal("Error loading contracts config", zap.Error(err))
+		}
+	}
+
+	in := os.Stdin
+	if f.in != "-" {
+		if in, err = os.Open(f.in); err != nil {
+			logger.Fatal("Error opening input", zap.Error(err))
+		}
+		defer in.Close()
+	}
+	out := os.Stdout
+	if f.out != "-" {
+		if out, err = os.Create(f.out); err != nil {
+			logger.Fatal("Error creating output", zap.Error(err))
+		}
+		defer out.Close()
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	api.InitMetrics()
+	cli := api.NewClient("", "", logger, nil, 0)
+	cli.SetMapperOptions(mapper.Options{Contracts: contracts})
+
+	stages := client.Enrichment{}
+	if f.configPath != "" {
+		if stages, err = loadEnrichment(logger, f.configPath); err != nil {
+			logger.Fatal("Error setting enrichment", zap.Error(err))
+		}
+	}
+
+	start := time.Now()
+	st, err := reprocess(ctx, logger, cli, in, out, options{workers: f.workers, diff: f.diff, progress: f.progress, enrichment: stages})
+	logProgress(logger, "Reprocessing finished", st, time.Since(start))
+	if err != nil {
+		logger.Error("Error reprocessing", zap.Error(err))
+		return 1
+	}
+	if st.failed > 0 {
+		return 2
+	}
+	return 0
+}
+
+// loadEnrichment builds enrichment stages enabled in the worker config at path, with lcd client set the same way as in the worker
+func loadEnrichment(logger *zap.Logger, path string) (client.Enrichment, error) {
+	cfg := &config.Config{}
+	if err := common.FromFile(path, cfg); err != nil {
+		return client.Enrichment{}, err
+	}
+
+	lcd := api.NewClient(cfg.TerraLCDAddr, cfg.DatahubKey, logger, nil, int(cfg.RequestsPerSecond))
+	if cfg.ArchiveMode != "" {
+		archive, err := api.NewArchive(cfg.ArchiveDir)
+		if err == nil {
+			err = lcd.SetArchive(archive, api.ArchiveMode(cfg.ArchiveMode))
+		}
+		if err != nil {
+			return client.Enrichment{}, fmt.Errorf("error setting archive: %w", err)
+		}
+	}
+	return enrichment.New(cfg.Config, cfg.ChainID, lcd)
+}
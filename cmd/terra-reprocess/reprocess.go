@@ -0,0 +1,268 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/figment-networks/indexer-manager/structs"
+	cStruct "github.com/figment-networks/indexer-manager/worker/connectivity/structs"
+	"github.com/figment-networks/terra-worker/api"
+	"github.com/figment-networks/terra-worker/client"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// maxRecordSize is the longest accepted input line
+const maxRecordSize = 64 * 1024 * 1024
+
+// record is a stored transaction to reprocess. Raw and raw log are encoded the same way as in structs.Transaction,
+// so transactions stored by the manager are accepted as they are. Block metadata is taken from `block`,
+// or from `block_hash`, `chain_id` and `time` of the transaction when it's missing.
+// Id, hash and gas used are copied into the output, events are the previous ones compared in diff mode
+type record struct {
+	ID      uuid.UUID                 `json:"id,omitempty"`
+	Hash    string                    `json:"hash,omitempty"`
+	Raw     []byte                    `json:"raw"`
+	RawLog  []byte                    `json:"raw_log"`
+	Height  uint64                    `json:"height"`
+	GasUsed uint64                    `json:"gas_used,omitempty"`
+	Block   *structs.Block            `json:"block,omitempty"`
+	Events  structs.TransactionEvents `json:"events,omitempty"`
+
+	BlockHash string    `json:"block_hash,omitempty"`
+	ChainID   string    `json:"chain_id,omitempty"`
+	Time      time.Time `json:"time,omitempty"`
+}
+
+// options of reprocessing
+type options struct {
+	workers  int
+	diff     bool
+	progress time.Duration
+	// enrichment is applied to converted transactions, the same stages as the worker applies
+	enrichment client.Enrichment
+}
+
+// stats of reprocessing, updated atomically while running
+type stats struct {
+	read      uint64
+	processed uint64
+	written   uint64
+	changed   uint64
+	failed    uint64
+}
+
+// job is a record with its position in the input: seq counts records, line counts lines (including empty ones)
+type job struct {
+	seq  uint64
+	line uint64
+	data []byte
+}
+
+type result struct {
+	seq     uint64
+	line    uint64
+	data    []byte
+	changed bool
+	err     error
+}
+
+// reprocess converts JSONL records from in into transactions written as JSONL to out, in the input order.
+// Records are converted by the same conversion and enrichment as the worker uses in parallel workers.
+// In diff mode only transactions with events different from the record events are written.
+// Records that can't be converted are logged and skipped, an error is returned only when reading or writing fails
+func reprocess(ctx context.Context, logger *zap.Logger, cli *api.Client, in io.Reader, out io.Writer, opts options) (*stats, error) {
+	if opts.workers < 1 {
+		opts.workers = 1
+	}
+	st := &stats{}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	jobs := make(chan job, opts.workers*2)
+	results := make(chan result, opts.workers*2)
+
+	readErr := make(chan error, 1)
+	go func() {
+		defer close(jobs)
+		scanner := bufio.NewScanner(in)
+		scanner.Buffer(make([]byte, 0, 64*1024), maxRecordSize)
+		var seq, line uint64
+		for scanner.Scan() {
+			line++
+			if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+				continue
+			}
+			atomic.AddUint64(&st.read, 1)
+			select {
+			case jobs <- job{seq: seq, line: line, data: append([]byte(nil), scanner.Bytes()...)}:
+			case <-ctx.Done():
+				readErr <- ctx.Err()
+				return
+			}
+			seq++
+		}
+		readErr <- scanner.Err()
+	}()
+
+	wg := &sync.WaitGroup{}
+	for i := 0; i < opts.workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range jobs {
+				r := convertRecord(ctx, logger, cli, opts.enrichment, j.data)
+				r.seq, r.line = j.seq, j.line
+				select {
+				case results <- r:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	if opts.progress > 0 {
+		go reportProgress(ctx, logger, st, opts.progress)
+	}
+
+	w := bufio.NewWriter(out)
+	if err := writeOrdered(logger, w, results, st, opts.diff); err != nil {
+		return st, err
+	}
+	if err := <-readErr; err != nil {
+		return st, fmt.Errorf("error reading records: %w", err)
+	}
+	return st, w.Flush()
+}
+
+// writeOrdered writes results in the input order, results converted ahead are kept until their turn
+func writeOrdered(logger *zap.Logger, w io.Writer, results <-chan result, st *stats, diff bool) (err error) {
+	pending := map[uint64]result{}
+	var next uint64
+	for r := range results {
+		pending[r.seq] = r
+		for {
+			r, ok := pending[next]
+			if !ok {
+				break
+			}
+			delete(pending, next)
+			next++
+			atomic.AddUint64(&st.processed, 1)
+
+			if r.err != nil {
+				atomic.AddUint64(&st.failed, 1)
+				logger.Error("Problem reprocessing record", zap.Uint64("line", r.line), zap.Error(r.err))
+				continue
+			}
+			if r.changed {
+				atomic.AddUint64(&st.changed, 1)
+			}
+			if (diff && !r.changed) || err != nil {
+				continue
+			}
+			if _, err = w.Write(append(r.data, '\n')); err == nil {
+				atomic.AddUint64(&st.written, 1)
+			}
+		}
+	}
+	return err
+}
+
+// convertRecord converts and enriches record into transaction json, changed is set when its events differ from the record events
+func convertRecord(ctx context.Context, logger *zap.Logger, cli *api.Client, enrichment client.Enrichment, data []byte) (r result) {
+	rec := record{}
+	if r.err = json.Unmarshal(data, &rec); r.err != nil {
+		return r
+	}
+	if len(rec.Raw) == 0 {
+		r.err = fmt.Errorf("record has no raw transaction")
+		return r
+	}
+
+	block := structs.Block{Hash: rec.BlockHash, Height: rec.Height, Time: rec.Time, ChainID: rec.ChainID}
+	if rec.Block != nil {
+		block = *rec.Block
+	}
+
+	tx, err := cli.GetTransactionFromRaw(logger, bytes.NewReader(rec.Raw), bytes.NewReader(rec.RawLog), rec.Height, block)
+	if err != nil {
+		r.err = err
+		return r
+	}
+	tx.ID = rec.ID
+	tx.GasUsed = rec.GasUsed
+	if rec.Hash != "" {
+		tx.Hash = rec.Hash
+	}
+
+	resp, err := enrichment.Apply(ctx, logger, cStruct.OutResp{Type: "Transaction", Payload: tx})
+	if err != nil {
+		r.err = err
+		return r
+	}
+	if r.data, r.err = json.Marshal(resp.Payload); r.err != nil {
+		return r
+	}
+
+	// enriched payload isn't always structs.Transaction, its events are read back from json
+	enriched := structs.Transaction{}
+	if r.err = json.Unmarshal(r.data, &enriched); r.err != nil {
+		return r
+	}
+	r.changed, r.err = eventsChanged(rec.Events, enriched.Events)
+	return r
+}
+
+// eventsChanged compares events by their json, so the ones decoded from the record compare equal to the converted ones
+func eventsChanged(previous, current structs.TransactionEvents) (bool, error) {
+	p, err := json.Marshal(previous)
+	if err != nil {
+		return false, err
+	}
+	c, err := json.Marshal(current)
+	if err != nil {
+		return false, err
+	}
+	return !bytes.Equal(p, c), nil
+}
+
+// reportProgress logs number of processed records and the rate every interval until ctx is done
+func reportProgress(ctx context.Context, logger *zap.Logger, st *stats, interval time.Duration) {
+	start := time.Now()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			logProgress(logger, "Reprocessing", st, time.Since(start))
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func logProgress(logger *zap.Logger, msg string, st *stats, elapsed time.Duration) {
+	processed := atomic.LoadUint64(&st.processed)
+	logger.Info(msg,
+		zap.Uint64("read", atomic.LoadUint64(&st.read)),
+		zap.Uint64("processed", processed),
+		zap.Uint64("written", atomic.LoadUint64(&st.written)),
+		zap.Uint64("changed", atomic.LoadUint64(&st.changed)),
+		zap.Uint64("failed", atomic.LoadUint64(&st.failed)),
+		zap.Float64("records_per_second", float64(processed)/elapsed.Seconds()),
+	)
+}
@@ -0,0 +1,184 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/figment-networks/indexer-manager/structs"
+	cStruct "github.com/figment-networks/indexer-manager/worker/connectivity/structs"
+	"github.com/figment-networks/terra-worker/api"
+	"github.com/figment-networks/terra-worker/api/mapper"
+	"github.com/figment-networks/terra-worker/api/types"
+	"github.com/figment-networks/terra-worker/client"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/terra-project/core/x/auth"
+	"github.com/terra-project/core/x/bank"
+)
+
+// storedTransactions converts tx_search fixture the way worker does, so they are the transactions stored by the manager
+func storedTransactions(t *testing.T, cli *api.Client, fixture string) []structs.Transaction {
+	b, err := ioutil.ReadFile(filepath.Join("..", "..", "api", "testdata", "tx_search", "columbus-4", fixture))
+	require.NoError(t, err)
+	result := &types.GetTxSearchResponse{}
+	require.NoError(t, json.Unmarshal(b, result))
+
+	blocks := map[uint64]structs.Block{}
+	for _, tx := range result.Result.Txs {
+		var h uint64
+		require.NoError(t, json.Unmarshal([]byte(tx.Height), &h))
+		blocks[h] = structs.Block{Hash: "BLOCK" + tx.Height, Height: h, Time: time.Unix(int64(h), 0).UTC(), ChainID: "columbus-4"}
+	}
+
+	out := make(chan cStruct.OutResp, len(result.Result.Txs))
+	require.NoError(t, api.RawToTransaction(zaptest.NewLogger(t), cli.CDC(), mapper.Options{}, result.Result.Txs, blocks, out))
+	close(out)
+
+	var txs []structs.Transaction
+	for o := range out {
+		txs = append(txs, o.Payload.(structs.Transaction))
+	}
+	return txs
+}
+
+func TestReprocess(t *testing.T) {
+	api.InitMetrics()
+	cli := api.NewClient("", "", nil, nil, 0)
+	stored := storedTransactions(t, cli, "bank.json")
+	require.True(t, len(stored) > 2)
+
+	input := &bytes.Buffer{}
+	for i, tx := range stored {
+		if i == 1 {
+			// transaction converted by an older mapper
+			tx.Events = tx.Events[:1]
+		}
+		b, err := json.Marshal(tx)
+		require.NoError(t, err)
+		input.Write(append(b, '\n'))
+		if i == 0 {
+			input.WriteString("\n{\"raw\": \"invalid\"}\n")
+		}
+	}
+	// record with block metadata instead of transaction fields
+	b, err := json.Marshal(record{Raw: stored[0].Raw, RawLog: stored[0].RawLog, Height: stored[0].Height, GasUsed: stored[0].GasUsed, Hash: stored[0].Hash, Events: stored[0].Events,
+		Block: &structs.Block{Hash: stored[0].BlockHash, Height: stored[0].Height, Time: stored[0].Time, ChainID: stored[0].ChainID}})
+	require.NoError(t, err)
+	input.Write(append(b, '\n'))
+
+	tests := []struct {
+		name        string
+		diff        bool
+		wantHashes  []string
+		wantChanged uint64
+	}{
+		{
+			name:        "all records",
+			wantHashes:  append(hashes(stored), stored[0].Hash),
+			wantChanged: 1,
+		},
+		{
+			name:        "diff",
+			diff:        true,
+			wantHashes:  []string{stored[1].Hash},
+			wantChanged: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := &bytes.Buffer{}
+			st, err := reprocess(context.Background(), zaptest.NewLogger(t), cli, strings.NewReader(input.String()), out, options{workers: 4, diff: tt.diff})
+			require.NoError(t, err)
+			require.Equal(t, uint64(len(stored)+2), st.read)
+			require.Equal(t, uint64(len(stored)+2), st.processed)
+			require.Equal(t, uint64(1), st.failed)
+			require.Equal(t, tt.wantChanged, st.changed)
+			require.Equal(t, uint64(len(tt.wantHashes)), st.written)
+
+			var got []structs.Transaction
+			scanner := bufio.NewScanner(out)
+			for scanner.Scan() {
+				tx := structs.Transaction{}
+				require.NoError(t, json.Unmarshal(scanner.Bytes(), &tx))
+				got = append(got, tx)
+			}
+			require.Equal(t, tt.wantHashes, hashes(got))
+
+			// reprocessed transactions are the same as stored, apart from the outdated events
+			for _, tx := range got {
+				for _, s := range stored {
+					if s.Hash == tx.Hash {
+						require.Equal(t, s, tx)
+					}
+				}
+			}
+		})
+	}
+}
+
+type taxRates api.TaxRates
+
+func (r taxRates) GetTaxRates(ctx context.Context, height uint64) (api.TaxRates, error) {
+	return api.TaxRates(r), nil
+}
+
+func TestReprocess_Enrichment(t *testing.T) {
+	api.InitMetrics()
+	cli := api.NewClient("", "", nil, nil, 0)
+	logger := zaptest.NewLogger(t)
+	taxes := client.Enrichment{Taxes: api.NewTaxResolver(taxRates{Rate: sdk.NewDecWithPrec(5, 3), Caps: map[string]sdk.Int{}}, cli.CDC(), 10)}
+
+	// columbus-4 send, whose log doesn't report tax
+	from, to := sdk.AccAddress("from________________"), sdk.AccAddress("to__________________")
+	amount, _ := sdk.ParseCoins("1000000uusd")
+	fee, _ := sdk.ParseCoins("8000uusd")
+	raw, err := cli.CDC().MarshalBinaryLengthPrefixed(auth.StdTx{Msgs: []sdk.Msg{bank.NewMsgSend(from, to, amount)}, Fee: auth.NewStdFee(200000, fee)})
+	require.NoError(t, err)
+	rec := record{Raw: []byte(base64.StdEncoding.EncodeToString(raw)), Height: 3000001, Block: &structs.Block{Height: 3000001, ChainID: "columbus-4"}}
+	tx, err := cli.GetTransactionFromRaw(logger, bytes.NewReader(rec.Raw), bytes.NewReader(rec.RawLog), rec.Height, *rec.Block)
+	require.NoError(t, err)
+
+	// stored by the worker with taxes resolved
+	resp, err := taxes.Apply(context.Background(), logger, cStruct.OutResp{Type: "Transaction", Payload: tx})
+	require.NoError(t, err)
+	rec.Events = resp.Payload.(structs.Transaction).Events
+	input, err := json.Marshal(rec)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name        string
+		enrichment  client.Enrichment
+		wantChanged bool
+	}{
+		{name: "the same stages as worker", enrichment: taxes},
+		{name: "without stages", wantChanged: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := &bytes.Buffer{}
+			st, err := reprocess(context.Background(), logger, cli, bytes.NewReader(input), out, options{workers: 4, diff: true, enrichment: tt.enrichment})
+			require.NoError(t, err)
+			require.Equal(t, uint64(0), st.failed)
+			require.Equal(t, tt.wantChanged, st.changed == 1)
+			require.Equal(t, tt.wantChanged, out.Len() > 0)
+		})
+	}
+}
+
+func hashes(txs []structs.Transaction) (h []string) {
+	for _, tx := range txs {
+		h = append(h, tx.Hash)
+	}
+	return h
+}
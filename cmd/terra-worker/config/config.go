@@ -1,11 +1,9 @@
 package config
 
 import (
-	"encoding/json"
-	"io/ioutil"
 	"time"
 
-	"github.com/kelseyhightower/envconfig"
+	common "github.com/figment-networks/terra-worker/cmd/common/config"
 )
 
 var (
@@ -32,14 +30,11 @@ type Config struct {
 	ManagerInterval time.Duration `json:"manager_interval" envconfig:"MANAGER_INTERVAL" default:"60s"`
 	Hostname        string        `json:"hostname" envconfig:"HOSTNAME"`
 
-	TerraRPCAddr string `json:"terra_rpc_addr" envconfig:"TERRA_RPC_ADDR" required:"true"`
 	TerraLCDAddr string `json:"terra_lcd_addr" envconfig:"TERRA_LCD_ADDR" required:"true"`
-	DatahubKey   string `json:"datahub_key" envconfig:"DATAHUB_KEY"`
-	ChainID      string `json:"chain_id" envconfig:"CHAIN_ID"`
+	// Shared holds node access and conversion settings read the same way by terra-export and terra-reprocess
+	common.Shared
 
 	MaximumHeightsToGet float64 `json:"maximum_heights_to_get" envconfig:"MAXIMUM_HEIGHTS_TO_GET" default:"10000"`
-	BigPage             float64 `json:"big_page" envconfig:"BIG_PAGE" default:"1000"`
-	RequestsPerSecond   int64   `json:"requests_per_second" envconfig:"REQUESTS_PER_SECOND" default:"33"`
 
 	HealthCheckInterval time.Duration `json:"health_check_interval" envconfig:"HEALTH_CHECK_INTERVAL" default:"10s"`
 
@@ -47,17 +42,3 @@ type Config struct {
 	RollbarAccessToken string `json:"rollbar_access_token" envconfig:"ROLLBAR_ACCESS_TOKEN"`
 	RollbarServerRoot  string `json:"rollbar_server_root" envconfig:"ROLLBAR_SERVER_ROOT" default:"github.com/figment-networks/terra-worker"`
 }
-
-// FromFile reads the config from a file
-func FromFile(path string, config *Config) error {
-	data, err := ioutil.ReadFile(path)
-	if err != nil {
-		return err
-	}
-	return json.Unmarshal(data, config)
-}
-
-// FromEnv reads the config from environment variables
-func FromEnv(config *Config) error {
-	return envconfig.Process("", config)
-}
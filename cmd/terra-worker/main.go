@@ -18,7 +18,10 @@ import (
 	grpc "google.golang.org/grpc"
 
 	"github.com/figment-networks/terra-worker/api"
+	"github.com/figment-networks/terra-worker/api/mapper"
 	"github.com/figment-networks/terra-worker/client"
+	common "github.com/figment-networks/terra-worker/cmd/common/config"
+	"github.com/figment-networks/terra-worker/cmd/common/enrichment"
 	"github.com/figment-networks/terra-worker/cmd/common/logger"
 	"github.com/figment-networks/terra-worker/cmd/terra-worker/config"
 
@@ -52,6 +55,10 @@ func main() {
 	if err != nil {
 		log.Fatalf("error initializing config [ERR: %v]", err.Error())
 	}
+	wasmByteCode, err := mapper.ParseWasmByteCodeMode(cfg.WasmByteCode)
+	if err != nil {
+		log.Fatalf("error initializing config [ERR: %v]", err.Error())
+	}
 
 	if cfg.RollbarServerRoot == "" {
 		cfg.RollbarServerRoot = "github.com/figment-networks/terra-worker"
@@ -109,10 +116,36 @@ func main() {
 
 	grpcServer := grpc.NewServer()
 
+	contracts := mapper.NewContractRegistry()
+	if cfg.ContractsConfigPath != "" {
+		if err := loadContracts(contracts, cfg.ContractsConfigPath, cfg.ChainID); err != nil {
+			logger.Error(fmt.Errorf("error loading contracts config: %w", err))
+			return
+		}
+	}
+
 	rpcClient := api.NewClient(cfg.TerraRPCAddr, cfg.DatahubKey, logger.GetLogger(), nil, int(cfg.RequestsPerSecond))
 	lcdClient := api.NewClient(cfg.TerraLCDAddr, cfg.DatahubKey, logger.GetLogger(), nil, int(cfg.RequestsPerSecond))
+	if cfg.ArchiveMode != "" {
+		if err := setArchive(cfg.ArchiveDir, api.ArchiveMode(cfg.ArchiveMode), rpcClient, lcdClient); err != nil {
+			logger.Error(fmt.Errorf("error setting archive: %w", err))
+			return
+		}
+	}
+	contracts.SetCodeIDSource(api.NewContractCodeIDs(lcdClient, 20*time.Second, cfg.ContractCodeIDsCacheSize))
+
 	workerClient := client.NewIndexerClient(ctx, logger.GetLogger(), lcdClient, rpcClient, uint64(cfg.BigPage), uint64(cfg.MaximumHeightsToGet))
 
+	workerClient.SetWasmByteCode(wasmByteCode)
+	workerClient.SetContractRegistry(contracts)
+	rpcClient.SetMapperOptions(mapper.Options{EmbedWasmByteCode: wasmByteCode == mapper.WasmByteCodeEmbed, Contracts: contracts})
+	stages, err := enrichment.New(cfg.Config, cfg.ChainID, lcdClient)
+	if err != nil {
+		logger.Error(err)
+		return
+	}
+	workerClient.SetEnrichment(stages)
+
 	worker := grpcIndexer.NewIndexerServer(ctx, workerClient, logger.GetLogger())
 	grpcProtoIndexer.RegisterIndexerServiceServer(grpcServer, worker)
 
@@ -165,21 +198,34 @@ RunLoop:
 
 func initConfig(path string) (*config.Config, error) {
 	cfg := &config.Config{}
-	if path != "" {
-		if err := config.FromFile(path, cfg); err != nil {
-			return nil, err
-		}
+	if err := common.Load(path, cfg); err != nil {
+		return nil, err
 	}
+	return cfg, nil
+}
 
-	if cfg.TerraRPCAddr != "" {
-		return cfg, nil
+func loadContracts(cr *mapper.ContractRegistry, path, chainID string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
 	}
+	defer f.Close()
 
-	if err := config.FromEnv(cfg); err != nil {
-		return nil, err
-	}
+	return cr.LoadConfig(f, chainID)
+}
 
-	return cfg, nil
+// setArchive makes clients record responses into archive in dir or replay them from it
+func setArchive(dir string, mode api.ArchiveMode, clients ...*api.Client) error {
+	archive, err := api.NewArchive(dir)
+	if err != nil {
+		return err
+	}
+	for _, c := range clients {
+		if err := c.SetArchive(archive, mode); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 func runGRPC(grpcServer *grpc.Server, port string, logger *zap.Logger, exit chan<- string) {
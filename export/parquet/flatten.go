@@ -0,0 +1,201 @@
+package parquet
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/figment-networks/indexer-manager/structs"
+	"github.com/figment-networks/terra-worker/api"
+)
+
+// Rows are rows of all tables
+type Rows struct {
+	Blocks         []Block
+	Transactions   []Transaction
+	Events         []Event
+	Transfers      []Transfer
+	BalanceChanges []BalanceChange
+}
+
+// Flatten converts blocks and transactions into table rows, in the given order
+func Flatten(blocks []structs.Block, txs []structs.Transaction) (r Rows) {
+	for _, b := range blocks {
+		r.Blocks = append(r.Blocks, Block{
+			Height:               int64(b.Height),
+			Hash:                 b.Hash,
+			Time:                 millis(b.Time),
+			ChainID:              b.ChainID,
+			Epoch:                b.Epoch,
+			NumberOfTransactions: int64(b.NumberOfTransactions),
+		})
+	}
+	for _, tx := range txs {
+		r.flattenTransaction(tx)
+	}
+	return r
+}
+
+func (r *Rows) flattenTransaction(tx structs.Transaction) {
+	txTime := millis(tx.Time)
+	row := Transaction{
+		Hash:       tx.Hash,
+		BlockHash:  tx.BlockHash,
+		Height:     int64(tx.Height),
+		Time:       txTime,
+		ChainID:    tx.ChainID,
+		Epoch:      tx.Epoch,
+		GasWanted:  int64(tx.GasWanted),
+		GasUsed:    int64(tx.GasUsed),
+		Memo:       tx.Memo,
+		EventCount: int32(len(tx.Events)),
+	}
+
+	for _, fee := range tx.Fee {
+		r.Transfers = append(r.Transfers, transferRow(tx, txTime, nil, nil, SourceFee, nil, "", fee))
+	}
+
+	types := map[string]bool{}
+	for i, ev := range tx.Events {
+		eventIndex := int32(i)
+		var subIndex int32
+		var walk func(sub structs.SubsetEvent, parent *int32)
+		walk = func(sub structs.SubsetEvent, parent *int32) {
+			index := subIndex
+			subIndex++
+			for _, t := range sub.Type {
+				types[t] = true
+			}
+			if sub.Error != nil {
+				row.HasErrors = true
+			}
+			r.Events = append(r.Events, eventRow(tx, txTime, eventIndex, ev, index, parent, sub))
+			r.subTransfers(tx, txTime, eventIndex, index, sub)
+			for _, s := range sub.Sub {
+				walk(s, &index)
+			}
+		}
+		for _, sub := range ev.Sub {
+			walk(sub, nil)
+		}
+	}
+	row.Types = joinSorted(types)
+	r.Transactions = append(r.Transactions, row)
+
+	for i, c := range api.BalanceChanges(tx) {
+		r.BalanceChanges = append(r.BalanceChanges, BalanceChange{
+			TxHash:   tx.Hash,
+			Height:   int64(tx.Height),
+			Time:     txTime,
+			Index:    int32(i),
+			Account:  c.Account,
+			Reason:   c.Reason,
+			Currency: c.Amount.Currency,
+			Text:     c.Amount.Text,
+			Numeric:  numeric(c.Amount),
+			Exp:      c.Amount.Exp,
+		})
+	}
+}
+
+func eventRow(tx structs.Transaction, txTime int64, eventIndex int32, ev structs.TransactionEvent, index int32, parent *int32, sub structs.SubsetEvent) Event {
+	e := Event{
+		TxHash:         tx.Hash,
+		Height:         int64(tx.Height),
+		Time:           txTime,
+		EventIndex:     eventIndex,
+		EventKind:      ev.Kind,
+		EventModule:    ev.Module,
+		SubIndex:       index,
+		ParentSubIndex: parent,
+		Type:           strings.Join(sub.Type, ","),
+		Action:         sub.Action,
+		Module:         sub.Module,
+		Nonce:          sub.Nonce,
+		Node:           jsonColumn(sub.Node),
+		Amount:         jsonColumn(sub.Amount),
+		Additional:     jsonColumn(sub.Additional),
+	}
+	if sub.Completion != nil {
+		c := millis(*sub.Completion)
+		e.Completion = &c
+	}
+	if sub.Error != nil {
+		msg := sub.Error.Message
+		e.Error = &msg
+	}
+	return e
+}
+
+// subTransfers adds amounts of sender, recipient and transfers of subevent (transfers sorted by kind)
+func (r *Rows) subTransfers(tx structs.Transaction, txTime int64, eventIndex, subIndex int32, sub structs.SubsetEvent) {
+	add := func(source string, kind *string, transfers []structs.EventTransfer) {
+		for _, t := range transfers {
+			for _, am := range t.Amounts {
+				r.Transfers = append(r.Transfers, transferRow(tx, txTime, &eventIndex, &subIndex, source, kind, t.Account.ID, am))
+			}
+		}
+	}
+	add(SourceSender, nil, sub.Sender)
+	add(SourceRecipient, nil, sub.Recipient)
+
+	kinds := make([]string, 0, len(sub.Transfers))
+	for k := range sub.Transfers {
+		kinds = append(kinds, k)
+	}
+	sort.Strings(kinds)
+	for _, k := range kinds {
+		kind := k
+		add(SourceTransfers, &kind, sub.Transfers[k])
+	}
+}
+
+func transferRow(tx structs.Transaction, txTime int64, eventIndex, subIndex *int32, source string, kind *string, account string, am structs.TransactionAmount) Transfer {
+	return Transfer{
+		TxHash:     tx.Hash,
+		Height:     int64(tx.Height),
+		Time:       txTime,
+		EventIndex: eventIndex,
+		SubIndex:   subIndex,
+		Source:     source,
+		Kind:       kind,
+		Account:    account,
+		Currency:   am.Currency,
+		Text:       am.Text,
+		Numeric:    numeric(am),
+		Exp:        am.Exp,
+	}
+}
+
+func numeric(am structs.TransactionAmount) string {
+	if am.Numeric == nil {
+		return ""
+	}
+	return am.Numeric.String()
+}
+
+// jsonColumn encodes map as json, empty maps are null. Keys of maps are sorted by encoding/json
+func jsonColumn(v interface{}) string {
+	b, err := json.Marshal(v)
+	if err != nil || string(b) == "{}" {
+		return "null"
+	}
+	return string(b)
+}
+
+func joinSorted(set map[string]bool) string {
+	s := make([]string, 0, len(set))
+	for k := range set {
+		s = append(s, k)
+	}
+	sort.Strings(s)
+	return strings.Join(s, ",")
+}
+
+func millis(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.UnixNano() / int64(time.Millisecond)
+}
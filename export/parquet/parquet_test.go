@@ -0,0 +1,150 @@
+package parquet
+
+import (
+	"math/big"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/figment-networks/indexer-manager/structs"
+	"github.com/figment-networks/terra-worker/api"
+	"github.com/stretchr/testify/require"
+	"github.com/xitongsys/parquet-go-source/local"
+	"github.com/xitongsys/parquet-go/reader"
+)
+
+func amount(currency, text string, numeric int64, exp int32) structs.TransactionAmount {
+	return structs.TransactionAmount{Currency: currency, Text: text, Numeric: big.NewInt(numeric), Exp: exp}
+}
+
+func testTransaction() structs.Transaction {
+	tm := time.Date(2021, 3, 1, 12, 0, 0, 0, time.UTC)
+	return structs.Transaction{
+		Hash:      "AB01",
+		BlockHash: "B1",
+		Height:    3000001,
+		ChainID:   "columbus-4",
+		Time:      tm,
+		GasWanted: 200000,
+		GasUsed:   100000,
+		Memo:      "memo",
+		Fee:       []structs.TransactionAmount{amount("uluna", "3000", 3000, 0)},
+		Events: structs.TransactionEvents{
+			{Kind: "0", Type: []string{"multisend", "send"}, Module: "bank", Sub: []structs.SubsetEvent{
+				{
+					Type:      []string{"send"},
+					Module:    "bank",
+					Sender:    []structs.EventTransfer{{Account: structs.Account{ID: "terra1from"}, Amounts: []structs.TransactionAmount{amount("uluna", "10", 10, 0)}}},
+					Recipient: []structs.EventTransfer{{Account: structs.Account{ID: "terra1to"}, Amounts: []structs.TransactionAmount{amount("uluna", "10", 10, 0)}}},
+					Node:      map[string][]structs.Account{"sender": {{ID: "terra1from"}}},
+				},
+				{
+					Type:   []string{"multisend"},
+					Module: "bank",
+					Error:  &structs.SubsetEventError{Message: "failed"},
+					Transfers: map[string][]structs.EventTransfer{
+						"send":    {{Account: structs.Account{ID: "terra1a"}, Amounts: []structs.TransactionAmount{amount("uusd", "1.5", 15, 1)}}},
+						"receive": {{Account: structs.Account{ID: "terra1b"}, Amounts: []structs.TransactionAmount{amount("uusd", "1.5", 15, 1)}}},
+					},
+					Sub: []structs.SubsetEvent{{Type: []string{"transfer"}, Additional: map[string][]string{"reason": {"inner"}}}},
+				},
+			}},
+			{Kind: api.BalanceChangesKind, Sub: []structs.SubsetEvent{
+				{
+					Type:       []string{"balance_change"},
+					Node:       map[string][]structs.Account{"account": {{ID: "terra1from"}}},
+					Amount:     map[string]structs.TransactionAmount{"delta": amount("uluna", "-3010", -3010, 0)},
+					Additional: map[string][]string{"reason": {"fee"}},
+				},
+			}},
+		},
+	}
+}
+
+func TestFlatten(t *testing.T) {
+	tx := testTransaction()
+	block := structs.Block{Hash: "B1", Height: 3000001, Time: tx.Time, ChainID: "columbus-4", NumberOfTransactions: 1}
+	rows := Flatten([]structs.Block{block}, []structs.Transaction{tx})
+	ms := tx.Time.UnixNano() / int64(time.Millisecond)
+
+	require.Equal(t, []Block{{Height: 3000001, Hash: "B1", Time: ms, ChainID: "columbus-4", NumberOfTransactions: 1}}, rows.Blocks)
+
+	require.Len(t, rows.Transactions, 1)
+	require.Equal(t, "balance_change,multisend,send,transfer", rows.Transactions[0].Types)
+	require.Equal(t, int32(2), rows.Transactions[0].EventCount)
+	require.True(t, rows.Transactions[0].HasErrors)
+
+	// nested subevents follow the containing one
+	require.Len(t, rows.Events, 4)
+	zero, one := int32(0), int32(1)
+	require.Equal(t, []int32{0, 1, 2, 0}, []int32{rows.Events[0].SubIndex, rows.Events[1].SubIndex, rows.Events[2].SubIndex, rows.Events[3].SubIndex})
+	require.Nil(t, rows.Events[1].ParentSubIndex)
+	require.Equal(t, &one, rows.Events[2].ParentSubIndex)
+	require.Equal(t, `{"sender":[{"id":"terra1from"}]}`, rows.Events[0].Node)
+	require.Equal(t, "null", rows.Events[0].Amount)
+	require.Equal(t, "failed", *rows.Events[1].Error)
+	require.Equal(t, `{"reason":["inner"]}`, rows.Events[2].Additional)
+	require.Equal(t, api.BalanceChangesKind, rows.Events[3].EventKind)
+
+	sources := []string{}
+	for _, tr := range rows.Transfers {
+		sources = append(sources, tr.Source)
+	}
+	require.Equal(t, []string{SourceFee, SourceSender, SourceRecipient, SourceTransfers, SourceTransfers}, sources)
+	require.Nil(t, rows.Transfers[0].EventIndex)
+	require.Equal(t, &zero, rows.Transfers[1].EventIndex)
+	require.Equal(t, "receive", *rows.Transfers[3].Kind)
+	require.Equal(t, Transfer{
+		TxHash: "AB01", Height: 3000001, Time: ms, EventIndex: &zero, SubIndex: &one, Source: SourceTransfers, Kind: rows.Transfers[4].Kind,
+		Account: "terra1a", Currency: "uusd", Text: "1.5", Numeric: "15", Exp: 1,
+	}, rows.Transfers[4])
+
+	require.Equal(t, []BalanceChange{{
+		TxHash: "AB01", Height: 3000001, Time: ms, Account: "terra1from", Reason: "fee", Currency: "uluna", Text: "-3010", Numeric: "-3010",
+	}}, rows.BalanceChanges)
+}
+
+func TestWriteChunk(t *testing.T) {
+	dir := t.TempDir()
+	tx := testTransaction()
+	rows := Flatten([]structs.Block{{Hash: "B1", Height: 3000001, Time: tx.Time, ChainID: "columbus-4"}}, []structs.Transaction{tx})
+	require.NoError(t, WriteChunk(dir, 3000001, 3000010, rows))
+
+	tests := []struct {
+		table  string
+		schema interface{}
+		want   interface{}
+	}{
+		{TableBlocks, new(Block), &rows.Blocks},
+		{TableTransactions, new(Transaction), &rows.Transactions},
+		{TableEvents, new(Event), &rows.Events},
+		{TableTransfers, new(Transfer), &rows.Transfers},
+		{TableBalanceChanges, new(BalanceChange), &rows.BalanceChanges},
+	}
+	for _, tt := range tests {
+		t.Run(tt.table, func(t *testing.T) {
+			path := filepath.Join(dir, ChunkFile(tt.table, 3000001, 3000010))
+			require.Equal(t, filepath.Join(dir, tt.table, "000003000001-000003000010.parquet"), path)
+
+			fr, err := local.NewLocalFileReader(path)
+			require.NoError(t, err)
+			defer fr.Close()
+			pr, err := reader.NewParquetReader(fr, tt.schema, 1)
+			require.NoError(t, err)
+			defer pr.ReadStop()
+
+			require.Equal(t, SchemaVersion, *pr.Footer.KeyValueMetadata[0].Value)
+			dst := reflect.New(reflect.TypeOf(tt.want).Elem())
+			dst.Elem().Set(reflect.MakeSlice(dst.Elem().Type(), int(pr.GetNumRows()), int(pr.GetNumRows())))
+			require.NoError(t, pr.Read(dst.Interface()))
+			require.Equal(t, tt.want, dst.Interface())
+		})
+	}
+
+	// empty chunk still has file of every table
+	require.NoError(t, WriteChunk(dir, 3000011, 3000020, Rows{}))
+	for _, table := range Tables {
+		require.FileExists(t, filepath.Join(dir, ChunkFile(table, 3000011, 3000020)))
+	}
+}
@@ -0,0 +1,110 @@
+// Package parquet writes blocks and transactions as flat, typed Parquet tables:
+// blocks, transactions, events (every subevent, nested ones included), transfers and balance changes.
+//
+// Columns are part of the schema version, they're only added (never renamed or retyped) without bumping it.
+// Amounts keep exact values as decimal strings: `text` is the decimal number, `numeric` is it without the decimal point
+// and `exp` is the number of decimal places. Times are milliseconds since epoch (UTC).
+package parquet
+
+// SchemaVersion is the version of tables schema, stored in metadata of every file
+const SchemaVersion = "1"
+
+// Table names, used as directory names of table files
+const (
+	TableBlocks         = "blocks"
+	TableTransactions   = "transactions"
+	TableEvents         = "events"
+	TableTransfers      = "transfers"
+	TableBalanceChanges = "balance_changes"
+)
+
+// Tables lists all tables in the order they're written
+var Tables = []string{TableBlocks, TableTransactions, TableEvents, TableTransfers, TableBalanceChanges}
+
+// Transfer sources
+const (
+	SourceFee       = "fee"
+	SourceSender    = "sender"
+	SourceRecipient = "recipient"
+	SourceTransfers = "transfers"
+)
+
+// Block is a row of `blocks` table
+type Block struct {
+	Height               int64  `parquet:"name=height, type=INT64"`
+	Hash                 string `parquet:"name=hash, type=BYTE_ARRAY, convertedtype=UTF8"`
+	Time                 int64  `parquet:"name=time, type=INT64, convertedtype=TIMESTAMP_MILLIS"`
+	ChainID              string `parquet:"name=chain_id, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	Epoch                string `parquet:"name=epoch, type=BYTE_ARRAY, convertedtype=UTF8"`
+	NumberOfTransactions int64  `parquet:"name=num_txs, type=INT64"`
+}
+
+// Transaction is a row of `transactions` table. Types are distinct types of its subevents joined by comma
+type Transaction struct {
+	Hash       string `parquet:"name=hash, type=BYTE_ARRAY, convertedtype=UTF8"`
+	BlockHash  string `parquet:"name=block_hash, type=BYTE_ARRAY, convertedtype=UTF8"`
+	Height     int64  `parquet:"name=height, type=INT64"`
+	Time       int64  `parquet:"name=time, type=INT64, convertedtype=TIMESTAMP_MILLIS"`
+	ChainID    string `parquet:"name=chain_id, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	Epoch      string `parquet:"name=epoch, type=BYTE_ARRAY, convertedtype=UTF8"`
+	GasWanted  int64  `parquet:"name=gas_wanted, type=INT64"`
+	GasUsed    int64  `parquet:"name=gas_used, type=INT64"`
+	Memo       string `parquet:"name=memo, type=BYTE_ARRAY, convertedtype=UTF8"`
+	Types      string `parquet:"name=types, type=BYTE_ARRAY, convertedtype=UTF8"`
+	EventCount int32  `parquet:"name=event_count, type=INT32"`
+	HasErrors  bool   `parquet:"name=has_errors, type=BOOLEAN"`
+}
+
+// Event is a row of `events` table, one for every subevent. SubIndex numbers subevents of the event depth first,
+// nested subevents refer to the containing one by ParentSubIndex.
+// Node, Amount and Additional are json objects of the subevent fields (null when empty)
+type Event struct {
+	TxHash         string  `parquet:"name=tx_hash, type=BYTE_ARRAY, convertedtype=UTF8"`
+	Height         int64   `parquet:"name=height, type=INT64"`
+	Time           int64   `parquet:"name=time, type=INT64, convertedtype=TIMESTAMP_MILLIS"`
+	EventIndex     int32   `parquet:"name=event_index, type=INT32"`
+	EventKind      string  `parquet:"name=event_kind, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	EventModule    string  `parquet:"name=event_module, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	SubIndex       int32   `parquet:"name=sub_index, type=INT32"`
+	ParentSubIndex *int32  `parquet:"name=parent_sub_index, type=INT32, repetitiontype=OPTIONAL"`
+	Type           string  `parquet:"name=type, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	Action         string  `parquet:"name=action, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	Module         string  `parquet:"name=module, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	Nonce          string  `parquet:"name=nonce, type=BYTE_ARRAY, convertedtype=UTF8"`
+	Completion     *int64  `parquet:"name=completion, type=INT64, convertedtype=TIMESTAMP_MILLIS, repetitiontype=OPTIONAL"`
+	Error          *string `parquet:"name=error, type=BYTE_ARRAY, convertedtype=UTF8, repetitiontype=OPTIONAL"`
+	Node           string  `parquet:"name=node, type=BYTE_ARRAY, convertedtype=UTF8"`
+	Amount         string  `parquet:"name=amount, type=BYTE_ARRAY, convertedtype=UTF8"`
+	Additional     string  `parquet:"name=additional, type=BYTE_ARRAY, convertedtype=UTF8"`
+}
+
+// Transfer is a row of `transfers` table, one for every transferred amount. Source tells where the amount comes from:
+// transaction fee, subevent sender or recipient, or subevent transfers (of Kind key)
+type Transfer struct {
+	TxHash     string  `parquet:"name=tx_hash, type=BYTE_ARRAY, convertedtype=UTF8"`
+	Height     int64   `parquet:"name=height, type=INT64"`
+	Time       int64   `parquet:"name=time, type=INT64, convertedtype=TIMESTAMP_MILLIS"`
+	EventIndex *int32  `parquet:"name=event_index, type=INT32, repetitiontype=OPTIONAL"`
+	SubIndex   *int32  `parquet:"name=sub_index, type=INT32, repetitiontype=OPTIONAL"`
+	Source     string  `parquet:"name=source, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	Kind       *string `parquet:"name=kind, type=BYTE_ARRAY, convertedtype=UTF8, repetitiontype=OPTIONAL"`
+	Account    string  `parquet:"name=account, type=BYTE_ARRAY, convertedtype=UTF8"`
+	Currency   string  `parquet:"name=currency, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	Text       string  `parquet:"name=text, type=BYTE_ARRAY, convertedtype=UTF8"`
+	Numeric    string  `parquet:"name=numeric, type=BYTE_ARRAY, convertedtype=UTF8"`
+	Exp        int32   `parquet:"name=exp, type=INT32"`
+}
+
+// BalanceChange is a row of `balance_changes` table with signed balance delta of account
+type BalanceChange struct {
+	TxHash   string `parquet:"name=tx_hash, type=BYTE_ARRAY, convertedtype=UTF8"`
+	Height   int64  `parquet:"name=height, type=INT64"`
+	Time     int64  `parquet:"name=time, type=INT64, convertedtype=TIMESTAMP_MILLIS"`
+	Index    int32  `parquet:"name=index, type=INT32"`
+	Account  string `parquet:"name=account, type=BYTE_ARRAY, convertedtype=UTF8"`
+	Reason   string `parquet:"name=reason, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	Currency string `parquet:"name=currency, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	Text     string `parquet:"name=text, type=BYTE_ARRAY, convertedtype=UTF8"`
+	Numeric  string `parquet:"name=numeric, type=BYTE_ARRAY, convertedtype=UTF8"`
+	Exp      int32  `parquet:"name=exp, type=INT32"`
+}
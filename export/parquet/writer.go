@@ -0,0 +1,92 @@
+package parquet
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	pq "github.com/xitongsys/parquet-go/parquet"
+	"github.com/xitongsys/parquet-go/writer"
+)
+
+// schemaVersionKey is the file metadata key holding SchemaVersion
+const schemaVersionKey = "terra_worker_schema_version"
+
+// ChunkFile returns path of table file with rows of heights from start to end, relative to export directory.
+// Heights are zero padded, so files of table sort by height
+func ChunkFile(table string, start, end uint64) string {
+	return filepath.Join(table, fmt.Sprintf("%012d-%012d.parquet", start, end))
+}
+
+// WriteChunk writes file of every table with rows of heights from start to end into dir.
+// Files are written under temporary names and renamed when complete, so files of interrupted chunk are never partial
+func WriteChunk(dir string, start, end uint64, rows Rows) error {
+	for _, table := range Tables {
+		path := filepath.Join(dir, ChunkFile(table, start, end))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return err
+		}
+		if err := writeFile(path, table, rows); err != nil {
+			return fmt.Errorf("error writing %s: %w", path, err)
+		}
+	}
+	return nil
+}
+
+func writeFile(path, table string, rows Rows) error {
+	tmp := path + ".tmp"
+	f, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+	if err := WriteTable(f, table, rows); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// WriteTable writes rows of table as parquet file into w
+func WriteTable(w io.Writer, table string, rows Rows) error {
+	var (
+		schema interface{}
+		n      int
+		row    func(i int) interface{}
+	)
+	switch table {
+	case TableBlocks:
+		schema, n, row = new(Block), len(rows.Blocks), func(i int) interface{} { return rows.Blocks[i] }
+	case TableTransactions:
+		schema, n, row = new(Transaction), len(rows.Transactions), func(i int) interface{} { return rows.Transactions[i] }
+	case TableEvents:
+		schema, n, row = new(Event), len(rows.Events), func(i int) interface{} { return rows.Events[i] }
+	case TableTransfers:
+		schema, n, row = new(Transfer), len(rows.Transfers), func(i int) interface{} { return rows.Transfers[i] }
+	case TableBalanceChanges:
+		schema, n, row = new(BalanceChange), len(rows.BalanceChanges), func(i int) interface{} { return rows.BalanceChanges[i] }
+	default:
+		return fmt.Errorf("unknown table %q", table)
+	}
+
+	pw, err := writer.NewParquetWriterFromWriter(w, schema, 1)
+	if err != nil {
+		return err
+	}
+	version := SchemaVersion
+	pw.Footer.KeyValueMetadata = append(pw.Footer.KeyValueMetadata, &pq.KeyValue{Key: schemaVersionKey, Value: &version})
+
+	for i := 0; i < n; i++ {
+		if err := pw.Write(row(i)); err != nil {
+			return err
+		}
+	}
+	return pw.WriteStop()
+}
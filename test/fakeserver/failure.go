@@ -0,0 +1,123 @@
+package fakeserver
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+)
+
+// Failure describes misbehaviour injected into responses of the server
+type Failure struct {
+	// Path is a prefix of request path the failure applies to. Empty path matches every request
+	Path string
+	// Times is the number of requests affected by the failure, 0 means every request
+	Times int
+
+	// Latency delays the response
+	Latency time.Duration
+	// StatusCode is returned instead of regular response (eg. http.StatusServiceUnavailable)
+	StatusCode int
+	// Truncate cuts the response body in half, producing invalid json
+	Truncate bool
+
+	// TotalCountOffset is added to `total_count` of /tx_search responses,
+	// as if transactions were added or removed between requests of pages
+	TotalCountOffset int
+	// EmptyPage makes /tx_search return page without transactions (but with regular `total_count`)
+	EmptyPage bool
+
+	// IgnorePagination makes lcd return whole array results regardless of `page` and `limit`
+	IgnorePagination bool
+	// Total is returned in `total` of lcd responses, as by lcds reporting count of paginated results
+	Total int
+}
+
+type failureKey struct{}
+
+// Inject adds failure to the server. Failures are matched in the order of injection,
+// only the first matching one is applied to a request
+func (s *Server) Inject(f Failure) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.failures = append(s.failures, &f)
+}
+
+// Reset removes all injected failures and clears the call counters
+func (s *Server) Reset() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.failures = nil
+	s.calls = map[string]int{}
+}
+
+// takeFailure returns failure applicable for given path, decreasing its counter
+func (s *Server) takeFailure(path string) *Failure {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.calls[path]++
+	for i, f := range s.failures {
+		if !strings.HasPrefix(path, f.Path) {
+			continue
+		}
+
+		taken := *f
+		if f.Times > 0 {
+			f.Times--
+			if f.Times == 0 {
+				s.failures = append(s.failures[:i], s.failures[i+1:]...)
+			}
+		}
+		return &taken
+	}
+	return nil
+}
+
+func (s *Server) middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		f := s.takeFailure(r.URL.Path)
+		if f == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if f.Latency > 0 {
+			select {
+			case <-time.After(f.Latency):
+			case <-r.Context().Done():
+				return
+			}
+		}
+
+		if f.StatusCode > 0 {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(f.StatusCode)
+			fmt.Fprintf(w, `{"error":"injected failure: %s"}`, http.StatusText(f.StatusCode))
+			return
+		}
+
+		r = r.WithContext(context.WithValue(r.Context(), failureKey{}, f))
+		if !f.Truncate {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		rec := httptest.NewRecorder()
+		next.ServeHTTP(rec, r)
+		for k, v := range rec.Header() {
+			w.Header()[k] = v
+		}
+		w.WriteHeader(rec.Code)
+		body := rec.Body.Bytes()
+		w.Write(body[:len(body)/2])
+	})
+}
+
+// requestFailure returns failure injected into the request, if any
+func requestFailure(r *http.Request) *Failure {
+	f, _ := r.Context().Value(failureKey{}).(*Failure)
+	return f
+}
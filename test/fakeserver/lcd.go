@@ -0,0 +1,96 @@
+package fakeserver
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+)
+
+// lcdResponse is terra lcd response envelope
+type lcdResponse struct {
+	Height string          `json:"height"`
+	Result json.RawMessage `json:"result"`
+	Total  string          `json:"total,omitempty"`
+}
+
+// lcdError is the same as cosmos rest.ErrorResponse
+type lcdError struct {
+	Code  int    `json:"code,omitempty"`
+	Error string `json:"error"`
+}
+
+// handleLCD serves results set by LoadLCD or SetLCD for exact request path.
+// Results are the same for every height, the requested height is only returned back in the envelope
+func (s *Server) handleLCD(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	s.lock.RLock()
+	result, ok := s.lcd[r.URL.Path]
+	lastHeight := s.lastHeight
+	s.lock.RUnlock()
+
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(lcdError{Error: fmt.Sprintf("no fixture for %s", r.URL.Path)})
+		return
+	}
+
+	height, err := uintParam(r.URL.Query().Get("height"), lastHeight)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(lcdError{Error: err.Error()})
+		return
+	}
+
+	if height > lastHeight {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(lcdError{Error: fmt.Sprintf("failed to load state at height %d; (latest height: %d)", height, lastHeight)})
+		return
+	}
+
+	resp := lcdResponse{Height: strconv.FormatUint(height, 10), Result: result}
+	f := requestFailure(r)
+	if f == nil || !f.IgnorePagination {
+		if resp.Result, err = paginate(result, r.URL.Query().Get("page"), r.URL.Query().Get("limit")); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(lcdError{Error: err.Error()})
+			return
+		}
+	}
+	if f != nil && f.Total > 0 {
+		resp.Total = strconv.Itoa(f.Total)
+	}
+
+	json.NewEncoder(w).Encode(resp)
+}
+
+// paginate returns page of array result the same way as cosmos client.Paginate (pages from 1, 100 items by default).
+// Results other than arrays are returned as they are
+func paginate(result json.RawMessage, page, limit string) (json.RawMessage, error) {
+	var items []json.RawMessage
+	if err := json.Unmarshal(result, &items); err != nil {
+		return result, nil
+	}
+
+	p, err := uintParam(page, 1)
+	if err != nil {
+		return nil, err
+	}
+	l, err := uintParam(limit, 100)
+	if err != nil {
+		return nil, err
+	}
+	if p == 0 || l == 0 {
+		return nil, fmt.Errorf("invalid page %d or limit %d", p, l)
+	}
+
+	start, end := (p-1)*l, p*l
+	if start >= uint64(len(items)) {
+		return json.RawMessage("[]"), nil
+	}
+	if end > uint64(len(items)) {
+		end = uint64(len(items))
+	}
+	return json.Marshal(items[start:end])
+}
@@ -0,0 +1,327 @@
+package fakeserver
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/figment-networks/terra-worker/api/types"
+)
+
+const (
+	blockchainMaxLimit = 20
+	txSearchPerPage    = 30
+	txSearchMaxPerPage = 100
+)
+
+// rpcResponse is tendermint json-rpc response envelope
+type rpcResponse struct {
+	RPC    string      `json:"jsonrpc"`
+	ID     int         `json:"id"`
+	Result interface{} `json:"result,omitempty"`
+	Error  *rpcError   `json:"error,omitempty"`
+}
+
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	Data    string `json:"data"`
+}
+
+func writeRPC(w http.ResponseWriter, result interface{}, err error) {
+	w.Header().Set("Content-Type", "application/json")
+
+	resp := rpcResponse{RPC: "2.0", ID: -1}
+	if err != nil {
+		// tendermint responds with 500 for internal errors
+		w.WriteHeader(http.StatusInternalServerError)
+		resp.Error = &rpcError{Code: -32603, Message: "Internal error", Data: err.Error()}
+	} else {
+		resp.Result = result
+	}
+	json.NewEncoder(w).Encode(resp)
+}
+
+func (s *Server) handleBlockchain(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	minHeight, err := uintParam(q.Get("minHeight"), 0)
+	if err != nil {
+		writeRPC(w, nil, err)
+		return
+	}
+	maxHeight, err := uintParam(q.Get("maxHeight"), 0)
+	if err != nil {
+		writeRPC(w, nil, err)
+		return
+	}
+	limit, err := uintParam(q.Get("limit"), blockchainMaxLimit)
+	if err != nil {
+		writeRPC(w, nil, err)
+		return
+	}
+	if limit == 0 || limit > blockchainMaxLimit {
+		limit = blockchainMaxLimit
+	}
+
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	// same range rules as in tendermint's filterMinMax
+	if maxHeight == 0 || maxHeight > s.lastHeight {
+		maxHeight = s.lastHeight
+	}
+	if minHeight == 0 {
+		minHeight = 1
+	}
+	if maxHeight >= limit && minHeight < maxHeight-limit+1 {
+		minHeight = maxHeight - limit + 1
+	}
+	if minHeight > maxHeight {
+		writeRPC(w, nil, fmt.Errorf("min height %d can't be greater than max height %d", minHeight, maxHeight))
+		return
+	}
+
+	numTxs := map[uint64]int{}
+	for _, t := range s.txs {
+		numTxs[t.height]++
+	}
+
+	lastHeight := strconv.FormatUint(s.lastHeight, 10)
+	if s.chainID == "columbus-4" {
+		result := types.ResultBlockchainV4{LastHeight: lastHeight}
+		for h := maxHeight; h >= minHeight; h-- {
+			result.BlockMetas = append(result.BlockMetas, types.BlockMetaV4{
+				BlockID: types.BlockID{Hash: BlockHash(s.chainID, h)},
+				Header: types.BlockHeaderV4{
+					Height:  strconv.FormatUint(h, 10),
+					ChainID: s.chainID,
+					Time:    BlockTime(h).Format(time.RFC3339Nano),
+				},
+				NumTxs: strconv.Itoa(numTxs[h]),
+			})
+		}
+		writeRPC(w, result, nil)
+		return
+	}
+
+	result := types.ResultBlockchain{LastHeight: lastHeight}
+	for h := maxHeight; h >= minHeight; h-- {
+		result.BlockMetas = append(result.BlockMetas, types.BlockMeta{
+			BlockID: types.BlockID{Hash: BlockHash(s.chainID, h)},
+			Header: types.BlockHeader{
+				Height:  strconv.FormatUint(h, 10),
+				ChainID: s.chainID,
+				Time:    BlockTime(h).Format(time.RFC3339Nano),
+				NumTxs:  strconv.Itoa(numTxs[h]),
+			},
+		})
+	}
+	writeRPC(w, result, nil)
+}
+
+type txSearchResult struct {
+	Txs        []json.RawMessage `json:"txs"`
+	TotalCount string            `json:"total_count"`
+}
+
+func (s *Server) handleTxSearch(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	conditions, err := parseQuery(q.Get("query"))
+	if err != nil {
+		writeRPC(w, nil, err)
+		return
+	}
+
+	page, err := uintParam(q.Get("page"), 1)
+	if err != nil {
+		writeRPC(w, nil, err)
+		return
+	}
+	perPage, err := uintParam(q.Get("per_page"), txSearchPerPage)
+	if err != nil {
+		writeRPC(w, nil, err)
+		return
+	}
+	if perPage == 0 {
+		perPage = txSearchPerPage
+	} else if perPage > txSearchMaxPerPage {
+		perPage = txSearchMaxPerPage
+	}
+
+	s.lock.RLock()
+	var matching []json.RawMessage
+	for _, t := range s.txs {
+		if t.matches(conditions) {
+			matching = append(matching, t.raw)
+		}
+	}
+	s.lock.RUnlock()
+
+	pages := (uint64(len(matching)) + perPage - 1) / perPage
+	if pages == 0 {
+		pages = 1
+	}
+	if page == 0 || page > pages {
+		writeRPC(w, nil, fmt.Errorf("page should be within [1, %d] range, given %d", pages, page))
+		return
+	}
+
+	start := (page - 1) * perPage
+	end := start + perPage
+	if end > uint64(len(matching)) {
+		end = uint64(len(matching))
+	}
+
+	result := txSearchResult{
+		Txs:        matching[start:end],
+		TotalCount: strconv.Itoa(len(matching)),
+	}
+
+	if f := requestFailure(r); f != nil {
+		if f.EmptyPage {
+			result.Txs = []json.RawMessage{}
+		}
+		if f.TotalCountOffset != 0 {
+			result.TotalCount = strconv.Itoa(len(matching) + f.TotalCountOffset)
+		}
+	}
+
+	writeRPC(w, result, nil)
+}
+
+type blockResults struct {
+	Height      string            `json:"height"`
+	TxsResults  []json.RawMessage `json:"txs_results"`
+	BeginBlock  []types.TxEvents  `json:"begin_block_events"`
+	EndBlock    []types.TxEvents  `json:"end_block_events"`
+	ValUpdates  []json.RawMessage `json:"validator_updates"`
+	ParamUpdate json.RawMessage   `json:"consensus_param_updates"`
+}
+
+func (s *Server) handleBlockResults(w http.ResponseWriter, r *http.Request) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	height, err := uintParam(r.URL.Query().Get("height"), s.lastHeight)
+	if err != nil {
+		writeRPC(w, nil, err)
+		return
+	}
+
+	if height == 0 || height > s.lastHeight {
+		writeRPC(w, nil, fmt.Errorf("height %d must be less than or equal to the current blockchain height %d", height, s.lastHeight))
+		return
+	}
+
+	result := blockResults{Height: strconv.FormatUint(height, 10)}
+	for _, t := range s.txs {
+		if t.height == height {
+			result.TxsResults = append(result.TxsResults, t.result)
+		}
+	}
+	writeRPC(w, result, nil)
+}
+
+// condition is a single condition of tx_search query
+type condition struct {
+	key   string
+	op    string
+	value string
+}
+
+// parseQuery parses tendermint query joined with `AND` (eg. `"tx.height>=10 AND message.action='send'"`)
+func parseQuery(query string) (conditions []condition, err error) {
+	query = strings.Trim(strings.TrimSpace(query), `"`)
+	if query == "" {
+		return nil, errors.New("query cannot be empty")
+	}
+
+	for _, part := range strings.Split(query, " AND ") {
+		var c condition
+		for _, op := range []string{">=", "<=", "=", ">", "<"} {
+			if i := strings.Index(part, op); i > 0 {
+				c = condition{
+					key:   strings.TrimSpace(part[:i]),
+					op:    op,
+					value: strings.Trim(strings.TrimSpace(part[i+len(op):]), "'"),
+				}
+				break
+			}
+		}
+		if c.op == "" {
+			return nil, fmt.Errorf("failed to parse query condition %q", part)
+		}
+		if c.key != "tx.height" && c.op != "=" {
+			return nil, fmt.Errorf("operator %s is supported only for tx.height", c.op)
+		}
+		conditions = append(conditions, c)
+	}
+	return conditions, nil
+}
+
+func (t tx) matches(conditions []condition) bool {
+	for _, c := range conditions {
+		switch c.key {
+		case "tx.height":
+			v, err := strconv.ParseUint(c.value, 10, 64)
+			if err != nil {
+				return false
+			}
+			switch c.op {
+			case "=":
+				if t.height != v {
+					return false
+				}
+			case ">=":
+				if t.height < v {
+					return false
+				}
+			case "<=":
+				if t.height > v {
+					return false
+				}
+			case ">":
+				if t.height <= v {
+					return false
+				}
+			case "<":
+				if t.height >= v {
+					return false
+				}
+			}
+		case "tx.hash":
+			if !strings.EqualFold(t.hash, c.value) {
+				return false
+			}
+		default:
+			if !contains(t.events[c.key], c.value) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func uintParam(value string, def uint64) (uint64, error) {
+	if value == "" {
+		return def, nil
+	}
+	v, err := strconv.ParseUint(strings.Trim(value, `"`), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("wrong parameter %q: %w", value, err)
+	}
+	return v, nil
+}
@@ -0,0 +1,266 @@
+// Package fakeserver is a local fake of terra rpc and lcd endpoints used by terra-worker.
+// It serves `/blockchain`, `/tx_search`, `/block_results` and lcd queries
+// (`/bank/...`, `/staking/...`, `/distribution/...` and others) from fixture files,
+// so api.Client and IndexerClient flows can be tested without network access.
+//
+// Responses might be altered by injected failures (latency, 5xx, truncated json, broken pagination).
+package fakeserver
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// GenesisTime is the time of block at height 0. Block at height h is produced h seconds later
+var GenesisTime = time.Date(2021, 6, 1, 0, 0, 0, 0, time.UTC)
+
+// Server is a fake terra node serving both rpc and lcd endpoints
+type Server struct {
+	*httptest.Server
+
+	chainID string
+
+	lock       sync.RWMutex
+	lastHeight uint64
+	txs        []tx
+	lcd        map[string]json.RawMessage
+	failures   []*Failure
+	calls      map[string]int
+}
+
+// New creates and starts fake server for given chain.
+// For chainID "columbus-4" blocks are served in columbus-4 format, every other chain gets columbus-3 format
+func New(chainID string) *Server {
+	s := &Server{
+		chainID: chainID,
+		lcd:     map[string]json.RawMessage{},
+		calls:   map[string]int{},
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/blockchain", s.handleBlockchain)
+	mux.HandleFunc("/tx_search", s.handleTxSearch)
+	mux.HandleFunc("/block_results", s.handleBlockResults)
+	mux.HandleFunc("/", s.handleLCD)
+
+	s.Server = httptest.NewServer(s.middleware(mux))
+	return s
+}
+
+// tx is indexed transaction from fixtures
+type tx struct {
+	hash   string
+	height uint64
+	index  int
+	raw    json.RawMessage
+	result json.RawMessage
+	events map[string][]string
+}
+
+// fixtureTxSearch is a format of tx_search fixture files
+type fixtureTxSearch struct {
+	Result struct {
+		Txs []json.RawMessage `json:"txs"`
+	} `json:"result"`
+}
+
+type fixtureTx struct {
+	Hash     string          `json:"hash"`
+	Height   string          `json:"height"`
+	Index    int             `json:"index"`
+	TxResult json.RawMessage `json:"tx_result"`
+}
+
+type fixtureLog struct {
+	Events []struct {
+		Type       string `json:"type"`
+		Attributes []struct {
+			Key   string `json:"key"`
+			Value string `json:"value"`
+		} `json:"attributes"`
+	} `json:"events"`
+}
+
+// LoadTxSearch loads transactions from tx_search response files (eg. api/testdata/tx_search/columbus-4/bank.json).
+// Transactions already loaded (by hash) are skipped. Last height of the chain is extended to the highest transaction
+func (s *Server) LoadTxSearch(paths ...string) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	known := map[string]bool{}
+	for _, t := range s.txs {
+		known[t.hash] = true
+	}
+
+	for _, path := range paths {
+		b, err := ioutil.ReadFile(path)
+		if err != nil {
+			return err
+		}
+
+		f := &fixtureTxSearch{}
+		if err := json.Unmarshal(b, f); err != nil {
+			return fmt.Errorf("error decoding fixture %s: %w", path, err)
+		}
+
+		for _, raw := range f.Result.Txs {
+			t, err := newTx(raw)
+			if err != nil {
+				return fmt.Errorf("error decoding transaction from %s: %w", path, err)
+			}
+			if known[t.hash] {
+				continue
+			}
+			known[t.hash] = true
+			s.txs = append(s.txs, t)
+			if t.height > s.lastHeight {
+				s.lastHeight = t.height
+			}
+		}
+	}
+
+	sort.SliceStable(s.txs, func(i, j int) bool {
+		if s.txs[i].height == s.txs[j].height {
+			return s.txs[i].index < s.txs[j].index
+		}
+		return s.txs[i].height < s.txs[j].height
+	})
+	return nil
+}
+
+func newTx(raw json.RawMessage) (t tx, err error) {
+	ft := &fixtureTx{}
+	if err := json.Unmarshal(raw, ft); err != nil {
+		return t, err
+	}
+
+	if t.height, err = strconv.ParseUint(ft.Height, 10, 64); err != nil {
+		return t, fmt.Errorf("wrong height %q: %w", ft.Height, err)
+	}
+
+	t.hash = ft.Hash
+	t.index = ft.Index
+	t.raw = raw
+	t.result = ft.TxResult
+	t.events = map[string][]string{}
+
+	result := struct {
+		Log string `json:"log"`
+	}{}
+	if err := json.Unmarshal(ft.TxResult, &result); err != nil {
+		return t, err
+	}
+
+	var logs []fixtureLog
+	// failed transactions have plain text logs, they are not searchable by events
+	if err := json.Unmarshal([]byte(result.Log), &logs); err != nil {
+		return t, nil
+	}
+
+	for _, l := range logs {
+		for _, ev := range l.Events {
+			for _, attr := range ev.Attributes {
+				key := ev.Type + "." + attr.Key
+				t.events[key] = append(t.events[key], attr.Value)
+			}
+		}
+	}
+	return t, nil
+}
+
+// LoadLCD loads lcd fixture file. The file is a json object of request paths
+// (without query) and `result` values returned for them
+func (s *Server) LoadLCD(path string) error {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	results := map[string]json.RawMessage{}
+	if err := json.Unmarshal(b, &results); err != nil {
+		return fmt.Errorf("error decoding fixture %s: %w", path, err)
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	for p, r := range results {
+		s.lcd[p] = r
+	}
+	return nil
+}
+
+// SetLCD sets result returned for given lcd path
+func (s *Server) SetLCD(path string, result interface{}) error {
+	b, err := json.Marshal(result)
+	if err != nil {
+		return err
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.lcd[path] = b
+	return nil
+}
+
+// SetLastHeight sets current height of the chain. It cannot be lower than height of loaded transactions
+func (s *Server) SetLastHeight(height uint64) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if n := len(s.txs); n > 0 && s.txs[n-1].height > height {
+		height = s.txs[n-1].height
+	}
+	s.lastHeight = height
+}
+
+// LastHeight returns current height of the chain
+func (s *Server) LastHeight() uint64 {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	return s.lastHeight
+}
+
+// NumTxs returns number of loaded transactions in given (inclusive) height range
+func (s *Server) NumTxs(startHeight, endHeight uint64) (n int) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	for _, t := range s.txs {
+		if t.height >= startHeight && t.height <= endHeight {
+			n++
+		}
+	}
+	return n
+}
+
+// Calls returns number of requests received for paths starting with given prefix
+func (s *Server) Calls(prefix string) (n int) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	for p, c := range s.calls {
+		if strings.HasPrefix(p, prefix) {
+			n += c
+		}
+	}
+	return n
+}
+
+// BlockHash returns hash of block at given height
+func BlockHash(chainID string, height uint64) string {
+	return fmt.Sprintf("%X", sha256.Sum256([]byte(chainID+"/"+strconv.FormatUint(height, 10))))
+}
+
+// BlockTime returns time of block at given height
+func BlockTime(height uint64) time.Time {
+	return GenesisTime.Add(time.Duration(height) * time.Second)
+}
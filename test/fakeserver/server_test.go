@@ -0,0 +1,508 @@
+package fakeserver_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/figment-networks/indexer-manager/structs"
+	cStructs "github.com/figment-networks/indexer-manager/worker/connectivity/structs"
+	"github.com/figment-networks/terra-worker/api"
+	"github.com/figment-networks/terra-worker/api/types"
+	"github.com/figment-networks/terra-worker/client"
+	"github.com/figment-networks/terra-worker/test/fakeserver"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+const (
+	chainID   = "columbus-4"
+	account   = "terra190vqdjtlpcq27xslcveglfmr4ynfwg7gxlzhjn"
+	validator = "terravaloper1lq40xgtqh3f3zt9prz4m74l6dlk506usv9ag54"
+)
+
+func newServer(t *testing.T) *fakeserver.Server {
+	t.Helper()
+
+	fixtures, err := filepath.Glob(filepath.Join("..", "..", "api", "testdata", "tx_search", chainID, "*.json"))
+	require.NoError(t, err)
+	require.NotEmpty(t, fixtures)
+
+	s := fakeserver.New(chainID)
+	t.Cleanup(s.Close)
+
+	require.NoError(t, s.LoadTxSearch(fixtures...))
+	require.NoError(t, s.LoadLCD(filepath.Join("testdata", "lcd.json")))
+	return s
+}
+
+func TestGetBlocksMeta(t *testing.T) {
+	api.InitMetrics()
+	s := newServer(t)
+	c := api.NewClient(s.URL, "", zaptest.NewLogger(t), nil, 100)
+
+	last := s.LastHeight()
+	hr := structs.HeightRange{StartHeight: last - 9, EndHeight: last, ChainID: chainID}
+
+	blocks := &api.BlocksMap{Blocks: map[uint64]structs.Block{}}
+	end := make(chan error, 1)
+	c.GetBlocksMeta(context.Background(), hr, 0, blocks, end)
+	require.NoError(t, <-end)
+
+	require.Len(t, blocks.Blocks, 10)
+	require.Equal(t, hr.StartHeight, blocks.StartHeight)
+	require.Equal(t, hr.EndHeight, blocks.EndHeight)
+	require.Equal(t, uint64(s.NumTxs(hr.StartHeight, hr.EndHeight)), blocks.NumTxs)
+
+	b := blocks.Blocks[last]
+	require.Equal(t, fakeserver.BlockHash(chainID, last), b.Hash)
+	require.Equal(t, chainID, b.ChainID)
+	require.True(t, fakeserver.BlockTime(last).Equal(b.Time))
+}
+
+func TestSearchTxByQuery(t *testing.T) {
+	api.InitMetrics()
+	s := newServer(t)
+	c := api.NewClient(s.URL, "", zaptest.NewLogger(t), nil, 100)
+	ctx := context.Background()
+	hr := structs.HeightRange{StartHeight: 1, EndHeight: s.LastHeight()}
+	all := s.NumTxs(hr.StartHeight, hr.EndHeight)
+
+	queries := []struct {
+		name        string
+		query       string
+		hr          structs.HeightRange
+		wantHeights []string
+	}{
+		{name: "event query", query: "store_code.code_id='3'", hr: hr, wantHeights: []string{"3000027"}},
+		{name: "oracle vote", query: "aggregate_vote.voter='" + validator + "'", hr: hr, wantHeights: []string{"3000020"}},
+		{name: "feeder delegation", query: "feed_delegate.feeder='" + account + "'", hr: hr, wantHeights: []string{"3000019"}},
+		{name: "outside of height range", query: "aggregate_vote.voter='" + validator + "'", hr: structs.HeightRange{StartHeight: 3000021, EndHeight: s.LastHeight()}},
+		{name: "no match", query: "aggregate_vote.voter='terravaloper1unknown'", hr: hr},
+	}
+	for _, tt := range queries {
+		t.Run(tt.name, func(t *testing.T) {
+			txs, total, err := c.SearchTxByQuery(ctx, tt.query, tt.hr, 1, 30)
+			require.NoError(t, err)
+			require.Equal(t, uint64(len(tt.wantHeights)), total)
+			var heights []string
+			for _, tx := range txs {
+				heights = append(heights, tx.Height)
+			}
+			require.Equal(t, tt.wantHeights, heights)
+		})
+	}
+
+	t.Run("pages", func(t *testing.T) {
+		var got int
+		for page := 1; got < all; page++ {
+			txs, total, err := c.SearchTxByQuery(ctx, "tx.height>0", hr, page, 10)
+			require.NoError(t, err)
+			require.Equal(t, uint64(all), total)
+			got += len(txs)
+		}
+		require.Equal(t, all, got)
+
+		_, _, err := c.SearchTxByQuery(ctx, "tx.height>0", hr, all/10+2, 10)
+		require.Error(t, err, "page out of range")
+	})
+
+	t.Run("inconsistent total count", func(t *testing.T) {
+		s.Inject(fakeserver.Failure{Path: "/tx_search", Times: 1, TotalCountOffset: 5})
+		txs, total, err := c.SearchTxByQuery(ctx, "tx.height>0", hr, 1, 100)
+		require.NoError(t, err)
+		require.Len(t, txs, all)
+		require.Equal(t, uint64(all+5), total)
+	})
+
+	t.Run("empty page", func(t *testing.T) {
+		s.Inject(fakeserver.Failure{Path: "/tx_search", Times: 1, EmptyPage: true})
+		txs, total, err := c.SearchTxByQuery(ctx, "tx.height>0", hr, 1, 100)
+		require.NoError(t, err)
+		require.Empty(t, txs)
+		require.Equal(t, uint64(all), total)
+	})
+
+	t.Run("truncated json", func(t *testing.T) {
+		s.Inject(fakeserver.Failure{Path: "/tx_search", Times: 1, Truncate: true})
+		_, _, err := c.SearchTxByQuery(ctx, "tx.height>0", hr, 1, 100)
+		require.Error(t, err)
+	})
+}
+
+func TestLCD(t *testing.T) {
+	api.InitMetrics()
+	ctx := context.Background()
+	params := structs.HeightAccount{Account: account, Height: 3000010, ChainID: chainID}
+
+	t.Run("balances, delegations and rewards", func(t *testing.T) {
+		s := newServer(t)
+		c := api.NewClient(s.URL, "", zaptest.NewLogger(t), nil, 100)
+
+		balance, err := c.GetAccountBalance(ctx, params)
+		require.NoError(t, err)
+		require.Len(t, balance.Balances, 3)
+		require.Equal(t, "44556677", balance.Balances[1].Text)
+
+		delegations, err := c.GetAccountDelegations(ctx, params)
+		require.NoError(t, err)
+		require.Len(t, delegations.Delegations, 2)
+		require.Equal(t, "1000000", delegations.Delegations[0].Balance.Numeric.String())
+
+		rewards, err := c.GetReward(ctx, params)
+		require.NoError(t, err)
+		require.Len(t, rewards.Rewards, 2)
+	})
+
+	t.Run("account", func(t *testing.T) {
+		s := newServer(t)
+		c := api.NewClient(s.URL, "", zaptest.NewLogger(t), nil, 100)
+
+		acc, err := c.GetAccount(ctx, account, params.Height)
+		require.NoError(t, err)
+		require.Equal(t, uint64(1234), acc.AccountNumber)
+		require.Equal(t, uint64(56), acc.Sequence)
+		require.NotNil(t, acc.PubKey)
+
+		_, err = c.GetAccount(ctx, "terra1unknown", params.Height)
+		require.Error(t, err)
+	})
+
+	t.Run("unknown account", func(t *testing.T) {
+		s := newServer(t)
+		c := api.NewClient(s.URL, "", zaptest.NewLogger(t), nil, 100)
+
+		_, err := c.GetAccountBalance(ctx, structs.HeightAccount{Account: "terra1unknown"})
+		require.Error(t, err)
+	})
+
+	t.Run("retry after 5xx", func(t *testing.T) {
+		s := newServer(t)
+		c := api.NewClient(s.URL, "", zaptest.NewLogger(t), nil, 100)
+
+		s.Inject(fakeserver.Failure{Path: "/bank/balances/", Times: 1, StatusCode: http.StatusServiceUnavailable})
+		balance, err := c.GetAccountBalance(ctx, params)
+		require.NoError(t, err)
+		require.Len(t, balance.Balances, 3)
+		require.Equal(t, 2, s.Calls("/bank/balances/"))
+	})
+
+	t.Run("retry after timeout", func(t *testing.T) {
+		s := newServer(t)
+		c := api.NewClient(s.URL, "", zaptest.NewLogger(t), &http.Client{Timeout: 100 * time.Millisecond}, 100)
+
+		s.Inject(fakeserver.Failure{Path: "/staking/", Times: 1, Latency: time.Second})
+		delegations, err := c.GetAccountDelegations(ctx, params)
+		require.NoError(t, err)
+		require.Len(t, delegations.Delegations, 2)
+		require.Equal(t, 2, s.Calls("/staking/"))
+	})
+
+	t.Run("gov votes pages", func(t *testing.T) {
+		s := newServer(t)
+		c := api.NewClient(s.URL, "", zaptest.NewLogger(t), nil, 100)
+
+		votes := make([]types.GovVote, 250)
+		for i := range votes {
+			votes[i] = types.GovVote{ProposalID: "7", Voter: fmt.Sprintf("terra1voter%d", i), Option: "Yes"}
+		}
+		require.NoError(t, s.SetLCD("/gov/proposals/7/votes", votes))
+
+		got, err := c.GetGovVotes(ctx, 7, params.Height)
+		require.NoError(t, err)
+		require.Len(t, got, 250)
+		require.Equal(t, "terra1voter0", got[0].Voter)
+		require.Equal(t, "terra1voter249", got[249].Voter)
+		require.Equal(t, 3, s.Calls("/gov/proposals/7/votes"))
+
+		// full last page needs one more request
+		require.NoError(t, s.SetLCD("/gov/proposals/7/votes", votes[:200]))
+		got, err = c.GetGovVotes(ctx, 7, params.Height)
+		require.NoError(t, err)
+		require.Len(t, got, 200)
+		require.Equal(t, 6, s.Calls("/gov/proposals/7/votes"))
+	})
+
+	t.Run("gov votes stop", func(t *testing.T) {
+		votes := make([]types.GovVote, 200)
+		for i := range votes {
+			votes[i] = types.GovVote{ProposalID: "7", Voter: fmt.Sprintf("terra1voter%d", i), Option: "Yes"}
+		}
+
+		tests := []struct {
+			name      string
+			failure   fakeserver.Failure
+			wantVotes int
+			wantCalls int
+		}{
+			{name: "lcd ignoring page and limit", failure: fakeserver.Failure{Path: "/gov/", IgnorePagination: true}, wantVotes: 200, wantCalls: 2},
+			{name: "total reached", failure: fakeserver.Failure{Path: "/gov/", Total: 200}, wantVotes: 200, wantCalls: 2},
+			{name: "total reached by lcd ignoring page and limit", failure: fakeserver.Failure{Path: "/gov/", IgnorePagination: true, Total: 200}, wantVotes: 200, wantCalls: 1},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				s := newServer(t)
+				c := api.NewClient(s.URL, "", zaptest.NewLogger(t), nil, 100)
+				require.NoError(t, s.SetLCD("/gov/proposals/7/votes", votes))
+				s.Inject(tt.failure)
+
+				got, err := c.GetGovVotes(ctx, 7, params.Height)
+				require.NoError(t, err)
+				require.Len(t, got, tt.wantVotes)
+				require.Equal(t, tt.wantCalls, s.Calls("/gov/proposals/7/votes"))
+			})
+		}
+	})
+
+	t.Run("contract code id", func(t *testing.T) {
+		s := newServer(t)
+		c := api.NewClient(s.URL, "", zaptest.NewLogger(t), nil, 100)
+
+		contract := "terra1ejpjr43ht3y56pplm5pxpusmcrk9rkkv09x0fz"
+		require.NoError(t, s.SetLCD("/wasm/contracts/"+contract, map[string]interface{}{"address": contract, "code_id": "4", "migratable": false}))
+
+		codeID, err := c.GetContractCodeID(ctx, contract, params.Height)
+		require.NoError(t, err)
+		require.Equal(t, uint64(4), codeID)
+
+		// code id is looked up once per contract, code ids from logs are not looked up
+		codeIDs := api.NewContractCodeIDs(c, time.Second, 10)
+		for i := 0; i < 2; i++ {
+			codeID, err = codeIDs.ContractCodeID(contract)
+			require.NoError(t, err)
+			require.Equal(t, uint64(4), codeID)
+		}
+		require.Equal(t, 2, s.Calls("/wasm/contracts/"))
+
+		codeIDs.SetContractCodeID(contract, 5)
+		codeIDs.SetContractCodeID("terra1migrated", 6)
+		codeID, err = codeIDs.ContractCodeID(contract)
+		require.NoError(t, err)
+		require.Equal(t, uint64(5), codeID)
+		codeID, err = codeIDs.ContractCodeID("terra1migrated")
+		require.NoError(t, err)
+		require.Equal(t, uint64(6), codeID)
+		require.Equal(t, 2, s.Calls("/wasm/contracts/"))
+
+		_, err = codeIDs.ContractCodeID("terra1unknown")
+		require.Error(t, err)
+	})
+
+	t.Run("truncated json", func(t *testing.T) {
+		s := newServer(t)
+		c := api.NewClient(s.URL, "", zaptest.NewLogger(t), nil, 100)
+
+		s.Inject(fakeserver.Failure{Path: "/distribution/", Times: 1, Truncate: true})
+		_, err := c.GetReward(ctx, params)
+		require.Error(t, err)
+	})
+}
+
+func TestOracle(t *testing.T) {
+	api.InitMetrics()
+	ctx := context.Background()
+	params := structs.HeightAccount{Account: validator, Height: 3000030, ChainID: chainID}
+
+	tests := []struct {
+		name          string
+		feeder        interface{}
+		miss          interface{}
+		failure       *fakeserver.Failure
+		wantMiss      uint64
+		wantVotes     int
+		wantFeederErr bool
+		wantMissErr   bool
+	}{
+		{name: "feeder with votes", feeder: account, miss: "12", wantMiss: 12, wantVotes: 1},
+		{name: "malformed miss counter", feeder: account, miss: "twelve", wantMissErr: true},
+		{name: "unknown validator", wantFeederErr: true, wantMissErr: true},
+		{name: "lcd error", feeder: account, miss: "12", failure: &fakeserver.Failure{Path: "/oracle/", StatusCode: http.StatusBadRequest}, wantFeederErr: true, wantMissErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newServer(t)
+			c := api.NewClient(s.URL, "", zaptest.NewLogger(t), nil, 100)
+			if tt.feeder != nil {
+				require.NoError(t, s.SetLCD("/oracle/voters/"+validator+"/feeder", tt.feeder))
+			}
+			if tt.miss != nil {
+				require.NoError(t, s.SetLCD("/oracle/voters/"+validator+"/miss", tt.miss))
+			}
+			if tt.failure != nil {
+				s.Inject(*tt.failure)
+			}
+
+			feeder, err := c.GetOracleFeeder(ctx, params)
+			require.Equal(t, tt.wantFeederErr, err != nil, err)
+			if err == nil {
+				require.Equal(t, tt.feeder, feeder)
+			}
+
+			miss, err := c.GetOracleMissCounter(ctx, params)
+			require.Equal(t, tt.wantMissErr, err != nil, err)
+			require.Equal(t, tt.wantMiss, miss)
+
+			// report of the range with oracle fixture transactions
+			payload, err := json.Marshal(client.OracleFeederReportRequest{Validator: validator, StartHeight: 3000001, EndHeight: params.Height, ChainID: chainID})
+			require.NoError(t, err)
+			stream := cStructs.NewStreamAccess()
+			defer stream.Close()
+			ic := client.NewIndexerClient(ctx, zaptest.NewLogger(t), c, c, 10, 1000)
+			ic.GetOracleFeederReport(ctx, cStructs.TaskRequest{Id: uuid.New(), Type: client.ReqIDGetOracleFeederReport, Payload: payload}, stream, c, c)
+
+			// range of 30 heights is reported in windows of 10 heights
+			var reports []client.OracleFeederReport
+			for resp := range stream.ResponseListener {
+				if tt.wantFeederErr || tt.wantMissErr {
+					require.True(t, resp.Final)
+					require.Contains(t, resp.Error.Msg, "Error getting oracle feeder report")
+					return
+				}
+				require.Empty(t, resp.Error.Msg)
+				if resp.Type == "END" {
+					break
+				}
+				report := client.OracleFeederReport{}
+				require.NoError(t, json.Unmarshal(resp.Payload, &report))
+				reports = append(reports, report)
+			}
+			require.Len(t, reports, 3)
+
+			var votes []client.OracleVote
+			for i, report := range reports {
+				require.Equal(t, uint64(3000001+i*10), report.StartHeight)
+				require.Equal(t, uint64(3000010+i*10), report.EndHeight)
+				require.Equal(t, account, report.Feeder)
+				require.Equal(t, tt.wantMiss, report.MissCounterEnd)
+				votes = append(votes, report.Votes...)
+			}
+			require.Len(t, votes, tt.wantVotes)
+			require.Equal(t, "aggregateexchangeratevote", votes[0].Type)
+			require.Equal(t, uint64(3000020), votes[0].Height)
+			require.Len(t, reports[1].Votes, tt.wantVotes)
+		})
+	}
+}
+
+func TestIndexerClientGetTransactions(t *testing.T) {
+	api.InitMetrics()
+	ctx := context.Background()
+
+	run := func(t *testing.T, s *fakeserver.Server, hr structs.HeightRange) (responses []cStructs.TaskResponse) {
+		c := api.NewClient(s.URL, "", zaptest.NewLogger(t), nil, 100)
+		ic := client.NewIndexerClient(ctx, zaptest.NewLogger(t), c, c, 10, 1000)
+
+		payload, err := json.Marshal(hr)
+		require.NoError(t, err)
+
+		stream := cStructs.NewStreamAccess()
+		defer stream.Close()
+
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			ic.GetTransactions(ctx, cStructs.TaskRequest{Id: uuid.New(), Type: structs.ReqIDGetTransactions, Payload: payload}, stream, c)
+		}()
+
+		timeout := time.After(10 * time.Second)
+		for len(responses) == 0 || !responses[len(responses)-1].Final {
+			select {
+			case resp := <-stream.ResponseListener:
+				responses = append(responses, resp)
+			case <-timeout:
+				t.Fatal("timeout waiting for final response")
+			}
+		}
+
+		// nothing is sent after the final response
+		select {
+		case <-done:
+		case <-timeout:
+			t.Fatal("timeout waiting for handler")
+		}
+		require.Empty(t, stream.ResponseListener)
+		return responses
+	}
+
+	t.Run("range", func(t *testing.T) {
+		s := newServer(t)
+		hr := structs.HeightRange{StartHeight: 3000001, EndHeight: s.LastHeight(), ChainID: chainID}
+
+		responses := run(t, s, hr)
+		types := map[string]int{}
+		for i, resp := range responses {
+			require.Empty(t, resp.Error.Msg)
+			require.Equal(t, uint64(i), resp.Order)
+			types[resp.Type]++
+		}
+
+		require.Equal(t, "END", responses[len(responses)-1].Type)
+		require.Equal(t, int(hr.EndHeight-hr.StartHeight+1), types["Block"])
+		require.Equal(t, s.NumTxs(hr.StartHeight, hr.EndHeight), types["Transaction"])
+	})
+
+	t.Run("blockchain error", func(t *testing.T) {
+		s := newServer(t)
+		hr := structs.HeightRange{StartHeight: 3000001, EndHeight: s.LastHeight(), ChainID: chainID}
+
+		s.Inject(fakeserver.Failure{Path: "/blockchain", StatusCode: http.StatusBadGateway})
+		responses := run(t, s, hr)
+
+		final := responses[len(responses)-1]
+		require.True(t, final.Final)
+		require.Contains(t, final.Error.Msg, "Errors Getting Blocks")
+	})
+}
+
+func TestArchive(t *testing.T) {
+	api.InitMetrics()
+	ctx := context.Background()
+	s := newServer(t)
+	archive, err := api.NewArchive(t.TempDir())
+	require.NoError(t, err)
+
+	last := s.LastHeight()
+	hr := structs.HeightRange{StartHeight: last - 9, EndHeight: last, ChainID: chainID}
+	fetch := func(c *api.Client) (*api.BlocksMap, []types.TxResponse, api.Account, error) {
+		blocks := &api.BlocksMap{Blocks: map[uint64]structs.Block{}}
+		end := make(chan error, 1)
+		c.GetBlocksMeta(ctx, hr, 0, blocks, end)
+		if err := <-end; err != nil {
+			return nil, nil, api.Account{}, err
+		}
+		txs, err := c.SearchTxSingularHeight(ctx, last, 1, 100)
+		if err != nil {
+			return nil, nil, api.Account{}, err
+		}
+		acc, err := c.GetAccount(ctx, account, 3000010)
+		return blocks, txs, acc, err
+	}
+
+	recording := api.NewClient(s.URL, "", zaptest.NewLogger(t), nil, 100)
+	require.NoError(t, recording.SetArchive(archive, api.ArchiveRecord))
+	blocks, txs, acc, err := fetch(recording)
+	require.NoError(t, err)
+	require.NotEmpty(t, txs)
+
+	// replay doesn't need the node
+	calls := s.Calls("/")
+	replaying := api.NewClient("http://127.0.0.1:1", "", zaptest.NewLogger(t), nil, 1)
+	require.NoError(t, replaying.SetArchive(archive, api.ArchiveReplay))
+	rBlocks, rTxs, rAcc, err := fetch(replaying)
+	require.NoError(t, err)
+	require.Equal(t, blocks.Blocks, rBlocks.Blocks)
+	require.Equal(t, txs, rTxs)
+	require.Equal(t, acc, rAcc)
+	require.Equal(t, calls, s.Calls("/"))
+
+	_, err = replaying.GetAccount(ctx, account, 3000011)
+	require.True(t, errors.Is(err, api.ErrNotArchived))
+
+	require.Error(t, replaying.SetArchive(archive, "other"))
+}